@@ -1,26 +1,22 @@
 package main
 
 import (
-	"github.com/mattn/go-xmlrpc"
+	"context"
 	"fmt"
 	"log"
+
+	"github.com/mattn/go-xmlrpc/wordpress"
 )
 
 func main() {
-	res, e := xmlrpc.Call(
-		"http://your-blog.example.com/xmlrpc.php",
-		"metaWeblog.getRecentPosts",
-		"blog-id",
-		"user-id",
-		"password",
-		10)
-	if e != nil {
-		log.Fatal(e)
+	c := wordpress.New("http://your-blog.example.com/xmlrpc.php", "user-id", "password")
+	posts, err := c.GetPosts(context.Background(), wordpress.PostFilter{Number: 10})
+	if err != nil {
+		log.Fatal(err)
 	}
-	for _, p := range res.(xmlrpc.Array) {
-		for k, v := range p.(xmlrpc.Struct) {
-			fmt.Printf("%s=%v\n", k, v)
-		}
+	for _, p := range posts {
+		fmt.Println(p.Title)
+		fmt.Printf("id=%s date=%s link=%s\n", p.ID, p.Date, p.Link)
 		fmt.Println()
 	}
 }
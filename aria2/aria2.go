@@ -0,0 +1,201 @@
+// Package aria2 is a client for the XML-RPC interface of the aria2
+// download utility, enabled with --enable-rpc and served at /rpc.
+package aria2
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of an aria2.
+type Client struct {
+	c      *xmlrpc.Client
+	secret string
+}
+
+// New returns a Client of the aria2 serving XML-RPC at url, e.g.
+// http://localhost:6800/rpc, authorizing with secret as set with
+// --rpc-secret, if not empty.
+func New(url, secret string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...), secret: secret}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Call calls the method name with args, preceded by the secret token if
+// the client has a secret.
+func (c *Client) Call(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	if c.secret != "" {
+		args = append([]interface{}{"token:" + c.secret}, args...)
+	}
+	return c.c.CallContext(ctx, name, args...)
+}
+
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.Call(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// AddURI adds a download of the resource at uris, which must all point to
+// the same file, with options such as "dir" or "out", returning its GID.
+func (c *Client) AddURI(ctx context.Context, uris []string, options map[string]string) (string, error) {
+	if options == nil {
+		options = map[string]string{}
+	}
+	var gid string
+	err := c.call(ctx, &gid, "aria2.addUri", uris, options)
+	return gid, err
+}
+
+// Remove removes the download gid, stopping it if it is active.
+func (c *Client) Remove(ctx context.Context, gid string) error {
+	var s string
+	return c.call(ctx, &s, "aria2.remove", gid)
+}
+
+// Pause pauses the download gid.
+func (c *Client) Pause(ctx context.Context, gid string) error {
+	var s string
+	return c.call(ctx, &s, "aria2.pause", gid)
+}
+
+// Unpause resumes the download gid.
+func (c *Client) Unpause(ctx context.Context, gid string) error {
+	var s string
+	return c.call(ctx, &s, "aria2.unpause", gid)
+}
+
+// Status is the status of a download.
+type Status struct {
+	GID             string
+	Status          string // active, waiting, paused, error, complete or removed
+	TotalLength     int64
+	CompletedLength int64
+	UploadLength    int64
+	DownloadSpeed   int64 // bytes per second
+	UploadSpeed     int64
+	Connections     int
+	ErrorCode       string
+	ErrorMessage    string
+	Dir             string
+	Files           []File
+}
+
+// File is a file of a download.
+type File struct {
+	Index           int
+	Path            string
+	Length          int64
+	CompletedLength int64
+	Selected        bool
+	URIs            []URI
+}
+
+// URI is a URI of a file and whether it is "used" or "waiting".
+type URI struct {
+	URI    string `xmlrpc:"uri"`
+	Status string `xmlrpc:"status"`
+}
+
+// status is the status of a download as sent by aria2, which sends all
+// numbers as strings.
+type status struct {
+	GID             string `xmlrpc:"gid"`
+	Status          string `xmlrpc:"status"`
+	TotalLength     string `xmlrpc:"totalLength"`
+	CompletedLength string `xmlrpc:"completedLength"`
+	UploadLength    string `xmlrpc:"uploadLength"`
+	DownloadSpeed   string `xmlrpc:"downloadSpeed"`
+	UploadSpeed     string `xmlrpc:"uploadSpeed"`
+	Connections     string `xmlrpc:"connections"`
+	ErrorCode       string `xmlrpc:"errorCode"`
+	ErrorMessage    string `xmlrpc:"errorMessage"`
+	Dir             string `xmlrpc:"dir"`
+	Files           []struct {
+		Index           string `xmlrpc:"index"`
+		Path            string `xmlrpc:"path"`
+		Length          string `xmlrpc:"length"`
+		CompletedLength string `xmlrpc:"completedLength"`
+		Selected        string `xmlrpc:"selected"`
+		URIs            []URI  `xmlrpc:"uris"`
+	} `xmlrpc:"files"`
+}
+
+func (s *status) convert() Status {
+	n := func(s string) int64 {
+		i, _ := strconv.ParseInt(s, 10, 64)
+		return i
+	}
+	st := Status{
+		GID:             s.GID,
+		Status:          s.Status,
+		TotalLength:     n(s.TotalLength),
+		CompletedLength: n(s.CompletedLength),
+		UploadLength:    n(s.UploadLength),
+		DownloadSpeed:   n(s.DownloadSpeed),
+		UploadSpeed:     n(s.UploadSpeed),
+		Connections:     int(n(s.Connections)),
+		ErrorCode:       s.ErrorCode,
+		ErrorMessage:    s.ErrorMessage,
+		Dir:             s.Dir,
+	}
+	for _, f := range s.Files {
+		st.Files = append(st.Files, File{
+			Index:           int(n(f.Index)),
+			Path:            f.Path,
+			Length:          n(f.Length),
+			CompletedLength: n(f.CompletedLength),
+			Selected:        f.Selected == "true",
+			URIs:            f.URIs,
+		})
+	}
+	return st
+}
+
+// TellStatus returns the status of the download gid.
+func (c *Client) TellStatus(ctx context.Context, gid string) (*Status, error) {
+	var s status
+	if err := c.call(ctx, &s, "aria2.tellStatus", gid); err != nil {
+		return nil, err
+	}
+	st := s.convert()
+	return &st, nil
+}
+
+// TellActive returns the status of the active downloads.
+func (c *Client) TellActive(ctx context.Context) ([]Status, error) {
+	return c.tellList(ctx, "aria2.tellActive")
+}
+
+// TellWaiting returns the status of up to num waiting or paused downloads
+// from offset.
+func (c *Client) TellWaiting(ctx context.Context, offset, num int) ([]Status, error) {
+	return c.tellList(ctx, "aria2.tellWaiting", offset, num)
+}
+
+// TellStopped returns the status of up to num stopped downloads from
+// offset.
+func (c *Client) TellStopped(ctx context.Context, offset, num int) ([]Status, error) {
+	return c.tellList(ctx, "aria2.tellStopped", offset, num)
+}
+
+func (c *Client) tellList(ctx context.Context, name string, args ...interface{}) ([]Status, error) {
+	var list []status
+	if err := c.call(ctx, &list, name, args...); err != nil {
+		return nil, err
+	}
+	r := make([]Status, len(list))
+	for i := range list {
+		r[i] = list[i].convert()
+	}
+	return r, nil
+}
@@ -0,0 +1,67 @@
+package aria2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestAddURI(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"aria2.addUri": xmlrpctest.Value("2089b05ecca3d829"),
+	})
+	defer s.Close()
+
+	gid, err := New(s.URL, "s3cret").AddURI(context.Background(), []string{"http://example.com/a.iso"}, map[string]string{"dir": "/tmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gid != "2089b05ecca3d829" {
+		t.Fatalf("unexpected gid %q", gid)
+	}
+	s.AssertCalled(t, "aria2.addUri", "token:s3cret", xmlrpc.Array{"http://example.com/a.iso"}, xmlrpc.Struct{"dir": "/tmp"})
+
+	if _, err := New(s.URL, "").AddURI(context.Background(), []string{"http://example.com/b.iso"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "aria2.addUri", xmlrpc.Array{"http://example.com/b.iso"}, xmlrpc.Struct{})
+}
+
+func TestTellStatus(t *testing.T) {
+	st := xmlrpc.Struct{
+		"gid":             "2089b05ecca3d829",
+		"status":          "active",
+		"totalLength":     "1000",
+		"completedLength": "250",
+		"downloadSpeed":   "100",
+		"connections":     "4",
+		"files": xmlrpc.Array{xmlrpc.Struct{
+			"index": "1", "path": "/tmp/a.iso", "length": "1000", "completedLength": "250", "selected": "true",
+			"uris": xmlrpc.Array{xmlrpc.Struct{"uri": "http://example.com/a.iso", "status": "used"}},
+		}},
+	}
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"aria2.tellStatus": xmlrpctest.Value(st),
+		"aria2.tellActive": xmlrpctest.Value(xmlrpc.Array{st}),
+	})
+	defer s.Close()
+	c := New(s.URL, "")
+
+	status, err := c.TellStatus(context.Background(), "2089b05ecca3d829")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TotalLength != 1000 || status.CompletedLength != 250 || status.Connections != 4 || len(status.Files) != 1 || !status.Files[0].Selected || status.Files[0].URIs[0].Status != "used" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	active, err := c.TellActive(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].DownloadSpeed != 100 {
+		t.Fatalf("unexpected active downloads: %+v", active)
+	}
+}
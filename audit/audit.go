@@ -0,0 +1,184 @@
+// Package audit records the calls of XML-RPC clients and servers, with
+// their arguments and outcome, to a Sink such as rotating files. Secrets
+// such as passwords are redacted before they are recorded.
+package audit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Entry is a recorded call.
+type Entry struct {
+	Time       time.Time     `json:"time"`
+	Side       string        `json:"side"` // "client" or "server"
+	URL        string        `json:"url"`  // the URL called, or the path served
+	RemoteAddr string        `json:"remoteAddr,omitempty"`
+	Method     string        `json:"method"`
+	Params     []interface{} `json:"params"`
+	Result     interface{}   `json:"result,omitempty"`
+	Fault      *xmlrpc.Fault `json:"fault,omitempty"`
+	Err        string        `json:"error,omitempty"` // failure of the transport or of decoding
+	Duration   time.Duration `json:"duration"`
+}
+
+// Sink persists entries. It must be safe for concurrent use.
+type Sink interface {
+	Write(e *Entry) error
+}
+
+// SinkFunc is a function used as Sink.
+type SinkFunc func(e *Entry) error
+
+// Write implements Sink.
+func (f SinkFunc) Write(e *Entry) error { return f(e) }
+
+// Logger records calls to a Sink. Use Transport on the client side and
+// Handler on the server side.
+type Logger struct {
+	// OnError is called with the errors of the sink. If nil, they are
+	// ignored.
+	OnError func(error)
+
+	sink   Sink
+	redact []Redactor
+}
+
+// New returns a Logger writing to sink the entries redacted by redact, in
+// order.
+func New(sink Sink, redact ...Redactor) *Logger {
+	return &Logger{sink: sink, redact: redact}
+}
+
+func (l *Logger) write(e *Entry, req, res []byte, resHeader http.Header) {
+	if method, params, err := xmlrpc.ParseRequest(req); err != nil {
+		e.Err = "can't decode request: " + err.Error()
+	} else {
+		e.Method, e.Params = method, params
+	}
+	if e.Err == "" && res != nil {
+		if resHeader.Get("Content-Encoding") == "gzip" {
+			if zr, err := gzip.NewReader(bytes.NewReader(res)); err == nil {
+				res, _ = io.ReadAll(zr)
+			}
+		}
+		v, err := xmlrpc.ParseResponse(res)
+		if f, ok := err.(*xmlrpc.Fault); ok {
+			e.Fault = f
+		} else if err != nil {
+			e.Err = "can't decode response: " + err.Error()
+		} else {
+			e.Result = v
+		}
+	}
+	for _, r := range l.redact {
+		r(e)
+	}
+	if err := l.sink.Write(e); err != nil && l.OnError != nil {
+		l.OnError(err)
+	}
+}
+
+// Transport returns an http.RoundTripper recording the calls sent through
+// rt, or http.DefaultTransport if nil. Use it as the Transport of the
+// http.Client of an xmlrpc.Client.
+func (l *Logger) Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{l: l, rt: rt}
+}
+
+type transport struct {
+	l  *Logger
+	rt http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := &Entry{Time: time.Now(), Side: "client", URL: req.URL.String()}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		e.Duration = time.Since(e.Time)
+		e.Err = err.Error()
+		t.l.write(e, body, nil, nil)
+		return nil, err
+	}
+	rbody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	e.Duration = time.Since(e.Time)
+	res.Body = io.NopCloser(bytes.NewReader(rbody))
+	switch {
+	case err != nil:
+		e.Err = err.Error()
+		t.l.write(e, body, nil, nil)
+		return nil, err
+	case res.StatusCode != http.StatusOK:
+		e.Err = res.Status
+		t.l.write(e, body, nil, nil)
+	default:
+		t.l.write(e, body, rbody, res.Header)
+	}
+	return res, nil
+}
+
+// Handler returns an http.Handler recording the calls served by h, such as
+// an xmlrpc.Server.
+func (l *Logger) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := &Entry{Time: time.Now(), Side: "server", URL: r.URL.Path, RemoteAddr: r.RemoteAddr}
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rw, r)
+		e.Duration = time.Since(e.Time)
+		if rw.status != http.StatusOK {
+			e.Err = http.StatusText(rw.status)
+			l.write(e, body, nil, nil)
+			return
+		}
+		l.write(e, body, rw.body.Bytes(), w.Header())
+	})
+}
+
+// responseWriter copies the status and body of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wrote {
+		w.status, w.wrote = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
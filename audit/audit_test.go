@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+type memory struct {
+	mu      sync.Mutex
+	entries []*Entry
+}
+
+func (m *memory) Write(e *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func newServer() *xmlrpc.Server {
+	s := xmlrpc.NewServer(xmlrpc.WithCompression(1))
+	s.Register("metaWeblog.getPost", func(args ...interface{}) (interface{}, error) {
+		return xmlrpc.Struct{"title": "hello", "password": "post secret"}, nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, &xmlrpc.Fault{Code: 4, String: "failed"}
+	})
+	return s
+}
+
+func TestLogger(t *testing.T) {
+	var server, client memory
+	redact := []Redactor{RedactParams("metaWeblog.*", 1, 2), RedactMembers("password")}
+	ts := httptest.NewServer(New(&server, redact...).Handler(newServer()))
+	defer ts.Close()
+	c := xmlrpc.NewClient(ts.URL, xmlrpc.WithHTTPClient(&http.Client{Transport: New(&client, redact...).Transport(nil)}))
+
+	v, err := c.Call("metaWeblog.getPost", "1", "user", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.(xmlrpc.Struct)["password"] != "post secret" {
+		t.Fatalf("want result unredacted but got %v", v)
+	}
+	if _, err := c.Call("fail"); err == nil {
+		t.Fatal("want fault")
+	}
+
+	for _, m := range []*memory{&server, &client} {
+		if len(m.entries) != 2 {
+			t.Fatalf("want 2 entries but got %d", len(m.entries))
+		}
+		e := m.entries[0]
+		if e.Method != "metaWeblog.getPost" || !xmlrpc.Equal(xmlrpc.Array(e.Params), xmlrpc.Array{"1", Redacted, Redacted}) {
+			t.Fatalf("unexpected call in %+v", e)
+		}
+		if !xmlrpc.Equal(e.Result, xmlrpc.Struct{"title": "hello", "password": Redacted}) || e.Err != "" {
+			t.Fatalf("unexpected outcome in %+v", e)
+		}
+		if e := m.entries[1]; e.Method != "fail" || e.Fault == nil || e.Fault.Code != 4 {
+			t.Fatalf("unexpected fault in %+v", e)
+		}
+	}
+	if server.entries[0].Side != "server" || server.entries[0].RemoteAddr == "" || client.entries[0].Side != "client" || client.entries[0].URL != ts.URL {
+		t.Fatalf("unexpected entries %+v, %+v", server.entries[0], client.entries[0])
+	}
+}
+
+func TestLoggerTransportError(t *testing.T) {
+	var m memory
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	c := xmlrpc.NewClient(ts.URL, xmlrpc.WithHTTPClient(&http.Client{Transport: New(&m).Transport(nil)}))
+	if _, err := c.Call("missing"); err == nil {
+		t.Fatal("want error")
+	}
+	if len(m.entries) != 1 || m.entries[0].Method != "missing" || m.entries[0].Err != "404 Not Found" {
+		t.Fatalf("unexpected entries %+v", m.entries)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f, err := OpenFile(path, 200, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := f.Write(&Entry{Side: "client", Method: "m", Params: []interface{}{i}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("want at most 2 backups but got %v", err)
+	}
+	var params []float64
+	for _, p := range []string{path + ".2", path + ".1", path} {
+		file, err := os.Open(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := file.Stat()
+		if fi.Size() > 200 {
+			t.Fatalf("%s has %d bytes", p, fi.Size())
+		}
+		s := bufio.NewScanner(file)
+		for s.Scan() {
+			var e struct{ Params []float64 }
+			if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+				t.Fatal(err)
+			}
+			params = append(params, e.Params...)
+		}
+		file.Close()
+	}
+	if len(params) == 0 || params[len(params)-1] != 9 {
+		t.Fatalf("unexpected entries %v", params)
+	}
+	for i := 1; i < len(params); i++ {
+		if params[i] != params[i-1]+1 {
+			t.Fatalf("unexpected entries %v", params)
+		}
+	}
+}
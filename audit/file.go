@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// File is a Sink writing entries as lines of JSON to a file, which is
+// rotated when it would grow beyond a size.
+type File struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenFile opens the file at path for appending, creating it if needed.
+// When it would grow beyond maxSize bytes, it is renamed to path.1, path.1
+// to path.2 and so on, keeping at most backups old files. A maxSize of 0
+// disables rotation.
+func OpenFile(path string, maxSize int64, backups int) (*File, error) {
+	f := &File{path: path, maxSize: maxSize, backups: backups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.f, f.size = file, fi.Size()
+	return nil
+}
+
+// Write implements Sink.
+func (f *File) Write(e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("audit: can't record %s: %v", e.Method, err)
+	}
+	b = append(b, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(b)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.f.Write(b)
+	f.size += int64(n)
+	return err
+}
+
+// rotate renames the files and opens a new one.
+func (f *File) rotate() error {
+	if err := f.f.Close(); err != nil {
+		return err
+	}
+	f.f = nil
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.backups))
+	for i := f.backups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	var err error
+	if f.backups > 0 {
+		err = os.Rename(f.path, f.path+".1")
+	} else {
+		err = os.Remove(f.path)
+	}
+	if err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"path"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Redacted replaces redacted values.
+const Redacted = "[redacted]"
+
+// Redactor removes secrets from an entry before it is recorded.
+type Redactor func(e *Entry)
+
+// RedactParams redacts the parameters at indexes of the methods matching
+// pattern, as of path.Match. For example the credentials of the
+// metaWeblog, blogger and wp APIs are redacted by
+//
+//	RedactParams("metaWeblog.*", 1, 2)
+//	RedactParams("blogger.*", 1, 2)
+//	RedactParams("wp.*", 1, 2)
+func RedactParams(pattern string, indexes ...int) Redactor {
+	return func(e *Entry) {
+		if ok, _ := path.Match(pattern, e.Method); !ok {
+			return
+		}
+		for _, i := range indexes {
+			if i >= 0 && i < len(e.Params) {
+				e.Params[i] = Redacted
+			}
+		}
+	}
+}
+
+// RedactMembers redacts the struct members named names, at any depth of the
+// parameters and the result, such as "password".
+func RedactMembers(names ...string) Redactor {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	var redact func(v interface{})
+	redact = func(v interface{}) {
+		switch v := v.(type) {
+		case xmlrpc.Struct:
+			for name, e := range v {
+				if set[name] {
+					v[name] = Redacted
+				} else {
+					redact(e)
+				}
+			}
+		case xmlrpc.Array:
+			for _, e := range v {
+				redact(e)
+			}
+		}
+	}
+	return func(e *Entry) {
+		for _, p := range e.Params {
+			redact(p)
+		}
+		redact(e.Result)
+	}
+}
@@ -0,0 +1,61 @@
+package xmlrpc
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Unauthorized is the code of the fault sent for requests rejected by
+// RequireBasicAuth and RequireToken.
+const Unauthorized = 401
+
+// RequireBasicAuth returns a handler which passes requests carrying HTTP
+// basic auth credentials accepted by check on to h. Other requests are
+// answered with status 401, a challenge for realm and a fault with code
+// Unauthorized. The user is recorded in Peer.User. check should compare
+// passwords in constant time, e.g. with crypto/subtle.
+func RequireBasicAuth(h http.Handler, realm string, check func(user, password string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || !check(user, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+strings.Replace(realm, `"`, `\"`, -1)+`"`)
+			unauthorized(w)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+	})
+}
+
+// RequireToken returns a handler which passes requests whose header carries
+// a token accepted by check on to h, such as a bearer token in the
+// Authorization header or an API key in X-API-Key. A "Bearer " prefix is
+// removed from the Authorization header. Other requests are answered with
+// status 401 and a fault with code Unauthorized.
+func RequireToken(h http.Handler, header string, check func(token string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(header)
+		if http.CanonicalHeaderKey(header) == "Authorization" {
+			const prefix = "Bearer "
+			if len(token) < len(prefix) || !strings.EqualFold(token[:len(prefix)], prefix) {
+				token = ""
+			} else {
+				token = token[len(prefix):]
+			}
+		}
+		if token == "" || !check(token) {
+			if http.CanonicalHeaderKey(header) == "Authorization" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			unauthorized(w)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusUnauthorized)
+	var e encoder
+	e.writeFault(w, &Fault{Code: Unauthorized, String: "unauthorized"})
+}
@@ -0,0 +1,79 @@
+package xmlrpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newAuthServer() *Server {
+	s := NewServer()
+	s.Register("ping", func(args ...interface{}) (interface{}, error) {
+		return "pong", nil
+	})
+	return s
+}
+
+func authCall(t *testing.T, h http.Handler, set func(r *http.Request)) (*httptest.ResponseRecorder, interface{}, error) {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`<methodCall><methodName>ping</methodName></methodCall>`))
+	set(r)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	v, err := newDecoder(rec.Body, decodeOptions{}).response()
+	return rec, v, err
+}
+
+func TestRequireBasicAuth(t *testing.T) {
+	h := RequireBasicAuth(newAuthServer(), "xmlrpc", func(user, password string) bool {
+		return user == "admin" && password == "secret"
+	})
+
+	_, v, err := authCall(t, h, func(r *http.Request) { r.SetBasicAuth("admin", "secret") })
+	if err != nil || v != "pong" {
+		t.Fatalf("want pong but got %v, %v", v, err)
+	}
+
+	rec, _, err := authCall(t, h, func(r *http.Request) { r.SetBasicAuth("admin", "wrong") })
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("want 401 but got %d", rec.Code)
+	}
+	if got := rec.Header().Get("WWW-Authenticate"); got != `Basic realm="xmlrpc"` {
+		t.Fatalf("unexpected challenge %q", got)
+	}
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != Unauthorized {
+		t.Fatalf("want Unauthorized fault but got %v", err)
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	check := func(token string) bool { return token == "t0k3n" }
+	tests := []struct {
+		header string
+		set    func(r *http.Request)
+		ok     bool
+	}{
+		{"Authorization", func(r *http.Request) { r.Header.Set("Authorization", "Bearer t0k3n") }, true},
+		{"Authorization", func(r *http.Request) { r.Header.Set("Authorization", "bearer t0k3n") }, true},
+		{"Authorization", func(r *http.Request) { r.Header.Set("Authorization", "t0k3n") }, false},
+		{"Authorization", func(r *http.Request) {}, false},
+		{"X-API-Key", func(r *http.Request) { r.Header.Set("X-Api-Key", "t0k3n") }, true},
+		{"X-API-Key", func(r *http.Request) { r.Header.Set("X-Api-Key", "other") }, false},
+	}
+	for i, tt := range tests {
+		rec, v, err := authCall(t, RequireToken(newAuthServer(), tt.header, check), tt.set)
+		if tt.ok {
+			if err != nil || v != "pong" {
+				t.Errorf("%d: want pong but got %v, %v", i, v, err)
+			}
+			continue
+		}
+		var f *Fault
+		if rec.Code != http.StatusUnauthorized || !errors.As(err, &f) || f.Code != Unauthorized {
+			t.Errorf("%d: want 401 with Unauthorized fault but got %d, %v", i, rec.Code, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package xmlrpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Bind sets the function-typed fields of the struct pointed to by v to
+// functions calling the corresponding methods of the server at url. See
+// Client.Bind.
+func Bind(url string, v interface{}) error {
+	return NewClient(url).Bind(v)
+}
+
+// Bind sets the function-typed fields of the struct pointed to by v to
+// functions calling the corresponding methods of the server. The method
+// name is taken from the xmlrpc tag of a field or, without a tag, from its
+// name with the first letter lowered. The functions must return an error
+// as their last result and at most one other value, into which the result
+// is stored with Unmarshal. Fields of other types and fields tagged "-" are
+// left alone.
+//
+// Go can't implement interfaces at runtime, so to bind an interface, embed
+// it in a struct with function fields and methods calling them, or use a
+// generated client instead.
+func (c *Client) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("xmlrpc: Bind needs a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() != reflect.Func {
+			continue
+		}
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		if f.Tag.Get("xmlrpc") == "" {
+			r, n := utf8.DecodeRuneInString(name)
+			name = string(unicode.ToLower(r)) + name[n:]
+		}
+		fn, err := c.bind(name, f.Type)
+		if err != nil {
+			return fmt.Errorf("xmlrpc: Bind %s: %v", f.Name, err)
+		}
+		rv.Field(i).Set(fn)
+	}
+	return nil
+}
+
+// bind returns a function of type ft calling the method name.
+func (c *Client) bind(name string, ft reflect.Type) (reflect.Value, error) {
+	n := ft.NumOut()
+	if n == 0 || n > 2 || ft.Out(n-1) != errorType {
+		return reflect.Value{}, errors.New("function must return an error and at most one value")
+	}
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		var args []interface{}
+		for i, arg := range in {
+			if i == len(in)-1 && ft.IsVariadic() {
+				for j := 0; j < arg.Len(); j++ {
+					args = append(args, arg.Index(j).Interface())
+				}
+				break
+			}
+			args = append(args, arg.Interface())
+		}
+
+		out := make([]reflect.Value, n)
+		var r reflect.Value
+		if n == 2 {
+			r = reflect.New(ft.Out(0))
+		}
+		v, err := c.Call(name, args...)
+		if err == nil && n == 2 {
+			err = Unmarshal(v, r.Interface())
+		}
+		if n == 2 {
+			out[0] = r.Elem()
+		}
+		out[n-1] = reflect.Zero(errorType)
+		if err != nil {
+			out[n-1] = reflect.ValueOf(&err).Elem()
+		}
+		return out
+	}), nil
+}
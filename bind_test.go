@@ -0,0 +1,75 @@
+package xmlrpc
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	s := NewServer()
+	s.Register("add", func(args ...interface{}) (interface{}, error) {
+		sum := 0
+		for _, arg := range args {
+			sum += arg.(int)
+		}
+		return sum, nil
+	})
+	s.Register("blogger.getUsersBlogs", func(args ...interface{}) (interface{}, error) {
+		return Array{Struct{"blogid": "1", "blogName": args[0]}}, nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 3, String: "failed"}
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	type blog struct {
+		BlogID   string `xmlrpc:"blogid"`
+		BlogName string `xmlrpc:"blogName"`
+	}
+	var api struct {
+		Add      func(...int) (int, error)
+		GetBlogs func(user string) ([]blog, error) `xmlrpc:"blogger.getUsersBlogs"`
+		Fail     func() error
+		Skipped  func() error `xmlrpc:"-"`
+		Other    int
+	}
+	if err := Bind(ts.URL, &api); err != nil {
+		t.Fatal(err)
+	}
+	if api.Skipped != nil {
+		t.Fatal("want skipped field to be left alone")
+	}
+
+	sum, err := api.Add(1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != 6 {
+		t.Fatalf("want 6 but got %v", sum)
+	}
+	blogs, err := api.GetBlogs("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (blog{"1", "go"}) {
+		t.Fatalf("unexpected blogs: %v", blogs)
+	}
+	err = api.Fail()
+	if f, ok := err.(*Fault); !ok || f.Code != 3 {
+		t.Fatalf("want fault 3 but got %v", err)
+	}
+}
+
+func TestBindInvalid(t *testing.T) {
+	var bad struct {
+		F func() int
+	}
+	if err := Bind("http://localhost/", &bad); err == nil {
+		t.Fatal("want error for function without error result")
+	}
+	var notStruct func() error
+	if err := Bind("http://localhost/", &notStruct); err == nil {
+		t.Fatal("want error for non-struct")
+	}
+}
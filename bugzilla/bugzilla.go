@@ -0,0 +1,199 @@
+// Package bugzilla is a client for the XML-RPC API of Bugzilla, served at
+// /xmlrpc.cgi.
+package bugzilla
+
+import (
+	"context"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a Bugzilla. It authenticates with an API
+// key, if set, or with the token and cookies of its last login.
+type Client struct {
+	c      *xmlrpc.Client
+	token  string
+	APIKey string
+}
+
+// New returns a Client of the Bugzilla serving XML-RPC at url, e.g.
+// https://bugzilla.example.com/xmlrpc.cgi. Its http.Client keeps the
+// login cookies older versions of Bugzilla rely on.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	c := xmlrpc.NewClient(url, opts...)
+	if c.HttpClient.Jar == nil {
+		c.HttpClient.Jar, _ = cookiejar.New(nil)
+	}
+	return &Client{c: c}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// call calls the method name with params, adding the credentials of the
+// client, and stores the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, params xmlrpc.Struct) error {
+	if c.APIKey != "" {
+		params["Bugzilla_api_key"] = c.APIKey
+	} else if c.token != "" {
+		params["Bugzilla_token"] = c.token
+	}
+	v, err := c.c.CallContext(ctx, name, params)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Login logs in as the user login. Bugzilla 4.4.3 and later return a token
+// which is sent with later calls; older versions set cookies instead.
+func (c *Client) Login(ctx context.Context, login, password string) (userID int, err error) {
+	var r struct {
+		ID    int    `xmlrpc:"id"`
+		Token string `xmlrpc:"token"`
+	}
+	if err := c.call(ctx, &r, "User.login", xmlrpc.Struct{"login": login, "password": password}); err != nil {
+		return 0, err
+	}
+	c.token = r.Token
+	return r.ID, nil
+}
+
+// Logout logs out.
+func (c *Client) Logout(ctx context.Context) error {
+	var r interface{}
+	err := c.call(ctx, &r, "User.logout", xmlrpc.Struct{})
+	c.token = ""
+	return err
+}
+
+// Bug is a bug.
+type Bug struct {
+	ID              int       `xmlrpc:"id"`
+	Alias           []string  `xmlrpc:"alias"`
+	Summary         string    `xmlrpc:"summary"`
+	Status          string    `xmlrpc:"status"`
+	Resolution      string    `xmlrpc:"resolution"`
+	IsOpen          bool      `xmlrpc:"is_open"`
+	Product         string    `xmlrpc:"product"`
+	Component       string    `xmlrpc:"component"`
+	Version         string    `xmlrpc:"version"`
+	Priority        string    `xmlrpc:"priority"`
+	Severity        string    `xmlrpc:"severity"`
+	OpSys           string    `xmlrpc:"op_sys"`
+	Platform        string    `xmlrpc:"platform"`
+	TargetMilestone string    `xmlrpc:"target_milestone"`
+	AssignedTo      string    `xmlrpc:"assigned_to"`
+	Creator         string    `xmlrpc:"creator"`
+	CC              []string  `xmlrpc:"cc"`
+	Keywords        []string  `xmlrpc:"keywords"`
+	Whiteboard      string    `xmlrpc:"whiteboard"`
+	URL             string    `xmlrpc:"url"`
+	DependsOn       []int     `xmlrpc:"depends_on"`
+	Blocks          []int     `xmlrpc:"blocks"`
+	CreationTime    time.Time `xmlrpc:"creation_time"`
+	LastChangeTime  time.Time `xmlrpc:"last_change_time"`
+}
+
+// Get returns the bugs ids.
+func (c *Client) Get(ctx context.Context, ids ...int) ([]Bug, error) {
+	var r struct {
+		Bugs []Bug `xmlrpc:"bugs"`
+	}
+	err := c.call(ctx, &r, "Bug.get", xmlrpc.Struct{"ids": ids})
+	return r.Bugs, err
+}
+
+// Query selects the bugs returned by Search. Fields with their zero value
+// are left out.
+type Query struct {
+	Product    string
+	Component  string
+	Status     []string
+	Resolution []string
+	Summary    string // substring of the summary
+	AssignedTo string
+	Creator    string
+	Limit      int
+	Offset     int
+}
+
+// Search returns the bugs matching q.
+func (c *Client) Search(ctx context.Context, q Query) ([]Bug, error) {
+	params := xmlrpc.Struct{}
+	set := func(name string, v interface{}, zero bool) {
+		if !zero {
+			params[name] = v
+		}
+	}
+	set("product", q.Product, q.Product == "")
+	set("component", q.Component, q.Component == "")
+	set("status", q.Status, len(q.Status) == 0)
+	set("resolution", q.Resolution, len(q.Resolution) == 0)
+	set("summary", q.Summary, q.Summary == "")
+	set("assigned_to", q.AssignedTo, q.AssignedTo == "")
+	set("creator", q.Creator, q.Creator == "")
+	set("limit", q.Limit, q.Limit == 0)
+	set("offset", q.Offset, q.Offset == 0)
+
+	var r struct {
+		Bugs []Bug `xmlrpc:"bugs"`
+	}
+	err := c.call(ctx, &r, "Bug.search", params)
+	return r.Bugs, err
+}
+
+// NewBug holds the fields of a bug to create. Product, Component, Summary
+// and Version are required; fields with their zero value are left out.
+type NewBug struct {
+	Product     string
+	Component   string
+	Summary     string
+	Version     string
+	Description string
+	OpSys       string
+	Platform    string
+	Priority    string
+	Severity    string
+	AssignedTo  string
+	CC          []string
+	Keywords    []string
+}
+
+// Create creates a bug, returning its ID.
+func (c *Client) Create(ctx context.Context, b NewBug) (int, error) {
+	params := xmlrpc.Struct{
+		"product":   b.Product,
+		"component": b.Component,
+		"summary":   b.Summary,
+		"version":   b.Version,
+	}
+	for name, v := range map[string]string{
+		"description": b.Description,
+		"op_sys":      b.OpSys,
+		"platform":    b.Platform,
+		"priority":    b.Priority,
+		"severity":    b.Severity,
+		"assigned_to": b.AssignedTo,
+	} {
+		if v != "" {
+			params[name] = v
+		}
+	}
+	if len(b.CC) > 0 {
+		params["cc"] = b.CC
+	}
+	if len(b.Keywords) > 0 {
+		params["keywords"] = b.Keywords
+	}
+	var r struct {
+		ID int `xmlrpc:"id"`
+	}
+	err := c.call(ctx, &r, "Bug.create", params)
+	return r.ID, err
+}
@@ -0,0 +1,90 @@
+package bugzilla
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestLoginAndGet(t *testing.T) {
+	var params []xmlrpc.Struct
+	record := func(v interface{}) xmlrpc.HandlerFunc {
+		return func(args ...interface{}) (interface{}, error) {
+			params = append(params, args[0].(xmlrpc.Struct))
+			return v, nil
+		}
+	}
+	s := xmlrpc.NewServer()
+	s.Register("User.login", record(xmlrpc.Struct{"id": 7, "token": "7-abc"}))
+	s.Register("Bug.get", record(xmlrpc.Struct{"bugs": xmlrpc.Array{xmlrpc.Struct{
+		"id": 1, "summary": "Crash", "is_open": true, "depends_on": xmlrpc.Array{2},
+		"creation_time": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}}))
+	s.Register("Bug.create", record(xmlrpc.Struct{"id": 3}))
+	var cookies int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("Bugzilla_logincookie"); err == nil {
+			cookies++
+		}
+		http.SetCookie(w, &http.Cookie{Name: "Bugzilla_logincookie", Value: "xyz"})
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	c := New(ts.URL + "/xmlrpc.cgi")
+	ctx := context.Background()
+
+	id, err := c.Login(ctx, "me@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 {
+		t.Fatalf("want user 7 but got %d", id)
+	}
+	bugs, err := c.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bugs) != 1 || bugs[0].Summary != "Crash" || !bugs[0].IsOpen || bugs[0].DependsOn[0] != 2 || bugs[0].CreationTime.Year() != 2024 {
+		t.Fatalf("unexpected bugs: %+v", bugs)
+	}
+	if cookies != 1 {
+		t.Fatalf("want login cookie sent once but got %d", cookies)
+	}
+
+	c.APIKey = "key"
+	if _, err := c.Create(ctx, NewBug{Product: "P", Component: "C", Summary: "S", Version: "1", Severity: "major"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []xmlrpc.Struct{
+		{"login": "me@example.com", "password": "secret"},
+		{"ids": xmlrpc.Array{1}, "Bugzilla_token": "7-abc"},
+		{"product": "P", "component": "C", "summary": "S", "version": "1", "severity": "major", "Bugzilla_api_key": "key"},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Fatalf("want params %v but got %v", want, params)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var got xmlrpc.Struct
+	s := xmlrpc.NewServer()
+	s.Register("Bug.search", func(args ...interface{}) (interface{}, error) {
+		got = args[0].(xmlrpc.Struct)
+		return xmlrpc.Struct{"bugs": xmlrpc.Array{}}, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	if _, err := New(ts.URL).Search(context.Background(), Query{Product: "P", Status: []string{"NEW"}, Limit: 5}); err != nil {
+		t.Fatal(err)
+	}
+	want := xmlrpc.Struct{"product": "P", "status": xmlrpc.Array{"NEW"}, "limit": 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+}
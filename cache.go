@@ -0,0 +1,172 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Cache stores the results of calls for WithCache. It must be safe for
+// concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, unless it expired.
+	Get(key string) (interface{}, bool)
+
+	// Set stores v under key for ttl.
+	Set(key string, v interface{}, ttl time.Duration)
+}
+
+// MemoryCache is a Cache in memory, which drops expired values as it
+// grows.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	sweep   int // size at which to drop expired values
+}
+
+type cacheEntry struct {
+	v       interface{}
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]cacheEntry{}, sweep: 64}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.v, true
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(key string, v interface{}, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if len(m.entries) >= m.sweep {
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.sweep = 2 * len(m.entries)
+		if m.sweep < 64 {
+			m.sweep = 64
+		}
+	}
+	m.entries[key] = cacheEntry{v: v, expires: now.Add(ttl)}
+}
+
+// Purge drops all values.
+func (m *MemoryCache) Purge() {
+	m.mu.Lock()
+	m.entries = map[string]cacheEntry{}
+	m.mu.Unlock()
+}
+
+// cachePolicy is the configuration of WithCache.
+type cachePolicy struct {
+	store   Cache
+	ttl     time.Duration
+	methods map[string]bool
+}
+
+// WithCache makes Call and CallContext serve repeated calls of methods
+// with the same arguments from store, or a new MemoryCache if nil, for ttl
+// after a call succeeded. Only list methods whose results don't depend on
+// anything but their arguments, such as system.listMethods. Cached values
+// are shared between calls and must not be modified. Clients derived with
+// With share the cache only if they send the same headers and tokens and
+// sign requests alike. Calls with the
+// CallOptions CallHeader, CallRequestHook, CallResponseHook or
+// CallRawResponse bypass the cache.
+func WithCache(store Cache, ttl time.Duration, methods ...string) Option {
+	return func(c *Client) {
+		if store == nil {
+			store = NewMemoryCache()
+		}
+		p := &cachePolicy{store: store, ttl: ttl, methods: map[string]bool{}}
+		for _, m := range methods {
+			p.methods[m] = true
+		}
+		c.cache = p
+	}
+}
+
+// requestKey returns a key identifying the call of name with args: the
+// URL, the headers and credentials of the client and the request, with
+// the members of maps in canonical order. It returns false
+// for calls with Base64Reader or ArrayStream arguments, which can't be
+// compared.
+func (c *Client) requestKey(name string, args []interface{}) (string, bool) {
+	if hasReader(args) {
+		return "", false
+	}
+	c.mu.Lock()
+	enc := c.enc
+	c.mu.Unlock()
+	enc.sortKeys = true
+	enc.indent = ""
+	var buf bytes.Buffer
+	buf.WriteString(c.url + "\n" + c.credentials + "\n")
+	c.header.Write(&buf)
+	buf.WriteString("\n")
+	if err := enc.writeRequest(&buf, name, args...); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// newCredentials returns a random identifier for a token or signer, which
+// can't be compared, so that clients with different ones don't share
+// results, even through a Cache shared by several processes.
+func newCredentials() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// callCached is like call with the decoder of single responses, serving
+// the methods of the cache policy from the cache and coalescing identical
+// calls if configured.
+func (c *Client) callCached(ctx context.Context, name string, args []interface{}) (interface{}, error) {
+	cached := c.cache != nil && c.cache.methods[name]
+	coalesced := c.flights != nil && c.retryPolicy(ctx).idempotent(ctx, name)
+	if !cached && !coalesced || callOptionsFrom(ctx).private() {
+		return c.call(ctx, name, args, (*decoder).response)
+	}
+	key, ok := c.requestKey(name, args)
+	if !ok {
+		return c.call(ctx, name, args, (*decoder).response)
+	}
+	if cached {
+		if v, ok := c.cache.store.Get(key); ok {
+			return v, nil
+		}
+	}
+	call := func(ctx context.Context) (interface{}, error) {
+		v, err := c.call(ctx, name, args, (*decoder).response)
+		if err == nil && cached {
+			c.cache.store.Set(key, v, c.cache.ttl)
+		}
+		return v, err
+	}
+	if coalesced {
+		return c.flights.do(ctx, key, call)
+	}
+	return call(ctx)
+}
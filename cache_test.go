@@ -0,0 +1,100 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	s := NewServer()
+	calls := 0
+	s.Register("get", func(args ...interface{}) (interface{}, error) {
+		calls++
+		return calls, nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		calls++
+		return nil, &Fault{Code: 1, String: "fail"}
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	store := NewMemoryCache()
+	c := NewClient(ts.URL, WithCache(store, time.Hour, "get", "fail"))
+
+	arg := Struct{"a": 1, "b": 2, "c": 3, "d": 4}
+	for i := 0; i < 3; i++ {
+		v, err := c.Call("get", arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != 1 {
+			t.Fatalf("want cached 1 but got %v", v)
+		}
+	}
+	if v, _ := c.Call("get", "other"); v != 2 {
+		t.Fatalf("want 2 for other args but got %v", v)
+	}
+	c.Call("fail")
+	c.Call("fail")
+	if calls != 4 {
+		t.Fatalf("want faults not cached, 4 calls but got %d", calls)
+	}
+
+	store.Purge()
+	if v, _ := c.Call("get", arg); v != 5 {
+		t.Fatalf("want 5 after purge but got %v", v)
+	}
+	if v, _ := NewClient(ts.URL).Call("get", arg); v != 6 {
+		t.Fatalf("want 6 without cache but got %v", v)
+	}
+}
+
+func TestCacheWithDerivedClients(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("whoami", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		h := p.Header
+		return h.Get("X-Tenant") + h.Get("Authorization"), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	base := NewClient(ts.URL, WithCache(nil, time.Hour, "whoami"))
+	token := func(tok string) Option {
+		return WithBearerToken(func(context.Context) (string, error) { return tok, nil })
+	}
+	for _, tt := range []struct {
+		c    *Client
+		want string
+	}{
+		{base.With(WithHeader("X-Tenant", "a")), "a"},
+		{base.With(WithHeader("X-Tenant", "b")), "b"},
+		{base.With(token("x")), "Bearer x"},
+		{base.With(token("y")), "Bearer y"},
+		{base, ""},
+	} {
+		if v, err := tt.c.Call("whoami"); err != nil || v != tt.want {
+			t.Fatalf("want %q but got %v, %v", tt.want, v, err)
+		}
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	m := NewMemoryCache()
+	m.Set("a", 1, -time.Second)
+	if _, ok := m.Get("a"); ok {
+		t.Fatal("want expired value dropped")
+	}
+	for i := 0; i < 100; i++ {
+		m.Set(string(rune('a'+i)), i, -time.Second)
+	}
+	m.Set("live", 1, time.Hour)
+	if len(m.entries) > 64 {
+		t.Fatalf("want expired values swept but got %d", len(m.entries))
+	}
+	if v, ok := m.Get("live"); !ok || v != 1 {
+		t.Fatalf("want live value but got %v, %v", v, ok)
+	}
+}
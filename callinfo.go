@@ -0,0 +1,87 @@
+package xmlrpc
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// CallInfo describes a finished call. It is passed to the function set with
+// WithCallInfo. The timings are collected by an httptrace.ClientTrace; a
+// trace of the context of the call, as set by httptrace.WithClientTrace,
+// is called as well.
+type CallInfo struct {
+	Method        string
+	RequestBytes  int64 // size of the request body
+	ResponseBytes int64 // size of the response body
+	RemoteAddr    string
+	Reused        bool // whether the connection was reused
+	DNS           time.Duration
+	Connect       time.Duration
+	TLSHandshake  time.Duration
+	FirstByte     time.Duration // from writing the request to the first response byte
+	Decode        time.Duration
+	Total         time.Duration
+	Err           error
+}
+
+// trace returns a ClientTrace which records the timings of a request in info.
+func (info *CallInfo) trace() *httptrace.ClientTrace {
+	var dnsStart, connectStart, tlsStart time.Time
+	// The request is written and the response read by different
+	// goroutines, which may overlap.
+	var mu sync.Mutex
+	var wrote time.Time
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			info.DNS = time.Since(dnsStart)
+		},
+		GotConn: func(ci httptrace.GotConnInfo) {
+			info.Reused = ci.Reused
+			if ci.Conn != nil {
+				info.RemoteAddr = ci.Conn.RemoteAddr().String()
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			info.Connect = time.Since(connectStart)
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			info.TLSHandshake = time.Since(tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			wrote = time.Now()
+			mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if !wrote.IsZero() {
+				info.FirstByte = time.Since(wrote)
+			}
+		},
+	}
+}
+
+// countReader counts the bytes read from r.
+type countReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (r countReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	*r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,62 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"testing"
+)
+
+func TestCallInfo(t *testing.T) {
+	ts := httptest.NewServer(&ParseIntArrayHandler{})
+	defer ts.Close()
+
+	var info *CallInfo
+	client := NewClient(ts.URL+"/", WithCallInfo(func(i *CallInfo) {
+		info = i
+	}))
+	if _, err := client.Call("Irrelevant", "arg"); err != nil {
+		t.Fatal(err)
+	}
+	if info == nil {
+		t.Fatal("want CallInfo")
+	}
+	if info.Method != "Irrelevant" || info.Err != nil {
+		t.Fatalf("unexpected CallInfo %+v", info)
+	}
+	body, _ := (&encoder{}).makeRequest("Irrelevant", "arg")
+	if want := int64(body.(interface{ Len() int }).Len()); info.RequestBytes != want {
+		t.Fatalf("want %d request bytes but got %d", want, info.RequestBytes)
+	}
+	if info.ResponseBytes == 0 || info.Total == 0 || info.Total < info.Decode {
+		t.Fatalf("unexpected CallInfo %+v", info)
+	}
+}
+
+func TestCallInfoTrace(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+
+	var infos []*CallInfo
+	client := NewClient("http://localhost:"+u.Port()+"/", WithCallInfo(func(i *CallInfo) {
+		infos = append(infos, i)
+	}))
+	var dns, conns int
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		DNSDone: func(httptrace.DNSDoneInfo) { dns++ },
+		GotConn: func(httptrace.GotConnInfo) { conns++ },
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := client.CallContext(ctx, "system.listMethods"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if dns != 1 || conns != 2 {
+		t.Fatalf("want trace of context called but got %d DNS lookups and %d connections", dns, conns)
+	}
+	if infos[0].Reused || !infos[1].Reused || infos[0].DNS == 0 || infos[0].RemoteAddr == "" {
+		t.Fatalf("unexpected CallInfo %+v, %+v", infos[0], infos[1])
+	}
+}
@@ -0,0 +1,169 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallOption changes the behavior of a single call. CallOptions are passed
+// to Call and CallContext after the arguments of the call, e.g.
+//
+//	var n int
+//	_, err := c.Call("count", "foo", xmlrpc.CallTimeout(time.Second), xmlrpc.CallResult(&n))
+type CallOption interface {
+	applyCall(*callOptions)
+}
+
+type callOptions struct {
+	header  http.Header
+	timeout time.Duration
+	retry   *RetryPolicy
+	result  interface{}
+
+	onRequest  func(*http.Request)
+	onResponse func(*http.Response)
+	raw        *rawWriter
+}
+
+// private reports whether the options change the request or observe the
+// response, so that the call can't be served by a cached or shared
+// response.
+func (o *callOptions) private() bool {
+	return o.header != nil || o.onRequest != nil || o.onResponse != nil || o.raw != nil
+}
+
+// rawWriter writes the responses of the attempts of a call one at a time.
+type rawWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *rawWriter) write(b []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.w.Write(b)
+}
+
+type callOptionFunc func(*callOptions)
+
+func (f callOptionFunc) applyCall(o *callOptions) {
+	f(o)
+}
+
+// CallHeader adds the header key with value to the request of the call.
+func CallHeader(key, value string) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Add(key, value)
+	})
+}
+
+// CallTimeout limits the duration of the call, including its retries, to
+// d.
+func CallTimeout(d time.Duration) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		o.timeout = d
+	})
+}
+
+// CallRetry makes the call retried according to p instead of the policy
+// of the client.
+func CallRetry(p RetryPolicy) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		o.retry = &p
+	})
+}
+
+// CallResult makes the result of the call unmarshaled into v, as by
+// Unmarshal. The result is returned by the call as well.
+func CallResult(v interface{}) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		o.result = v
+	})
+}
+
+// CallRequestHook makes fn called with the HTTP request of each attempt of
+// the call before it is signed and sent, e.g. to set details of the
+// request not covered by other options.
+func CallRequestHook(fn func(*http.Request)) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		o.onRequest = fn
+	})
+}
+
+// CallResponseHook makes fn called with the HTTP response of each attempt
+// of the call before its body is read. Hedged attempts may call it
+// concurrently.
+func CallResponseHook(fn func(*http.Response)) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		o.onResponse = fn
+	})
+}
+
+// CallRawResponse makes the body of the response of the call copied to w
+// exactly as it is received, e.g. to archive it, while it is decoded as
+// usual. Each attempt of a retried or hedged call writes its response, as
+// a whole, in turn.
+func CallRawResponse(w io.Writer) CallOption {
+	return callOptionFunc(func(o *callOptions) {
+		o.raw = &rawWriter{w: w}
+	})
+}
+
+type callOptionsKey struct{}
+
+// splitCallOptions removes the CallOptions at the end of args, returning
+// nil options if there are none.
+func splitCallOptions(args []interface{}) ([]interface{}, *callOptions) {
+	n := len(args)
+	for n > 0 {
+		if _, ok := args[n-1].(CallOption); !ok {
+			break
+		}
+		n--
+	}
+	if n == len(args) {
+		return args, nil
+	}
+	o := &callOptions{}
+	for _, opt := range args[n:] {
+		opt.(CallOption).applyCall(o)
+	}
+	return args[:n], o
+}
+
+// callWith makes the call of name with args and the options o.
+func (c *Client) callWith(ctx context.Context, name string, args []interface{}, o *callOptions) (interface{}, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	v, err := c.callCached(context.WithValue(ctx, callOptionsKey{}, o), name, args)
+	if err == nil && o.result != nil {
+		err = Unmarshal(v, o.result)
+	}
+	return v, err
+}
+
+// retryPolicy returns the retry policy of the call with ctx.
+func (c *Client) retryPolicy(ctx context.Context) *RetryPolicy {
+	if o, ok := ctx.Value(callOptionsKey{}).(*callOptions); ok && o.retry != nil {
+		return o.retry
+	}
+	return &c.retry
+}
+
+// callOptionsFrom returns the options of the call with ctx, or empty
+// options.
+func callOptionsFrom(ctx context.Context) *callOptions {
+	if o, ok := ctx.Value(callOptionsKey{}).(*callOptions); ok {
+		return o
+	}
+	return &callOptions{}
+}
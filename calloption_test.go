@@ -0,0 +1,136 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCallOptions(t *testing.T) {
+	calls := 0
+	s := NewServer()
+	s.RegisterContext("echo", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		return Struct{"args": Array(args), "header": p.Header.Get("X-Tenant")}, nil
+	})
+	s.Register("busy", func(args ...interface{}) (interface{}, error) {
+		calls++
+		return nil, &Fault{Code: 75, String: "server busy"}
+	})
+	s.Register("sleep", func(args ...interface{}) (interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return nil, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	var res struct {
+		Args   []int  `xmlrpc:"args"`
+		Header string `xmlrpc:"header"`
+	}
+	if _, err := c.Call("echo", 1, 2, CallHeader("X-Tenant", "acme"), CallResult(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Args) != 2 || res.Args[1] != 2 || res.Header != "acme" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	if _, err := c.Call("busy", CallRetry(RetryPolicy{MaxAttempts: 3, FaultCodes: []int{75}})); err == nil || calls != 3 {
+		t.Fatalf("want 3 attempts but got %d, %v", calls, err)
+	}
+	calls = 0
+	if _, err := c.Call("busy"); err == nil || calls != 1 {
+		t.Fatalf("want 1 attempt but got %d, %v", calls, err)
+	}
+
+	if _, err := c.Call("sleep", CallTimeout(10*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded but got %v", err)
+	}
+}
+
+func TestSplitCallOptions(t *testing.T) {
+	args, o := splitCallOptions([]interface{}{1, CallHeader("A", "b"), "x", CallTimeout(time.Second)})
+	if len(args) != 3 || o == nil || o.timeout != time.Second || o.header != nil {
+		t.Fatalf("want only trailing options but got %v, %+v", args, o)
+	}
+	if args, o = splitCallOptions([]interface{}{1}); len(args) != 1 || o != nil {
+		t.Fatalf("want no options but got %v, %+v", args, o)
+	}
+}
+
+func TestCallRawResponse(t *testing.T) {
+	const body = `<?xml version="1.0"?>
+<methodResponse><params><param><value><i4>42</i4></value></param></params></methodResponse>
+<!-- served by test -->
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	var raw bytes.Buffer
+	v, err := NewClient(ts.URL).Call("answer", CallRawResponse(&raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 || raw.String() != body {
+		t.Fatalf("want 42 and the whole body but got %v and %q", v, raw.String())
+	}
+}
+
+func TestCallOptionsBypassSharing(t *testing.T) {
+	arrived := make(chan string, 2)
+	release := make(chan struct{})
+	s := NewServer()
+	s.RegisterContext("whoami", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		user := p.Header.Get("X-User")
+		if user == "carol" || user == "dave" {
+			arrived <- user
+			<-release
+		}
+		return user, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithCache(nil, time.Minute, "whoami"), WithCoalescing())
+	if v, err := c.Call("whoami", CallHeader("X-User", "alice")); err != nil || v != "alice" {
+		t.Fatalf("want alice but got %v, %v", v, err)
+	}
+	var buf bytes.Buffer
+	if v, err := c.Call("whoami", CallHeader("X-User", "bob"), CallRawResponse(&buf)); err != nil || v != "bob" {
+		t.Fatalf("want bob but got %v, %v", v, err)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("want raw response of the call")
+	}
+	if v, err := c.Call("whoami"); err != nil || v != "" {
+		t.Fatalf("want no cached result of calls with headers but got %v, %v", v, err)
+	}
+
+	// Concurrent calls with different headers make requests of their own.
+	results := make(chan interface{}, 2)
+	for _, user := range []string{"carol", "dave"} {
+		go func(user string) {
+			v, _ := c.Call("whoami", CallHeader("X-User", user))
+			results <- v
+		}(user)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(5 * time.Second):
+			t.Fatal("want a request per call")
+		}
+	}
+	close(release)
+	if a, b := <-results, <-results; a == b {
+		t.Fatalf("want results of both users but got %v, %v", a, b)
+	}
+}
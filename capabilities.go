@@ -0,0 +1,120 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Capability describes a capability advertised by system.getCapabilities.
+type Capability struct {
+	SpecURL     string `xmlrpc:"specUrl"`
+	SpecVersion int    `xmlrpc:"specVersion"`
+}
+
+// Capabilities is the result of system.getCapabilities.
+type Capabilities struct {
+	FaultsInterop bool // standard fault codes, "faults_interop"
+	Introspection bool // system.listMethods and friends, "introspection"
+	MultiCall     bool // system.multicall, "system.multicall"
+	Nil           bool // the nil extension, "nil"
+	I8            bool // 64-bit integers, "i8"
+
+	// All holds every advertised capability by name.
+	All map[string]Capability
+}
+
+// Capabilities calls system.getCapabilities and returns the result, which
+// is cached for later calls. If the server advertises 64-bit integers,
+// integers outside the range of i4 are sent as i8 from then on.
+func (c *Client) Capabilities() (*Capabilities, error) {
+	c.mu.Lock()
+	caps := c.caps
+	c.mu.Unlock()
+	if caps != nil {
+		return caps, nil
+	}
+
+	v, err := c.Call("system.getCapabilities")
+	if err != nil {
+		return nil, err
+	}
+	caps = &Capabilities{}
+	if err := Unmarshal(v, &caps.All); err != nil {
+		return nil, err
+	}
+	has := func(name string) bool {
+		_, ok := caps.All[name]
+		return ok
+	}
+	caps.FaultsInterop = has("faults_interop")
+	caps.Introspection = has("introspection")
+	caps.MultiCall = has("system.multicall")
+	caps.Nil = has("nil")
+	caps.I8 = has("i8")
+
+	c.mu.Lock()
+	c.caps = caps
+	if caps.I8 {
+		c.enc.i8 = true
+	}
+	c.mu.Unlock()
+	return caps, nil
+}
+
+// ErrMethodNotFound is returned by calls of methods which the server
+// doesn't list, with WithMethodCheck.
+var ErrMethodNotFound = errors.New("xmlrpc: method not found")
+
+// WithMethodCheck makes the client check the names of methods against the
+// result of system.listMethods, fetched with the first call and cached,
+// failing calls of other methods with ErrMethodNotFound before sending
+// them. Calls fail as well if the methods can't be listed.
+func WithMethodCheck() Option {
+	return func(c *Client) {
+		c.checkMethods = true
+	}
+}
+
+// HasMethod reports whether the server lists the method name. The result of
+// system.listMethods is cached for later calls.
+func (c *Client) HasMethod(ctx context.Context, name string) (bool, error) {
+	c.mu.Lock()
+	methods := c.methods
+	c.mu.Unlock()
+	if methods == nil {
+		v, err := c.CallContext(ctx, "system.listMethods")
+		if err != nil {
+			return false, err
+		}
+		var names []string
+		if err := Unmarshal(v, &names); err != nil {
+			return false, err
+		}
+		methods = make(map[string]bool, len(names))
+		for _, n := range names {
+			methods[n] = true
+		}
+		c.mu.Lock()
+		c.methods = methods
+		c.mu.Unlock()
+	}
+	return methods[name], nil
+}
+
+// checkMethod returns ErrMethodNotFound if name isn't listed by the server,
+// with WithMethodCheck.
+func (c *Client) checkMethod(ctx context.Context, name string) error {
+	if !c.checkMethods || name == "system.listMethods" {
+		return nil
+	}
+	// The CallOptions of the call don't apply to listing the methods.
+	ok, err := c.HasMethod(context.WithValue(ctx, callOptionsKey{}, &callOptions{}), name)
+	if err != nil {
+		return fmt.Errorf("xmlrpc: can't check method %s: %w", name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrMethodNotFound, name)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	calls := 0
+	s := NewServer()
+	s.Register("system.getCapabilities", func(args ...interface{}) (interface{}, error) {
+		calls++
+		return Struct{
+			"faults_interop": Struct{"specUrl": "http://xmlrpc-epi.sourceforge.net/specs/rfc.fault_codes.php", "specVersion": 20010516},
+			"i8":             Struct{"specUrl": "http://ws.apache.org/xmlrpc/types.html", "specVersion": 1},
+		}, nil
+	})
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	var dump bytes.Buffer
+	c := NewClient(ts.URL, WithRequestDump(&dump))
+	if _, err := c.Call("echo", int64(1)<<40); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dump.String(), "<int>1099511627776</int>") {
+		t.Fatalf("want int before negotiation but got %s", dump.String())
+	}
+
+	caps, err := c.Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.FaultsInterop || !caps.I8 || caps.Introspection || caps.Nil || caps.MultiCall {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+	if v := caps.All["faults_interop"].SpecVersion; v != 20010516 {
+		t.Fatalf("want spec version 20010516 but got %v", v)
+	}
+	if _, err := c.Capabilities(); err != nil || calls != 1 {
+		t.Fatalf("want cached capabilities but got %d calls, %v", calls, err)
+	}
+
+	dump.Reset()
+	v, err := c.Call("echo", int64(1)<<40, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dump.String(), "<i8>1099511627776</i8></value></param><param><value><int>1</int>") {
+		t.Fatalf("want i8 after negotiation but got %s", dump.String())
+	}
+	if v != 1<<40 {
+		t.Fatalf("want %d but got %v", 1<<40, v)
+	}
+}
+
+func TestHasMethod(t *testing.T) {
+	var calls []string
+	s := NewServer(WithHooks(func(c *ServerCall) {
+		calls = append(calls, c.Method)
+	}, nil))
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithMethodCheck())
+	for _, name := range []string{"echo", "system.listMethods", "ehco"} {
+		ok, err := c.HasMethod(context.Background(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != (name != "ehco") {
+			t.Fatalf("%s: want %v", name, !ok)
+		}
+	}
+	if v, err := c.Call("echo", 1); err != nil || v != 1 {
+		t.Fatalf("want 1 but got %v, %v", v, err)
+	}
+	if _, err := c.Call("ehco", 1); !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("want ErrMethodNotFound but got %v", err)
+	}
+	if strings.Join(calls, " ") != "system.listMethods echo" {
+		t.Fatalf("want methods listed once and no call of unknown methods but got %v", calls)
+	}
+
+	if _, err := c.With(WithPath("/other")).Call("echo", 1); err != nil || calls[len(calls)-2] != "system.listMethods" {
+		t.Fatalf("want methods listed again for another path but got %v, %v", calls, err)
+	}
+
+	var raw bytes.Buffer
+	var requests int
+	c = NewClient(ts.URL, WithMethodCheck())
+	_, err := c.Call("echo", 1, CallRawResponse(&raw), CallRequestHook(func(*http.Request) { requests++ }))
+	if err != nil || strings.Count(raw.String(), "<methodResponse>") != 1 || requests != 1 {
+		t.Fatalf("want call options applied to the call only but got %d requests, %q, %v", requests, raw.String(), err)
+	}
+}
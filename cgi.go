@@ -0,0 +1,38 @@
+package xmlrpc
+
+import (
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/fcgi"
+)
+
+// ServeCGI handles the call of the current CGI request, reading it from
+// stdin and writing the response to stdout, so that s can replace an
+// xmlrpc.php script on hosts without long running processes.
+func (s *Server) ServeCGI() error {
+	return cgi.Serve(s.cgiHandler())
+}
+
+// ServeFastCGI accepts FastCGI connections on l, or on stdin if l is nil
+// as when started by the web server, and handles their calls.
+func (s *Server) ServeFastCGI(l net.Listener) error {
+	return fcgi.Serve(l, s.cgiHandler())
+}
+
+// cgiHandler returns s as a handler for CGI and FastCGI, which don't
+// recover from panics aborting incomplete responses.
+func (s *Server) cgiHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ServeHTTP(&cgiResponse{ResponseWriter: w}, r)
+	})
+}
+
+// cgiResponse is the ResponseWriter of CGI and FastCGI. They can't drop
+// the connection, so an aborted response ends where it stopped, as XML
+// which isn't well-formed.
+type cgiResponse struct {
+	http.ResponseWriter
+}
+
+func (w *cgiResponse) abort() {}
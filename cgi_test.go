@@ -0,0 +1,40 @@
+package xmlrpc
+
+import (
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestServeCGI(t *testing.T) {
+	if os.Getenv("XMLRPC_CGI_CHILD") == "1" {
+		s := NewServer()
+		s.Register("echo", func(args ...interface{}) (interface{}, error) {
+			return args[0], nil
+		})
+		if err := s.ServeCGI(); err != nil {
+			t.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	ts := httptest.NewServer(&cgi.Handler{
+		Path: exe,
+		Args: []string{"-test.run=^TestServeCGI$"},
+		Env:  []string{"XMLRPC_CGI_CHILD=1"},
+	})
+	defer ts.Close()
+
+	v, err := NewClient(ts.URL).Call("echo", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hello" {
+		t.Fatalf("want %q but got %v", "hello", v)
+	}
+}
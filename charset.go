@@ -0,0 +1,91 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// CharsetReader returns a reader that converts input declared in the given
+// charset to UTF-8. It has the same signature as
+// golang.org/x/net/html/charset.NewReaderLabel, which can be passed to
+// WithCharsetReader to support charsets such as GBK or Shift_JIS.
+type CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+// WithRequestCharsetReader sets the function used by the server to convert
+// requests in a charset other than UTF-8, declared by the payload or by the
+// charset parameter of the Content-Type header. By default only US-ASCII,
+// ISO-8859-1 and Windows-1252 are supported, as with WithCharsetReader.
+func WithRequestCharsetReader(fn CharsetReader) ServerOption {
+	return func(s *Server) {
+		s.dec.charsetReader = fn
+	}
+}
+
+// contentCharset returns the charset parameter of the Content-Type header
+// value h, or "" if it is missing or UTF-8.
+func contentCharset(h string) string {
+	_, params, err := mime.ParseMediaType(h)
+	if err != nil {
+		return ""
+	}
+	charset := strings.ToLower(params["charset"])
+	if charset == "utf-8" || charset == "utf8" {
+		return ""
+	}
+	return charset
+}
+
+// windows1252 maps the bytes 0x80-0x9f of Windows-1252 to runes. The rest
+// of the charset is identical to ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// defaultCharsetReader handles the single byte charsets legacy servers
+// declare most often.
+func defaultCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		return &singleByteReader{r: bufio.NewReader(input)}, nil
+	case "windows-1252", "cp1252":
+		return &singleByteReader{r: bufio.NewReader(input), high: &windows1252}, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// singleByteReader converts a single byte charset to UTF-8.
+type singleByteReader struct {
+	r    *bufio.Reader
+	high *[32]rune
+	buf  []byte
+}
+
+func (s *singleByteReader) Read(p []byte) (int, error) {
+	for len(s.buf) < len(p) {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			if len(s.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		r := rune(c)
+		if s.high != nil && c >= 0x80 && c < 0xa0 {
+			r = s.high[c-0x80]
+		}
+		s.buf = append(s.buf, string(r)...)
+		if s.r.Buffered() == 0 {
+			// Don't block waiting for more input.
+			break
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
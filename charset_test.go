@@ -0,0 +1,147 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCharsetLatin1(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>" +
+			"<methodResponse><params><param><value><string>caf\xe9 \x80</string></value></param></params></methodResponse>"))
+	}))
+	defer ts.Close()
+
+	v, err := NewClient(ts.URL).Call("Irrelevant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "café \u0080" {
+		t.Fatalf("want %q but got %q", "café \u0080", v)
+	}
+}
+
+func TestCharsetWindows1252(t *testing.T) {
+	v, err := newDecoder(strings.NewReader("<?xml version=\"1.0\" encoding=\"windows-1252\"?>"+
+		"<methodResponse><params><param><value><string>\x80 \x93x\x94</string></value></param></params></methodResponse>"), decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "€ “x”" {
+		t.Fatalf("want %q but got %q", "€ “x”", v)
+	}
+}
+
+func TestCharsetReaderOption(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="x-custom"?>` +
+			`<methodResponse><params><param><value><string>abc</string></value></param></params></methodResponse>`))
+	}))
+	defer ts.Close()
+
+	if _, err := NewClient(ts.URL).Call("Irrelevant"); err == nil {
+		t.Fatal("want error for unknown charset")
+	}
+	var got string
+	client := NewClient(ts.URL, WithCharsetReader(func(charset string, input io.Reader) (io.Reader, error) {
+		got = charset
+		return input, nil
+	}))
+	if _, err := client.Call("Irrelevant"); err != nil {
+		t.Fatal(err)
+	}
+	if got != "x-custom" {
+		t.Fatalf("want charset %q but got %q", "x-custom", got)
+	}
+}
+
+// gbkReader stands in for a GBK decoder such as charset.NewReaderLabel of
+// golang.org/x/net/html/charset, knowing just the characters of the test.
+func gbkReader(charset string, input io.Reader) (io.Reader, error) {
+	if !strings.EqualFold(charset, "gbk") {
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	b, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(strings.NewReplacer("\xd6\xd0", "中", "\xce\xc4", "文").Replace(string(b))), nil
+}
+
+func TestCharsetGBK(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<?xml version=\"1.0\" encoding=\"GBK\"?>" +
+			"<methodResponse><params><param><value><string>\xd6\xd0\xce\xc4</string></value></param></params></methodResponse>"))
+	}))
+	defer ts.Close()
+
+	if _, err := NewClient(ts.URL).Call("Irrelevant"); err == nil {
+		t.Fatal("want GBK unsupported by default")
+	}
+	v, err := NewClient(ts.URL, WithCharsetReader(gbkReader)).Call("Irrelevant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "中文" {
+		t.Fatalf("want %q but got %q", "中文", v)
+	}
+}
+
+func TestServerCharset(t *testing.T) {
+	s := NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/xml", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><methodCall><methodName>echo</methodName><params><param><value>caf\xe9</value></param></params></methodCall>"},
+		{"text/xml; charset=ISO-8859-1", "<methodCall><methodName>echo</methodName><params><param><value>caf\xe9</value></param></params></methodCall>"},
+		{"text/xml; charset=iso-8859-1", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><methodCall><methodName>echo</methodName><params><param><value>caf\xe9</value></param></params></methodCall>"},
+		{"text/xml; charset=utf-8", "<methodCall><methodName>echo</methodName><params><param><value>café</value></param></params></methodCall>"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		v, err := newDecoder(rec.Body, decodeOptions{}).response()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.contentType, err)
+		}
+		if v != "café" {
+			t.Errorf("%s: want %q but got %q", tt.contentType, "café", v)
+		}
+	}
+}
+
+func TestServerCharsetUnsupported(t *testing.T) {
+	s := NewServer()
+	req := httptest.NewRequest("POST", "/", strings.NewReader("<methodCall><methodName>x</methodName></methodCall>"))
+	req.Header.Set("Content-Type", "text/xml; charset=koi8-r")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	_, err := newDecoder(rec.Body, decodeOptions{}).response()
+	if f, ok := err.(*Fault); !ok || f.Code != UnsupportedEncoding {
+		t.Fatalf("want UnsupportedEncoding fault but got %v", err)
+	}
+
+	s = NewServer(WithRequestCharsetReader(func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}))
+	s.Register("x", func(args ...interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/", strings.NewReader("<methodCall><methodName>x</methodName></methodCall>"))
+	req.Header.Set("Content-Type", "text/xml; charset=koi8-r")
+	s.ServeHTTP(rec, req)
+	if v, err := newDecoder(rec.Body, decodeOptions{}).response(); err != nil || v != "ok" {
+		t.Fatalf("want ok but got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Command xmlrpc-stubgen generates a typed Go client for an XML-RPC server
+// from the method signatures the server reports through introspection.
+//
+//	xmlrpc-stubgen [-pkg name] [-type name] [-o file] url
+//
+// With -interface it instead implements a Go interface declared in the file
+// given by -src, which defaults to $GOFILE for use with go generate:
+//
+//	//go:generate xmlrpc-stubgen -interface Blog -o blog_client.go
+//	type Blog interface {
+//		// xmlrpc: metaWeblog.getPost
+//		GetPost(id, user, password string) (Post, error)
+//	}
+//
+// With -types it instead declares Go structs for the results of captured
+// methodResponse payloads of a method, such as those of WithResponseDump:
+//
+//	xmlrpc-stubgen -types Post [-pkg name] [-o file] response.xml...
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/stubgen"
+)
+
+func run() error {
+	var (
+		pkg   = flag.String("pkg", "client", "package name of the generated code")
+		typ   = flag.String("type", "Client", "name of the generated client type")
+		out   = flag.String("o", "", "output file; standard output if empty")
+		iface = flag.String("interface", "", "implement the named Go interface instead of introspecting a server")
+		src   = flag.String("src", os.Getenv("GOFILE"), "Go file declaring the interface")
+		types = flag.String("types", "", "declare the named struct type of the results of sample responses")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: xmlrpc-stubgen [flags] url")
+		fmt.Fprintln(os.Stderr, "       xmlrpc-stubgen -interface name [flags]")
+		fmt.Fprintln(os.Stderr, "       xmlrpc-stubgen -types name [flags] response.xml...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	switch {
+	case *iface != "" && *types != "",
+		*iface != "" && flag.NArg() != 0,
+		*types != "" && flag.NArg() == 0,
+		*iface == "" && *types == "" && flag.NArg() != 1:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var buf bytes.Buffer
+	if *types != "" {
+		var samples [][]byte
+		for _, name := range flag.Args() {
+			b, err := ioutil.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, b)
+		}
+		cfg := stubgen.Config{Package: *pkg, Source: strings.Join(flag.Args(), ", ")}
+		if err := stubgen.GenerateTypes(&buf, cfg, *types, samples...); err != nil {
+			return err
+		}
+	} else if *iface != "" {
+		if *src == "" {
+			return errors.New("-src or $GOFILE is required with -interface")
+		}
+		it, err := stubgen.ParseInterface(*src, nil, *iface)
+		if err != nil {
+			return err
+		}
+		cfg := stubgen.Config{Type: *typ, Source: *src}
+		if isSet("pkg") {
+			cfg.Package = *pkg
+		}
+		if !isSet("type") {
+			cfg.Type = ""
+		}
+		if err := stubgen.GenerateInterface(&buf, cfg, it); err != nil {
+			return err
+		}
+	} else {
+		methods, err := stubgen.Introspect(xmlrpc.NewClient(flag.Arg(0)))
+		if err != nil {
+			return err
+		}
+		err = stubgen.Generate(&buf, stubgen.Config{Package: *pkg, Type: *typ, Source: flag.Arg(0)}, methods)
+		if err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(*out, buf.Bytes(), 0644)
+}
+
+// isSet reports whether the flag name was given on the command line.
+func isSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "xmlrpc-stubgen:", err)
+		os.Exit(1)
+	}
+}
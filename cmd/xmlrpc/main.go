@@ -0,0 +1,185 @@
+// Command xmlrpc calls a method of an XML-RPC server and prints the result as
+// JSON.
+//
+//	xmlrpc [flags] url method [arg...]
+//
+// Arguments are strings unless they carry a type prefix such as int:42,
+// double:1.5, bool:true, base64:aGVsbG8=, dateTime:20060102T15:04:05 or
+// json:{"a":[1,2]}. With -json the arguments are read from a JSON array
+// instead, "-" meaning standard input.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+type basicAuth struct {
+	user, password string
+	rt             http.RoundTripper
+}
+
+func (t *basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.user, t.password)
+	return t.rt.RoundTrip(req)
+}
+
+// parseArg converts a command-line argument to a value.
+func parseArg(s string) (interface{}, error) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return s, nil
+	}
+	typ, v := s[:i], s[i+1:]
+	switch typ {
+	case "string":
+		return v, nil
+	case "int", "i4":
+		return strconv.Atoi(v)
+	case "double":
+		return strconv.ParseFloat(v, 64)
+	case "bool", "boolean":
+		return strconv.ParseBool(v)
+	case "base64":
+		return base64.StdEncoding.DecodeString(v)
+	case "dateTime", "dateTime.iso8601":
+		return time.Parse("20060102T15:04:05", v)
+	case "json":
+		return xmlrpc.FromJSON([]byte(v))
+	}
+	// Not a type prefix, such as in a URL.
+	return s, nil
+}
+
+// parseJSONArgs converts a JSON array to arguments.
+func parseJSONArgs(b []byte) ([]interface{}, error) {
+	v, err := xmlrpc.FromJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	args, ok := v.(xmlrpc.Array)
+	if !ok {
+		return nil, errors.New("arguments must be a JSON array")
+	}
+	return args, nil
+}
+
+func run() error {
+	var (
+		jsonArgs = flag.String("json", "", "read arguments from a JSON array; - for stdin")
+		user     = flag.String("user", "", "user name for basic authentication")
+		password = flag.String("password", "", "password for basic authentication")
+		insecure = flag.Bool("insecure", false, "skip verification of the server certificate")
+		cacert   = flag.String("cacert", "", "PEM file of CA certificates to verify the server with")
+		cert     = flag.String("cert", "", "PEM file of the client certificate")
+		key      = flag.String("key", "", "PEM file of the client key")
+		timeout  = flag.Duration("timeout", 10*time.Second, "timeout of the call")
+		indent   = flag.Bool("indent", true, "indent the output")
+		dump     = flag.Bool("dump", false, "dump the request and the response to stderr")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: xmlrpc [flags] url method [arg...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var args []interface{}
+	if *jsonArgs != "" {
+		if flag.NArg() > 2 {
+			return errors.New("-json can't be combined with positional arguments")
+		}
+		var b []byte
+		var err error
+		if *jsonArgs == "-" {
+			b, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			b = []byte(*jsonArgs)
+		}
+		if err != nil {
+			return err
+		}
+		if args, err = parseJSONArgs(b); err != nil {
+			return err
+		}
+	} else {
+		for _, s := range flag.Args()[2:] {
+			v, err := parseArg(s)
+			if err != nil {
+				return fmt.Errorf("argument %q: %v", s, err)
+			}
+			args = append(args, v)
+		}
+	}
+
+	config := &tls.Config{InsecureSkipVerify: *insecure}
+	if *cacert != "" {
+		b, err := ioutil.ReadFile(*cacert)
+		if err != nil {
+			return err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(b) {
+			return fmt.Errorf("%s: no certificates found", *cacert)
+		}
+	}
+	if *cert != "" {
+		c, err := tls.LoadX509KeyPair(*cert, *key)
+		if err != nil {
+			return err
+		}
+		config.Certificates = []tls.Certificate{c}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	var rt http.RoundTripper = transport
+	if *user != "" {
+		rt = &basicAuth{*user, *password, rt}
+	}
+
+	opts := []xmlrpc.Option{xmlrpc.WithHTTPClient(&http.Client{Transport: rt, Timeout: *timeout})}
+	if *dump {
+		opts = append(opts, xmlrpc.WithRequestDump(os.Stderr), xmlrpc.WithResponseDump(os.Stderr))
+	}
+	c := xmlrpc.NewClient(flag.Arg(0), opts...)
+
+	v, err := c.Call(flag.Arg(1), args...)
+	if err != nil {
+		return err
+	}
+	b, err := xmlrpc.ToJSON(v)
+	if err != nil {
+		return err
+	}
+	if *indent {
+		var out interface{}
+		json.Unmarshal(b, &out)
+		b, _ = json.MarshalIndent(out, "", "  ")
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "xmlrpc:", err)
+		os.Exit(1)
+	}
+}
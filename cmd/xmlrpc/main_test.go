@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestParseArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want interface{}
+	}{
+		{"foo", "foo"},
+		{"string:int:1", "int:1"},
+		{"int:42", 42},
+		{"double:1.5", 1.5},
+		{"bool:true", true},
+		{"base64:aGVsbG8=", []byte("hello")},
+		{"dateTime:19980717T14:08:55", time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC)},
+		{`json:{"a":[1,"b"]}`, xmlrpc.Struct{"a": xmlrpc.Array{1, "b"}}},
+		{"http://example.com/", "http://example.com/"},
+	}
+	for _, test := range tests {
+		got, err := parseArg(test.arg)
+		if err != nil {
+			t.Fatalf("%s: %v", test.arg, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: want %#v but got %#v", test.arg, test.want, got)
+		}
+	}
+	if _, err := parseArg("int:x"); err == nil {
+		t.Fatal("want error for int:x")
+	}
+}
+
+func TestParseJSONArgs(t *testing.T) {
+	args, err := parseJSONArgs([]byte(`[1, "a", {"b": true}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{1, "a", xmlrpc.Struct{"b": true}}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("want %v but got %v", want, args)
+	}
+	if _, err := parseJSONArgs([]byte(`{}`)); err == nil {
+		t.Fatal("want error for an object")
+	}
+}
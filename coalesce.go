@@ -0,0 +1,80 @@
+package xmlrpc
+
+import (
+	"context"
+	"sync"
+)
+
+// WithCoalescing makes concurrent calls of Call and CallContext with the
+// same method and arguments share a single request, for calls which may be
+// retried, as of RetryPolicy.Idempotent and WithIdempotent. The shared
+// request is canceled once all calls sharing it are. Shared values must
+// not be modified. Clients derived with With share requests only if they
+// send the same headers and tokens and sign requests alike. Calls with the
+// CallOptions CallHeader, CallRequestHook, CallResponseHook or
+// CallRawResponse make requests of their own.
+func WithCoalescing() Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{m: map[string]*flight{}}
+	}
+}
+
+// flightGroup tracks the requests in flight by key.
+type flightGroup struct {
+	mu sync.Mutex
+	m  map[string]*flight
+}
+
+// flight is a request in flight.
+type flight struct {
+	done    chan struct{}
+	v       interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do returns the result of fn, sharing it with concurrent calls of the
+// same key. fn runs with the values but not the cancellation of ctx.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	f, ok := g.m[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.m[key] = f
+		go func() {
+			f.v, f.err = fn(fctx)
+			g.forget(key, f)
+			cancel()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.v, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			if g.m[key] == f {
+				delete(g.m, key)
+			}
+			f.cancel()
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget drops the flight f of key, unless replaced.
+func (g *flightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	if g.m[key] == f {
+		delete(g.m, key)
+	}
+	g.mu.Unlock()
+}
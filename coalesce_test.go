@@ -0,0 +1,93 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescing(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	s := NewServer()
+	s.Register("get", func(args ...interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return args[0], nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL, WithCoalescing())
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.Call("get", Struct{"a": 1, "b": 2})
+		}(i)
+	}
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("want 1 request but got %d", n)
+	}
+	for _, v := range results {
+		if st, ok := v.(Struct); !ok || st["a"] != 1 {
+			t.Fatalf("unexpected result %v", v)
+		}
+	}
+
+	// Calls which aren't idempotent aren't coalesced.
+	ctx := WithIdempotent(context.Background(), false)
+	for i := 0; i < 2; i++ {
+		c.CallContext(ctx, "get", 1)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("want 3 requests but got %d", n)
+	}
+}
+
+func TestCoalescingCancel(t *testing.T) {
+	canceled := make(chan struct{})
+	s := NewServer()
+	s.RegisterContext("get", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL, WithCoalescing())
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() { _, err := c.CallContext(ctx1, "get"); errs <- err }()
+	go func() { _, err := c.CallContext(ctx2, "get"); errs <- err }()
+	time.Sleep(20 * time.Millisecond)
+	cancel1()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("want canceled but got %v", err)
+	}
+	select {
+	case <-canceled:
+		t.Fatal("want shared request kept for the other call")
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel2()
+	<-errs
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("want shared request canceled")
+	}
+}
@@ -0,0 +1,158 @@
+// Package cobbler is a client for the XML-RPC API of Cobbler, served at
+// /cobbler_api.
+package cobbler
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a Cobbler. Methods which change anything
+// need the token of a login.
+type Client struct {
+	c     *xmlrpc.Client
+	token string
+}
+
+// New returns a Client of the Cobbler at url, e.g.
+// http://cobbler.example.com/cobbler_api.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Token returns the token of the login, or "" if not logged in.
+func (c *Client) Token() string {
+	return c.token
+}
+
+// call calls the method name with args and stores the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Login logs in as username. The token it returns is sent with later
+// calls which need it.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	return c.call(ctx, &c.token, "login", username, password)
+}
+
+// System is a system, a machine Cobbler provisions. Cobbler sends "~" for
+// values which aren't set, and "<<inherit>>" for values inherited from the
+// profile.
+type System struct {
+	Name           string               `xmlrpc:"name"`
+	UID            string               `xmlrpc:"uid"`
+	Profile        string               `xmlrpc:"profile"`
+	Image          string               `xmlrpc:"image"`
+	Hostname       string               `xmlrpc:"hostname"`
+	Status         string               `xmlrpc:"status"`
+	Comment        string               `xmlrpc:"comment"`
+	NetbootEnabled bool                 `xmlrpc:"netboot_enabled"`
+	Interfaces     map[string]Interface `xmlrpc:"interfaces"`
+	Created        float64              `xmlrpc:"ctime"` // Unix time
+	Modified       float64              `xmlrpc:"mtime"`
+}
+
+// Interface is a network interface of a system.
+type Interface struct {
+	MACAddress string `xmlrpc:"mac_address"`
+	IPAddress  string `xmlrpc:"ip_address"`
+	Netmask    string `xmlrpc:"netmask"`
+	DNSName    string `xmlrpc:"dns_name"`
+	Static     bool   `xmlrpc:"static"`
+}
+
+// GetSystems returns all systems.
+func (c *Client) GetSystems(ctx context.Context) ([]System, error) {
+	var r []System
+	if err := c.call(ctx, &r, "get_systems"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetSystem returns the system name, or nil if there is none.
+func (c *Client) GetSystem(ctx context.Context, name string) (*System, error) {
+	v, err := c.c.CallContext(ctx, "get_system", name)
+	if err != nil {
+		return nil, err
+	}
+	// Missing systems are "~".
+	if _, ok := v.(string); ok {
+		return nil, nil
+	}
+	var s System
+	if err := xmlrpc.Unmarshal(v, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// NewSystem creates a system with fields, such as "name", "profile" and
+// "modify_interface", which takes a Struct of fields of interfaces keyed
+// like "macaddress-eth0".
+func (c *Client) NewSystem(ctx context.Context, fields map[string]interface{}) error {
+	var handle string
+	if err := c.call(ctx, &handle, "new_system", c.token); err != nil {
+		return err
+	}
+	if err := c.modify(ctx, handle, fields); err != nil {
+		return err
+	}
+	var ok bool
+	return c.call(ctx, &ok, "save_system", handle, c.token)
+}
+
+// ModifySystem sets fields of the system name like NewSystem.
+func (c *Client) ModifySystem(ctx context.Context, name string, fields map[string]interface{}) error {
+	var handle string
+	if err := c.call(ctx, &handle, "get_system_handle", name, c.token); err != nil {
+		return err
+	}
+	if err := c.modify(ctx, handle, fields); err != nil {
+		return err
+	}
+	var ok bool
+	return c.call(ctx, &ok, "save_system", handle, c.token)
+}
+
+// modify sets fields of the system handle, in the order of their names.
+func (c *Client) modify(ctx context.Context, handle string, fields map[string]interface{}) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var ok bool
+		if err := c.call(ctx, &ok, "modify_system", handle, k, fields[k], c.token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveSystem removes the system name.
+func (c *Client) RemoveSystem(ctx context.Context, name string) error {
+	var ok bool
+	return c.call(ctx, &ok, "remove_system", name, c.token)
+}
+
+// Sync writes the configuration of the DHCP, DNS and boot files of the
+// systems.
+func (c *Client) Sync(ctx context.Context) error {
+	var ok bool
+	return c.call(ctx, &ok, "sync", c.token)
+}
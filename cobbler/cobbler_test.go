@@ -0,0 +1,64 @@
+package cobbler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestSystems(t *testing.T) {
+	sys := xmlrpc.Struct{
+		"name": "web01", "profile": "centos-x86_64", "hostname": "web01.example.com",
+		"netboot_enabled": true, "status": "production", "comment": "~", "mtime": 1700000000.5,
+		"interfaces": xmlrpc.Struct{"eth0": xmlrpc.Struct{"mac_address": "aa:bb:cc:dd:ee:ff", "ip_address": "10.0.0.5", "static": true}},
+	}
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"login":         xmlrpctest.Value("tok"),
+		"get_systems":   xmlrpctest.Value(xmlrpc.Array{sys}),
+		"new_system":    xmlrpctest.Value("___NEW___system::abc"),
+		"modify_system": xmlrpctest.Value(true),
+		"save_system":   xmlrpctest.Value(true),
+		"sync":          xmlrpctest.Value(true),
+		"get_system": func(args ...interface{}) (interface{}, error) {
+			if args[0] == "web01" {
+				return sys, nil
+			}
+			return "~", nil
+		},
+	})
+	defer s.Close()
+	c := New(s.URL)
+	ctx := context.Background()
+
+	if err := c.Login(ctx, "cobbler", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	systems, err := c.GetSystems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(systems) != 1 || systems[0].Name != "web01" || !systems[0].NetbootEnabled || systems[0].Interfaces["eth0"].IPAddress != "10.0.0.5" {
+		t.Fatalf("unexpected systems %+v", systems)
+	}
+	if sys, err := c.GetSystem(ctx, "web01"); err != nil || sys == nil || sys.Modified != 1700000000.5 {
+		t.Fatalf("unexpected system %+v, %v", sys, err)
+	}
+	if sys, err := c.GetSystem(ctx, "missing"); err != nil || sys != nil {
+		t.Fatalf("want no system but got %+v, %v", sys, err)
+	}
+
+	if err := c.NewSystem(ctx, map[string]interface{}{"name": "web02", "profile": "centos-x86_64"}); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "new_system", "tok")
+	s.AssertCalled(t, "modify_system", "___NEW___system::abc", "name", "web02", "tok")
+	s.AssertCalled(t, "modify_system", "___NEW___system::abc", "profile", "centos-x86_64", "tok")
+	s.AssertCalled(t, "save_system", "___NEW___system::abc", "tok")
+
+	if err := c.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "sync", "tok")
+}
@@ -0,0 +1,95 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// EncodeMethodCall writes a methodCall of method with args to w. Use it
+// with DecodeMethodResponse to make calls over transports other than HTTP.
+func EncodeMethodCall(w io.Writer, method string, args ...interface{}) error {
+	if err := validateMethodName(method); err != nil {
+		return err
+	}
+	return (&encoder{}).writeRequest(w, method, args...)
+}
+
+// DecodeMethodResponse reads a methodResponse from r and returns the value
+// of its param. A fault response is returned as a *Fault error.
+func DecodeMethodResponse(r io.Reader) (interface{}, error) {
+	return newDecoder(r, decodeOptions{}).response()
+}
+
+// DecodeMethodCall reads a methodCall from r and returns the method name
+// and the arguments. Use it to implement servers on top of transports other
+// than HTTP or with a dispatcher of your own.
+func DecodeMethodCall(r io.Reader) (method string, args []interface{}, err error) {
+	return newDecoder(r, decodeOptions{}).call()
+}
+
+// ParseResponse is like DecodeMethodResponse for a payload held in memory,
+// e.g. for fuzzing.
+func ParseResponse(b []byte) (interface{}, error) {
+	return DecodeMethodResponse(bytes.NewReader(b))
+}
+
+// ParseRequest is like DecodeMethodCall for a payload held in memory.
+func ParseRequest(b []byte) (method string, args []interface{}, err error) {
+	return DecodeMethodCall(bytes.NewReader(b))
+}
+
+// MethodCall is a call of the method Name with Params.
+type MethodCall struct {
+	Name   string
+	Params []interface{}
+}
+
+// Encode writes c as a methodCall to w.
+func (c *MethodCall) Encode(w io.Writer) error {
+	return EncodeMethodCall(w, c.Name, c.Params...)
+}
+
+// Decode reads a methodCall from r into c.
+func (c *MethodCall) Decode(r io.Reader) error {
+	name, params, err := DecodeMethodCall(r)
+	if err != nil {
+		return err
+	}
+	c.Name, c.Params = name, params
+	return nil
+}
+
+// MethodResponse is the response to a call. Either Fault is set, or Value
+// holds the returned value.
+type MethodResponse struct {
+	Value interface{}
+	Fault *Fault
+
+	// Header holds the headers of the HTTP response, as returned by
+	// Client.Do. It is neither encoded nor decoded.
+	Header http.Header
+}
+
+// Encode writes r as a methodResponse to w.
+func (r *MethodResponse) Encode(w io.Writer) error {
+	if r.Fault != nil {
+		return (&encoder{}).writeFault(w, r.Fault)
+	}
+	return (&encoder{}).writeResponse(w, r.Value)
+}
+
+// Decode reads a methodResponse from rd into r. Unlike DecodeMethodResponse
+// it doesn't return a fault as an error but stores it in r.Fault.
+func (r *MethodResponse) Decode(rd io.Reader) error {
+	v, err := DecodeMethodResponse(rd)
+	if f, ok := err.(*Fault); ok {
+		r.Value, r.Fault = nil, f
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.Value, r.Fault = v, nil
+	return nil
+}
@@ -0,0 +1,93 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeMethodCall(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeMethodCall(&buf, "examples.getStateName", 41); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0"?><methodCall><methodName>examples.getStateName</methodName><params><param><value><int>41</int></value></param></params></methodCall>`
+	if buf.String() != want {
+		t.Fatalf("want %q but got %q", want, buf.String())
+	}
+	if err := EncodeMethodCall(&buf, "bad name"); err == nil {
+		t.Fatal("want error for invalid method name")
+	}
+}
+
+func TestDecodeMethodResponse(t *testing.T) {
+	v, err := DecodeMethodResponse(strings.NewReader(`<?xml version="1.0"?><methodResponse><params><param><value><string>South Dakota</string></value></param></params></methodResponse>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "South Dakota" {
+		t.Fatalf("want %q but got %v", "South Dakota", v)
+	}
+
+	_, err = DecodeMethodResponse(strings.NewReader(`<?xml version="1.0"?><methodResponse><fault><value><struct><member><name>faultCode</name><value><int>4</int></value></member><member><name>faultString</name><value><string>Too many parameters.</string></value></member></struct></value></fault></methodResponse>`))
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != 4 || f.String != "Too many parameters." {
+		t.Fatalf("want fault 4 but got %v", err)
+	}
+}
+
+func TestDecodeMethodCall(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeMethodCall(&buf, "examples.add", 1, "two", Array{3.5}); err != nil {
+		t.Fatal(err)
+	}
+	method, args, err := DecodeMethodCall(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != "examples.add" {
+		t.Fatalf("want %q but got %q", "examples.add", method)
+	}
+	if len(args) != 3 || args[0] != 1 || args[1] != "two" || args[2].(Array)[0] != 3.5 {
+		t.Fatalf("want [1 two [3.5]] but got %v", args)
+	}
+
+	if _, _, err := DecodeMethodCall(strings.NewReader(`<methodResponse/>`)); err == nil {
+		t.Fatal("want error for methodResponse")
+	}
+}
+
+func TestMethodCallRoundTrip(t *testing.T) {
+	in := &MethodCall{Name: "examples.add", Params: []interface{}{1, "two"}}
+	var buf bytes.Buffer
+	if err := in.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var out MethodCall
+	if err := out.Decode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != in.Name || len(out.Params) != 2 || out.Params[0] != 1 || out.Params[1] != "two" {
+		t.Fatalf("want %v but got %v", in, out)
+	}
+}
+
+func TestMethodResponseRoundTrip(t *testing.T) {
+	for _, in := range []*MethodResponse{
+		{Value: "South Dakota"},
+		{Fault: &Fault{Code: 4, String: "Too many parameters."}},
+	} {
+		var buf bytes.Buffer
+		if err := in.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var out MethodResponse
+		if err := out.Decode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if out.Value != in.Value || (in.Fault != nil) != (out.Fault != nil) || (in.Fault != nil && *in.Fault != *out.Fault) {
+			t.Fatalf("want %+v but got %+v", in, out)
+		}
+	}
+}
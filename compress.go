@@ -0,0 +1,56 @@
+package xmlrpc
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithCompression makes the server gzip responses of at least min bytes
+// for clients which accept gzip encoding.
+func WithCompression(min int) ServerOption {
+	return func(s *Server) {
+		s.gzipMin = min
+	}
+}
+
+// writeResponse writes the encoded methodResponse body to w, compressing
+// it if enabled and accepted by the client of r.
+func (s *Server) writeResponse(w http.ResponseWriter, r *http.Request, body []byte) {
+	w.Header().Set("Content-Type", "text/xml")
+	if s.gzipMin <= 0 {
+		w.Write(body)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	if len(body) < s.gzipMin || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		w.Write(body)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	gw := gzip.NewWriter(w)
+	gw.Write(body)
+	gw.Close()
+}
+
+// acceptsGzip reports whether the Accept-Encoding header value h accepts
+// gzip encoding.
+func acceptsGzip(h string) bool {
+	for _, part := range strings.Split(h, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				q, _ = strconv.ParseFloat(v, 64)
+			}
+		}
+		return q > 0
+	}
+	return false
+}
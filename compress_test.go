@@ -0,0 +1,67 @@
+package xmlrpc
+
+import (
+	"compress/gzip"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerCompression(t *testing.T) {
+	s := NewServer(WithCompression(1000))
+	s.Register("repeat", func(args ...interface{}) (interface{}, error) {
+		return strings.Repeat("x", args[0].(int)), nil
+	})
+	call := func(n int, accept string) *httptest.ResponseRecorder {
+		body := `<methodCall><methodName>repeat</methodName><params><param><value><int>` + string(rune('0'+n)) + `000</int></value></param></params></methodCall>`
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(2, "gzip, deflate")
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("want gzip encoding but got %q", rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := newDecoder(zr, decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != strings.Repeat("x", 2000) {
+		t.Fatal("unexpected response")
+	}
+
+	for _, accept := range []string{"", "gzip;q=0", "deflate"} {
+		if rec := call(2, accept); rec.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%q: want no encoding", accept)
+		}
+	}
+	if rec := call(0, "gzip"); rec.Header().Get("Content-Encoding") != "" {
+		t.Error("want small response not compressed")
+	}
+}
+
+func TestServerCompressionClient(t *testing.T) {
+	s := NewServer(WithCompression(100))
+	s.Register("repeat", func(args ...interface{}) (interface{}, error) {
+		return strings.Repeat("x", 1000), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	v, err := NewClient(ts.URL).Call("repeat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != strings.Repeat("x", 1000) {
+		t.Fatal("unexpected response")
+	}
+}
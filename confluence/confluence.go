@@ -0,0 +1,112 @@
+// Package confluence is a client for the legacy XML-RPC API of Confluence
+// Server, served at /rpc/xmlrpc, with the methods of its confluence2
+// namespace.
+package confluence
+
+import (
+	"context"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a Confluence. It calls them anonymously
+// until it logs in.
+type Client struct {
+	c     *xmlrpc.Client
+	token string
+}
+
+// New returns a Client of the Confluence at url, e.g.
+// https://wiki.example.com/rpc/xmlrpc.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap. They take the token of the login as first argument.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Token returns the token of the login, or "" if not logged in.
+func (c *Client) Token() string {
+	return c.token
+}
+
+// call calls the method confluence2.name with the token and args, and
+// stores the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, "confluence2."+name, append([]interface{}{c.token}, args...)...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Login logs in as username. The token it returns is sent with later
+// calls until Logout.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	v, err := c.c.CallContext(ctx, "confluence2.login", username, password)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, &c.token)
+}
+
+// Logout logs out.
+func (c *Client) Logout(ctx context.Context) error {
+	var ok bool
+	err := c.call(ctx, &ok, "logout")
+	c.token = ""
+	return err
+}
+
+// Page is a page. Pages are identified by IDs which are numbers sent as
+// strings.
+type Page struct {
+	ID            string    `xmlrpc:"id,omitempty"`
+	Space         string    `xmlrpc:"space,omitempty"`
+	ParentID      string    `xmlrpc:"parentId,omitempty"`
+	Title         string    `xmlrpc:"title,omitempty"`
+	URL           string    `xmlrpc:"url,omitempty"`
+	Version       int       `xmlrpc:"version,omitempty"`
+	Content       string    `xmlrpc:"content,omitempty"` // storage format
+	Created       time.Time `xmlrpc:"created,omitempty"`
+	Creator       string    `xmlrpc:"creator,omitempty"`
+	Modified      time.Time `xmlrpc:"modified,omitempty"`
+	Modifier      string    `xmlrpc:"modifier,omitempty"`
+	HomePage      bool      `xmlrpc:"homePage,omitempty"`
+	Permissions   int       `xmlrpc:"permissions,omitempty"`
+	ContentStatus string    `xmlrpc:"contentStatus,omitempty"`
+	Current       bool      `xmlrpc:"current,omitempty"`
+}
+
+// GetPage returns the page id.
+func (c *Client) GetPage(ctx context.Context, id string) (*Page, error) {
+	var p Page
+	if err := c.call(ctx, &p, "getPage", id); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPageByTitle returns the page titled title in the space spaceKey.
+func (c *Client) GetPageByTitle(ctx context.Context, spaceKey, title string) (*Page, error) {
+	var p Page
+	if err := c.call(ctx, &p, "getPage", spaceKey, title); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// StorePage creates the page p if it has no ID, and updates it otherwise,
+// returning the page stored. Updates must have the ID and the current
+// version of the page, such as returned by GetPage.
+func (c *Client) StorePage(ctx context.Context, p *Page) (*Page, error) {
+	var r Page
+	if err := c.call(ctx, &r, "storePage", p); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,59 @@
+package confluence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestPages(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	page := xmlrpc.Struct{
+		"id": "42", "space": "DOC", "title": "Home", "version": 3,
+		"content": "<p>hi</p>", "modified": modified, "current": true,
+	}
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"confluence2.login":     xmlrpctest.Value("tok"),
+		"confluence2.logout":    xmlrpctest.Value(true),
+		"confluence2.getPage":   xmlrpctest.Value(page),
+		"confluence2.storePage": xmlrpctest.Value(page),
+	})
+	defer s.Close()
+	c := New(s.URL)
+	ctx := context.Background()
+
+	if err := c.Login(ctx, "admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Token() != "tok" {
+		t.Fatalf("unexpected token %q", c.Token())
+	}
+	p, err := c.GetPage(ctx, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "Home" || p.Version != 3 || !p.Modified.Equal(modified) || !p.Current {
+		t.Fatalf("unexpected page %+v", p)
+	}
+	s.AssertCalled(t, "confluence2.getPage", "tok", "42")
+	if _, err := c.GetPageByTitle(ctx, "DOC", "Home"); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "confluence2.getPage", "tok", "DOC", "Home")
+
+	if _, err := c.StorePage(ctx, &Page{Space: "DOC", Title: "New", Content: "<p>new</p>"}); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "confluence2.storePage", "tok", xmlrpc.Struct{"space": "DOC", "title": "New", "content": "<p>new</p>"})
+
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "confluence2.logout", "tok")
+	if c.Token() != "" {
+		t.Fatal("want no token after logout")
+	}
+}
@@ -0,0 +1,53 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+)
+
+// ContextHandlerFunc is like HandlerFunc but also receives the context of
+// the request, which carries its Peer.
+type ContextHandlerFunc func(ctx context.Context, args ...interface{}) (interface{}, error)
+
+// RegisterContext registers h as the implementation of the method name,
+// replacing any previous registration.
+func (r *Registry) RegisterContext(name string, h ContextHandlerFunc) {
+	r.register(name, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		return h(ctx, args...)
+	})
+}
+
+// Peer describes the client that made a call.
+type Peer struct {
+	RemoteAddr string      // network address, as in http.Request
+	Header     http.Header // headers of the request
+	User       string      // authenticated user, if any; see WithUser
+}
+
+type peerKey struct{}
+
+type userKey struct{}
+
+// PeerFromContext returns the Peer of the call whose context is ctx.
+func PeerFromContext(ctx context.Context) (*Peer, bool) {
+	p, ok := ctx.Value(peerKey{}).(*Peer)
+	return p, ok
+}
+
+// WithUser returns a copy of ctx recording user as the authenticated user,
+// which the server reports in Peer.User. Authentication middleware can
+// use it on the context of the request; RequireBasicAuth does.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// peerContext returns the context of r with its Peer.
+func peerContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	user, _ := ctx.Value(userKey{}).(string)
+	return context.WithValue(ctx, peerKey{}, &Peer{
+		RemoteAddr: r.RemoteAddr,
+		Header:     r.Header,
+		User:       user,
+	})
+}
@@ -0,0 +1,37 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterContext(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("whoami", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, ok := PeerFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no peer")
+		}
+		if p.RemoteAddr == "" {
+			return nil, errors.New("no remote address")
+		}
+		return Struct{"user": p.User, "agent": p.Header.Get("User-Agent")}, nil
+	})
+	h := RequireBasicAuth(s, "test", func(user, password string) bool {
+		return password == "secret"
+	})
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	c := NewClient(strings.Replace(ts.URL, "http://", "http://alice:secret@", 1))
+	v, err := c.Call("whoami")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.(Struct)["user"] != "alice" || v.(Struct)["agent"] == "" {
+		t.Fatalf("unexpected peer: %v", v)
+	}
+}
@@ -0,0 +1,23 @@
+// Package http3 sends XML-RPC calls over HTTP/3, for gateways deployed on
+// HTTP/3 edges. It is a module of its own, so that the QUIC implementation
+// it depends on, quic-go, stays out of the dependencies of go-xmlrpc. The
+// codec is unchanged; only the transport differs.
+//
+// The package is experimental and its API may change.
+package http3
+
+import (
+	"crypto/tls"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// WithHTTP3 makes the client send its requests over HTTP/3 with
+// tlsConfig, or the default TLS configuration if nil. HTTP/3 requires
+// https URLs; there is no fallback to other versions of HTTP. The QUIC
+// connections are closed by CloseIdleConnections of the http.Client of the
+// client.
+func WithHTTP3(tlsConfig *tls.Config) xmlrpc.Option {
+	return xmlrpc.WithTransport(&http3.Transport{TLSClientConfig: tlsConfig})
+}
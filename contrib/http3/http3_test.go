@@ -0,0 +1,51 @@
+package http3
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestWithHTTP3(t *testing.T) {
+	s := xmlrpc.NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	var mu sync.Mutex
+	var proto string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		proto = r.Proto
+		mu.Unlock()
+		s.ServeHTTP(w, r)
+	})
+
+	// Borrow the certificate of httptest for the QUIC listener.
+	ts := httptest.NewTLSServer(handler)
+	defer ts.Close()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no UDP:", err)
+	}
+	srv := &http3.Server{Handler: handler, TLSConfig: http3.ConfigureTLSConfig(ts.TLS.Clone())}
+	go srv.Serve(conn)
+	defer srv.Close()
+
+	tlsConfig := ts.Client().Transport.(*http.Transport).TLSClientConfig
+	c := xmlrpc.NewClient("https://"+conn.LocalAddr().String()+"/", WithHTTP3(tlsConfig))
+	defer c.HttpClient.CloseIdleConnections()
+	v, err := c.Call("echo", "ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if v != "ok" || proto != "HTTP/3.0" {
+		t.Fatalf("want ok over HTTP/3.0 but got %v over %q", v, proto)
+	}
+}
@@ -0,0 +1,1130 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeError is returned when a payload does not have the expected shape.
+type DecodeError struct {
+	Expected string // element that was expected, if any
+	Actual   string // element that was found, if any
+	Path     string // location in the payload, e.g. params[0].value.array.data[2]
+	Offset   int64  // input offset of the decoder when the error occurred
+	Err      error  // underlying error, if any
+}
+
+func (e *DecodeError) Error() string {
+	msg := e.message()
+	if e.Path != "" {
+		msg += " at " + e.Path
+	}
+	return fmt.Sprintf("xmlrpc: %s (offset %d)", msg, e.Offset)
+}
+
+// message describes the error without its location.
+func (e *DecodeError) message() string {
+	var msg string
+	switch {
+	case e.Expected != "" && e.Actual != "":
+		msg = fmt.Sprintf("expected <%s> but got <%s>", e.Expected, e.Actual)
+	case e.Expected != "" && e.Err != nil:
+		msg = fmt.Sprintf("expected <%s>: %v", e.Expected, e.Err)
+	case e.Expected != "":
+		msg = fmt.Sprintf("expected <%s>", e.Expected)
+	case e.Actual != "" && e.Err != nil:
+		msg = fmt.Sprintf("invalid <%s>: %v", e.Actual, e.Err)
+	case e.Actual != "":
+		msg = fmt.Sprintf("unexpected <%s>", e.Actual)
+	case e.Err != nil:
+		msg = e.Err.Error()
+	default:
+		msg = "malformed payload"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeOptions holds the settings that control how payloads are decoded.
+type decodeOptions struct {
+	// unsafeXML allows DOCTYPE declarations, other directives and
+	// processing instructions. They are rejected by default.
+	unsafeXML bool
+
+	// charsetReader converts payloads declaring a charset other than UTF-8.
+	// defaultCharsetReader is used when it is nil.
+	charsetReader CharsetReader
+
+	// entity maps names of non-standard entities to their replacement text.
+	entity map[string]string
+
+	// junkLimit is the number of bytes before the XML declaration or root
+	// element that may be skipped.
+	junkLimit int
+
+	// whitespace controls surrounding whitespace of strings, untyped values
+	// and member names.
+	whitespace Whitespace
+
+	// duplicates controls struct members that occur more than once.
+	duplicates DuplicatePolicy
+
+	// strict records deviations from the XML-RPC specification and fails
+	// decoding with a *SpecError if there are any.
+	strict bool
+
+	// lenient returns values of unknown types and values which can't be
+	// parsed as RawValue and skips unexpected elements in structs and
+	// arrays instead of failing.
+	lenient bool
+
+	// unwrapValues decodes value elements nested in value elements as the
+	// innermost one.
+	unwrapValues bool
+
+	// base64Writer returns the writer the content of the base64 value at
+	// path is streamed to, or nil to decode it as []byte.
+	base64Writer func(path string) io.Writer
+
+	// ints is the Go type of decoded integers.
+	ints IntType
+
+	// limits bounds the size of values.
+	limits DecodeLimits
+
+	// strictScalars rejects elements and comments inside scalar values
+	// instead of ignoring them.
+	strictScalars bool
+
+	// trailing controls content following the root element.
+	trailing     TrailingPolicy
+	trailingHook func(content []byte)
+}
+
+// TrailingPolicy controls how content following the end of the root
+// element of a payload, such as warnings or HTML footers appended by
+// buggy PHP scripts, is handled. Whitespace and comments are always
+// ignored.
+type TrailingPolicy int
+
+const (
+	// TrailingIgnore discards the content. This is the default.
+	TrailingIgnore TrailingPolicy = iota
+	// TrailingHook passes the content to a hook.
+	TrailingHook
+	// TrailingError fails decoding.
+	TrailingError
+)
+
+// maxTrailing is the number of bytes of trailing content that are read.
+const maxTrailing = 64 << 10
+
+// DecodeLimits bounds the values of payloads, so that hostile peers can't
+// make the decoder build huge values. Zero fields mean no limit. Payloads
+// exceeding them fail with a *DecodeError wrapping ErrDecodeLimit.
+//
+// The size of a value is only checked once encoding/xml has read its whole
+// text into memory, so DecodeLimits doesn't bound the memory used for a
+// single huge value. Limit the size of the whole payload for that, with
+// WithMaxResponseSize or WithMaxBodySize.
+type DecodeLimits struct {
+	// MaxStringSize is the number of bytes of the text of a string, base64
+	// or other scalar value, before decoding.
+	MaxStringSize int
+
+	// MaxElements is the number of values of an array or members of a
+	// struct.
+	MaxElements int
+}
+
+// ErrDecodeLimit is wrapped by the errors of payloads exceeding
+// DecodeLimits.
+var ErrDecodeLimit = errors.New("xmlrpc: decode limit exceeded")
+
+// checkString fails if the text of a value of n bytes exceeds the limits.
+func (o *decodeOptions) checkString(n int) error {
+	if o.limits.MaxStringSize > 0 && n > o.limits.MaxStringSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrDecodeLimit, o.limits.MaxStringSize)
+	}
+	return nil
+}
+
+// checkElements fails if n elements of an array or struct exceed the
+// limits.
+func (o *decodeOptions) checkElements(n int) error {
+	if o.limits.MaxElements > 0 && n > o.limits.MaxElements {
+		return fmt.Errorf("%w: more than %d elements", ErrDecodeLimit, o.limits.MaxElements)
+	}
+	return nil
+}
+
+// RawValue is a value the decoder could not interpret, returned by clients
+// created with WithLenient.
+type RawValue struct {
+	Type string // name of the type element, e.g. "dom"
+	XML  string // content of the type element
+}
+
+// DuplicatePolicy controls how a struct member that occurs more than once
+// is decoded.
+type DuplicatePolicy int
+
+const (
+	// DuplicateLastWins keeps the last value. This is the default.
+	DuplicateLastWins DuplicatePolicy = iota
+	// DuplicateFirstWins keeps the first value.
+	DuplicateFirstWins
+	// DuplicateError fails decoding.
+	DuplicateError
+	// DuplicateCollect collects all values into an Array.
+	DuplicateCollect
+)
+
+// Whitespace controls how whitespace surrounding strings is decoded.
+type Whitespace int
+
+const (
+	// PreserveWhitespace returns strings verbatim. This is the default.
+	PreserveWhitespace Whitespace = iota
+	// TrimWhitespace removes leading and trailing whitespace.
+	TrimWhitespace
+)
+
+// IntType is the Go type integers are decoded to.
+type IntType int
+
+const (
+	// IntAsInt decodes all integers, including i8, as int. This is the
+	// default.
+	IntAsInt IntType = iota
+	// IntAsInt32 decodes int and i4 as int32, failing for values out of
+	// its range, and i8 as int64.
+	IntAsInt32
+	// IntAsInt64 decodes all integers as int64.
+	IntAsInt64
+)
+
+// int parses the integer b of the type typ as the IntType.
+func (o *decodeOptions) int(typ string, b []byte) (interface{}, error) {
+	// The conversions of b don't allocate as strconv doesn't keep its
+	// argument.
+	s := string(bytes.TrimSpace(b))
+	switch {
+	case o.ints == IntAsInt64 || o.ints == IntAsInt32 && typ == "i8":
+		return strconv.ParseInt(s, 10, 64)
+	case o.ints == IntAsInt32:
+		n, err := strconv.ParseInt(s, 10, 32)
+		return int32(n), err
+	}
+	return strconv.Atoi(s)
+}
+
+func (o *decodeOptions) string(s string) string {
+	if o.whitespace == TrimWhitespace {
+		return strings.TrimSpace(s)
+	}
+	return s
+}
+
+// decoder reads XML-RPC values from an xml.Decoder while keeping track of
+// where in the payload it is, so errors can point at the offending element.
+type decoder struct {
+	decodeOptions
+	r       *xml.Decoder
+	br      *bufio.Reader
+	path    []pathElem
+	skipped bool
+	started bool
+	open    []xml.Name // elements open, innermost last
+	scratch []byte
+
+	// transcoded is set once the xml.Decoder reads through a
+	// CharsetReader rather than from br.
+	transcoded bool
+
+	// charsetErr is the error of the CharsetReader, if it failed.
+	charsetErr error
+
+	violations []Violation
+}
+
+func newDecoder(r io.Reader, opts decodeOptions) *decoder {
+	return (&decoder{}).reset(r, opts)
+}
+
+// maxScratch is the capacity up to which the scratch buffer of a decoder
+// is kept for reuse.
+const maxScratch = 64 << 10
+
+// reset makes d decode r with opts from the start, keeping its bufio.Reader,
+// scratch buffer and path and element stacks. The xml.Decoder is always a
+// new one, as encoding/xml can't reset a Decoder, whose line numbers and
+// offsets would otherwise carry over from the previous payload.
+func (d *decoder) reset(r io.Reader, opts decodeOptions) *decoder {
+	size := opts.junkLimit + len(utf8BOM)
+	if size < 4096 {
+		size = 4096
+	}
+	br := d.br
+	if br == nil || br.Size() < size {
+		br = bufio.NewReaderSize(r, size)
+	} else {
+		br.Reset(r)
+	}
+	scratch := d.scratch[:0]
+	if cap(scratch) > maxScratch {
+		scratch = nil
+	}
+	*d = decoder{decodeOptions: opts, br: br, path: d.path[:0], open: d.open[:0], scratch: scratch}
+	// The xml.Decoder reads from br directly as it is an io.ByteReader,
+	// which allows base64 values to be streamed from br.
+	d.r = xml.NewDecoder(d.br)
+	d.r.Entity = opts.entity
+	d.r.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		d.transcoded = true
+		fn := opts.charsetReader
+		if fn == nil {
+			fn = defaultCharsetReader
+		}
+		r, err := fn(charset, input)
+		d.charsetErr = err
+		return r, err
+	}
+	return d
+}
+
+// newDecoderCharset returns a decoder of r, which is encoded in charset
+// as declared by the transport. The encoding declared by the payload
+// itself is ignored then.
+func newDecoderCharset(r io.Reader, charset string, opts decodeOptions) (*decoder, error) {
+	fn := opts.charsetReader
+	if fn == nil {
+		fn = defaultCharsetReader
+	}
+	tr, err := fn(charset, r)
+	if err != nil {
+		return nil, err
+	}
+	d := newDecoder(tr, opts)
+	d.r.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return d, nil
+}
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// skipLeading advances br to the start of the XML payload. A UTF-8 byte
+// order mark is always skipped. If limit is positive, up to limit bytes
+// before the XML declaration or the root element, such as PHP warnings
+// printed ahead of the response, are skipped as well.
+func skipLeading(br *bufio.Reader, limit int) {
+	if b, _ := br.Peek(len(utf8BOM)); bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	if limit <= 0 {
+		return
+	}
+	b, _ := br.Peek(limit)
+	i := bytes.Index(b, []byte("<?xml"))
+	if i < 0 {
+		i = bytes.Index(b, []byte("<method"))
+	}
+	if i > 0 {
+		br.Discard(i)
+	}
+}
+
+// pathElem is an element of the path of a decoder, with its index among
+// its siblings unless index is negative. The path is only formatted for
+// errors, so that decoding doesn't allocate for it.
+type pathElem struct {
+	name  string
+	index int
+}
+
+func (d *decoder) push(elem string) {
+	d.path = append(d.path, pathElem{elem, -1})
+}
+
+// pushIndex pushes the i-th element elem, e.g. data[i].
+func (d *decoder) pushIndex(elem string, i int) {
+	d.path = append(d.path, pathElem{elem, i})
+}
+
+func (d *decoder) pop() {
+	d.path = d.path[:len(d.path)-1]
+}
+
+// pathString formats the path like params[0].value.array.data[1].
+func (d *decoder) pathString() string {
+	var b strings.Builder
+	for i, e := range d.path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(e.name)
+		if e.index >= 0 {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(e.index))
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+func (d *decoder) error(expected, actual string, err error) error {
+	if de, ok := err.(*DecodeError); ok {
+		return de
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return &DecodeError{
+		Expected: expected,
+		Actual:   actual,
+		Path:     d.pathString(),
+		Offset:   d.r.InputOffset(),
+		Err:      err,
+	}
+}
+
+// next returns the next token of the payload. Unless unsafeXML is set,
+// directives such as DOCTYPE and processing instructions other than the
+// XML declaration are rejected, which rules out entity expansion attacks.
+func (d *decoder) next() (xml.Token, error) {
+	if !d.skipped {
+		skipLeading(d.br, d.junkLimit)
+		d.skipped = true
+	}
+	// RawToken doesn't copy the token again to translate name spaces,
+	// which XML-RPC doesn't use, but leaves checking that the elements
+	// are nested properly to us.
+	t, err := d.r.RawToken()
+	if err != nil {
+		if err == io.EOF && len(d.open) > 0 {
+			err = d.syntaxError("unexpected EOF")
+		}
+		return nil, err
+	}
+	switch tt := t.(type) {
+	case xml.StartElement:
+		d.started = true
+		d.open = append(d.open, tt.Name)
+	case xml.EndElement:
+		n := len(d.open)
+		if n == 0 {
+			return nil, d.syntaxError("unexpected end element </" + tt.Name.Local + ">")
+		}
+		if d.open[n-1] != tt.Name {
+			return nil, d.syntaxError("element <" + d.open[n-1].Local + "> closed by </" + tt.Name.Local + ">")
+		}
+		d.open = d.open[:n-1]
+	case xml.Directive:
+		if !d.unsafeXML {
+			return nil, d.error("", "", errors.New("directives are not allowed"))
+		}
+	case xml.ProcInst:
+		if !d.unsafeXML && (tt.Target != "xml" || d.started) {
+			return nil, d.error("", "", fmt.Errorf("processing instruction %q is not allowed", tt.Target))
+		}
+	}
+	return t, nil
+}
+
+// syntaxError returns an error like those of xml.Decoder.Token at the
+// current position.
+func (d *decoder) syntaxError(msg string) error {
+	line, _ := d.r.InputPos()
+	return &xml.SyntaxError{Msg: msg, Line: line}
+}
+
+// token returns the next start or end element, skipping character data,
+// comments and processing instructions.
+func (d *decoder) token() (xml.Token, error) {
+	for {
+		t, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement, xml.EndElement:
+			return t, nil
+		}
+	}
+}
+
+// expect reads the next element and checks that it is a start element
+// named name.
+func (d *decoder) expect(name string) (xml.StartElement, error) {
+	t, err := d.token()
+	if err != nil {
+		return xml.StartElement{}, d.error(name, "", err)
+	}
+	switch t := t.(type) {
+	case xml.StartElement:
+		if t.Name.Local != name {
+			return t, d.error(name, t.Name.Local, nil)
+		}
+		return t, nil
+	case xml.EndElement:
+		return xml.StartElement{}, d.error(name, "/"+t.Name.Local, nil)
+	}
+	panic("unreachable")
+}
+
+// expectEnd reads the next element and checks that it is the end element
+// named name.
+func (d *decoder) expectEnd(name string) error {
+	t, err := d.token()
+	if err != nil {
+		return d.error("/"+name, "", err)
+	}
+	switch t := t.(type) {
+	case xml.StartElement:
+		return d.error("/"+name, t.Name.Local, nil)
+	case xml.EndElement:
+		if t.Name.Local != name {
+			return d.error("/"+name, "/"+t.Name.Local, nil)
+		}
+	}
+	return nil
+}
+
+// value decodes the content of a <value> element whose start element has
+// already been read, up to and including the matching end element.
+func (d *decoder) value() (interface{}, error) {
+	var text []byte
+	var comment bool
+	for {
+		t, err := d.next()
+		if err != nil {
+			return nil, d.error("/value", "", err)
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			text = append(text, t...)
+			if err := d.checkString(len(text)); err != nil {
+				return nil, d.error("", "value", err)
+			}
+		case xml.Comment:
+			comment = true
+		case xml.StartElement:
+			v, err := d.typed(t)
+			if err != nil {
+				return nil, err
+			}
+			return v, d.expectEnd("value")
+		case xml.EndElement:
+			// A value without a type element is a string.
+			if comment && d.strictScalars {
+				return nil, d.error("", "value", errors.New("comment in scalar value"))
+			}
+			return d.string(string(text)), nil
+		}
+	}
+}
+
+// typed decodes a type element such as <int> or <struct> whose start
+// element has already been read.
+func (d *decoder) typed(se xml.StartElement) (interface{}, error) {
+	name := se.Name.Local
+	switch name {
+	case "struct":
+		return d.structValue()
+	case "array":
+		return d.arrayValue()
+	case "value":
+		if d.unwrapValues {
+			d.violate("nested <value>")
+			return d.value()
+		}
+		return nil, d.error("", name, nil)
+	case "nil":
+		d.violate("non-standard type <nil>")
+		if _, err := d.text(); err != nil {
+			return nil, d.error("", name, err)
+		}
+		return nil, nil
+	case "string", "boolean", "int", "i1", "i2", "i4", "i8", "double",
+		"dateTime.iso8601", "base64":
+	default:
+		if d.lenient {
+			s, err := d.raw()
+			if err != nil {
+				return nil, d.error("", name, err)
+			}
+			return RawValue{Type: name, XML: s}, nil
+		}
+		return nil, d.error("", name, nil)
+	}
+
+	if name == "base64" && d.base64Writer != nil {
+		if w := d.base64Writer(d.pathString()); w != nil {
+			v, err := d.streamBase64(w)
+			if err != nil {
+				return nil, d.error("", name, err)
+			}
+			return v, nil
+		}
+	}
+
+	b, err := d.textBytes()
+	if err != nil {
+		return nil, d.error("", name, err)
+	}
+	if name == "string" {
+		return d.string(string(b)), nil
+	}
+	v, err := d.scalar(name, b)
+	if err != nil {
+		if d.lenient {
+			return RawValue{Type: name, XML: xmlEscape(string(b))}, nil
+		}
+		return nil, d.error("", name, err)
+	}
+	if d.strict {
+		d.checkScalar(name, string(b))
+	}
+	return v, nil
+}
+
+// text returns the character data of the element whose start element has
+// already been read, up to and including the matching end element.
+func (d *decoder) text() (string, error) {
+	b, err := d.textBytes()
+	return string(b), err
+}
+
+// textBytes is like text but returns the character data in a buffer
+// which is reused by the next call, so that scalars can be parsed without
+// allocating.
+func (d *decoder) textBytes() ([]byte, error) {
+	b := d.scratch[:0]
+	defer func() {
+		d.scratch = b[:0]
+	}()
+	depth := 0
+	for {
+		t, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			if depth == 0 {
+				b = append(b, t...)
+				if err := d.checkString(len(b)); err != nil {
+					return nil, err
+				}
+			}
+		case xml.Comment:
+			if d.strictScalars {
+				return nil, errors.New("comment in scalar value")
+			}
+		case xml.StartElement:
+			if d.strictScalars {
+				return nil, fmt.Errorf("element <%s> in scalar value", t.Name.Local)
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return b, nil
+			}
+			depth--
+		}
+	}
+}
+
+// Base64Writer is the value of a base64 value which has been streamed to W
+// by a client created with WithBase64Writer. N is the number of bytes
+// written.
+type Base64Writer struct {
+	W io.Writer
+	N int64
+}
+
+// streamBase64 decodes the content of the <base64> element whose start
+// element has already been read into w, without holding it in memory.
+func (d *decoder) streamBase64(w io.Writer) (Base64Writer, error) {
+	cw := &countWriter{w: w}
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(cw, base64.NewDecoder(base64.StdEncoding, pr))
+		pr.CloseWithError(err)
+		done <- err
+	}()
+
+	err := d.copyBase64(pw)
+	pw.CloseWithError(err)
+	if derr := <-done; err == nil {
+		err = derr
+	}
+	return Base64Writer{W: w, N: cw.n}, err
+}
+
+// copyBase64 copies the character data of the current element to w,
+// leaving out whitespace.
+func (d *decoder) copyBase64(w io.Writer) error {
+	buf := make([]byte, 0, 4096)
+	flush := func() error {
+		_, err := w.Write(buf)
+		buf = buf[:0]
+		return err
+	}
+	if !d.transcoded {
+		// Read plain character data straight from the input, so that it
+		// doesn't have to be tokenized as a whole. The xml.Decoder picks
+		// up again at the next markup.
+		for {
+			c, err := d.br.ReadByte()
+			if err != nil {
+				return err
+			}
+			if c == '<' || c == '&' {
+				d.br.UnreadByte()
+				break
+			}
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				buf = append(buf, c)
+				if len(buf) == cap(buf) {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	// Whatever remains, such as CDATA sections, goes through the
+	// xml.Decoder.
+	for {
+		t, err := d.next()
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			for _, c := range t {
+				if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+					buf = append(buf, c)
+				}
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+		case xml.StartElement:
+			return fmt.Errorf("unexpected <%s> in base64", t.Name.Local)
+		case xml.EndElement:
+			return flush()
+		}
+	}
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// raw returns the content of the element whose start element has already
+// been read as XML, up to and including the matching end element.
+func (d *decoder) raw() (string, error) {
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+	depth := 0
+	for {
+		t, err := d.next()
+		if err != nil {
+			return "", err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				if err := e.Flush(); err != nil {
+					return "", err
+				}
+				return b.String(), nil
+			}
+			depth--
+		}
+		if err := e.EncodeToken(t); err != nil {
+			return "", err
+		}
+	}
+}
+
+// scalar parses the scalar b of the type typ. Numbers and booleans are
+// parsed in place.
+func (o *decodeOptions) scalar(typ string, b []byte) (interface{}, error) {
+	switch typ {
+	case "string":
+		return string(b), nil
+	case "boolean":
+		switch string(bytes.TrimSpace(b)) {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+		return nil, errors.New("invalid boolean value")
+	case "int", "i1", "i2", "i4", "i8":
+		return o.int(typ, b)
+	case "double":
+		return strconv.ParseFloat(string(bytes.TrimSpace(b)), 64)
+	case "dateTime.iso8601":
+		s := string(b)
+		t, err := time.Parse("20060102T15:04:05", s)
+		if err != nil {
+			t, err = time.Parse("2006-01-02T15:04:05-07:00", s)
+			if err != nil {
+				t, err = time.Parse("2006-01-02T15:04:05", s)
+			}
+		}
+		return t, err
+	case "base64":
+		dst := make([]byte, base64.StdEncoding.DecodedLen(len(b)))
+		n, err := base64.StdEncoding.Decode(dst, b)
+		return dst[:n], err
+	}
+	return nil, fmt.Errorf("unsupported type %s", typ)
+}
+
+func (d *decoder) structValue() (interface{}, error) {
+	d.push("struct")
+	defer d.pop()
+
+	st := Struct{}
+	var collected map[string]bool
+	for i := 0; ; i++ {
+		t, err := d.token()
+		if err != nil {
+			return nil, d.error("/struct", "", err)
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			return st, nil
+		}
+		se := t.(xml.StartElement)
+		if se.Name.Local != "member" {
+			if d.lenient {
+				if _, err := d.raw(); err != nil {
+					return nil, d.error("/"+se.Name.Local, "", err)
+				}
+				i--
+				continue
+			}
+			return nil, d.error("member", se.Name.Local, nil)
+		}
+
+		if err := d.checkElements(i + 1); err != nil {
+			return nil, d.error("", "struct", err)
+		}
+		d.pushIndex("member", i)
+		if _, err = d.expect("name"); err != nil {
+			return nil, err
+		}
+		name, err := d.text()
+		if err != nil {
+			return nil, d.error("", "name", err)
+		}
+		if _, err = d.expect("value"); err != nil {
+			return nil, err
+		}
+		d.push("value")
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		d.pop()
+		if err = d.expectEnd("member"); err != nil {
+			return nil, err
+		}
+		name = d.string(name)
+		if old, ok := st[name]; ok {
+			switch d.duplicates {
+			case DuplicateFirstWins:
+				value = old
+			case DuplicateError:
+				return nil, d.error("", "", fmt.Errorf("duplicate member %q", name))
+			case DuplicateCollect:
+				if collected[name] {
+					value = append(old.(Array), value)
+				} else {
+					if collected == nil {
+						collected = map[string]bool{}
+					}
+					collected[name] = true
+					value = Array{old, value}
+				}
+			}
+		}
+		d.pop()
+		st[name] = value
+	}
+}
+
+func (d *decoder) arrayValue() (interface{}, error) {
+	d.push("array")
+	defer d.pop()
+
+	if _, err := d.expect("data"); err != nil {
+		return nil, err
+	}
+	ar := Array{}
+	for i := 0; ; i++ {
+		t, err := d.token()
+		if err != nil {
+			return nil, d.error("/data", "", err)
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			break
+		}
+		se := t.(xml.StartElement)
+		if se.Name.Local != "value" {
+			if d.lenient {
+				if _, err := d.raw(); err != nil {
+					return nil, d.error("/"+se.Name.Local, "", err)
+				}
+				i--
+				continue
+			}
+			return nil, d.error("value", se.Name.Local, nil)
+		}
+		if err := d.checkElements(i + 1); err != nil {
+			return nil, d.error("", "array", err)
+		}
+		d.pushIndex("data", i)
+		d.push("value")
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		d.pop()
+		d.pop()
+		ar = append(ar, value)
+	}
+	return ar, d.expectEnd("array")
+}
+
+// response decodes a methodResponse envelope and returns the value of its
+// first param.
+func (d *decoder) response() (interface{}, error) {
+	max := 1
+	if d.strict {
+		max = -1
+	}
+	params, err := d.params(max)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) == 0 {
+		return nil, d.error("param", "/params", nil)
+	}
+	if len(params) > 1 {
+		d.violate("response has %d params instead of one", len(params))
+	}
+	if err := d.specError(); err != nil {
+		return nil, err
+	}
+	return params[0], nil
+}
+
+// params decodes a methodResponse envelope and returns the values of up to
+// max params, or of all params if max is negative. A fault response is
+// returned as a *Fault error.
+func (d *decoder) params(max int) (Array, error) {
+	if _, err := d.expect("methodResponse"); err != nil {
+		return nil, err
+	}
+	t, err := d.token()
+	if err != nil {
+		return nil, d.error("params", "", err)
+	}
+	se, ok := t.(xml.StartElement)
+	if ok && se.Name.Local == "fault" {
+		err := d.fault()
+		if f, ok := err.(*Fault); ok && len(d.violations) > 0 {
+			return nil, &SpecError{Violations: d.violations, Fault: f}
+		}
+		return nil, err
+	}
+	if !ok || se.Name.Local != "params" {
+		return nil, d.error("params", tokenName(t), nil)
+	}
+	return d.paramList(max)
+}
+
+// paramList decodes the content of a <params> element whose start element
+// has already been read and returns the values of up to max params, or of
+// all params if max is negative.
+func (d *decoder) paramList(max int) (Array, error) {
+	params := Array{}
+	for i := 0; i != max; i++ {
+		t, err := d.token()
+		if err != nil {
+			return nil, d.error("/params", "", err)
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			break
+		}
+		if se := t.(xml.StartElement); se.Name.Local != "param" {
+			return nil, d.error("param", se.Name.Local, nil)
+		}
+		d.pushIndex("params", i)
+		if _, err := d.expect("value"); err != nil {
+			return nil, err
+		}
+		d.push("value")
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		d.pop()
+		if err := d.expectEnd("param"); err != nil {
+			return nil, err
+		}
+		d.pop()
+		params = append(params, v)
+	}
+	return params, nil
+}
+
+// checkTrailing skips the rest of the root element and handles the
+// content following it according to the TrailingPolicy.
+func (d *decoder) checkTrailing() error {
+	if d.trailing == TrailingIgnore {
+		return nil
+	}
+	for len(d.open) > 0 {
+		if _, err := d.next(); err != nil {
+			return d.error("", "", err)
+		}
+	}
+	content, err := d.rest()
+	if err != nil {
+		return d.error("", "", err)
+	}
+	if len(content) == 0 {
+		return nil
+	}
+	if d.trailing == TrailingError {
+		return d.error("", "", fmt.Errorf("trailing content %q", content))
+	}
+	if d.trailingHook != nil {
+		d.trailingHook(content)
+	}
+	return nil
+}
+
+// rest returns up to maxTrailing bytes of the payload following the root
+// element with surrounding whitespace and comments removed.
+func (d *decoder) rest() ([]byte, error) {
+	var b bytes.Buffer
+	if !d.transcoded {
+		// The xml.Decoder reads from br directly, so what it hasn't read
+		// is left in br.
+		if _, err := io.Copy(&b, io.LimitReader(d.br, maxTrailing)); err != nil {
+			return nil, err
+		}
+	} else {
+		// The charset reader may hold some of the input, so take the
+		// tokens instead.
+		for b.Len() < maxTrailing {
+			t, err := d.r.RawToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			switch t := t.(type) {
+			case xml.CharData:
+				b.Write(t)
+			case xml.StartElement:
+				b.WriteString("<" + t.Name.Local + ">")
+			case xml.EndElement:
+				b.WriteString("</" + t.Name.Local + ">")
+			}
+		}
+	}
+	content := bytes.TrimSpace(b.Bytes())
+	for bytes.HasPrefix(content, []byte("<!--")) {
+		i := bytes.Index(content, []byte("-->"))
+		if i < 0 {
+			break
+		}
+		content = bytes.TrimSpace(content[i+3:])
+	}
+	return content, nil
+}
+
+// call decodes a methodCall envelope and returns the method name and the
+// arguments.
+func (d *decoder) call() (string, []interface{}, error) {
+	if _, err := d.expect("methodCall"); err != nil {
+		return "", nil, err
+	}
+	if _, err := d.expect("methodName"); err != nil {
+		return "", nil, err
+	}
+	name, err := d.text()
+	if err != nil {
+		return "", nil, d.error("", "methodName", err)
+	}
+	name = strings.TrimSpace(name)
+	d.checkMethodName(name)
+	t, err := d.token()
+	if err != nil {
+		return "", nil, d.error("params", "", err)
+	}
+	switch t := t.(type) {
+	case xml.EndElement:
+		// The params element may be omitted if there are no arguments.
+		return name, nil, nil
+	case xml.StartElement:
+		if t.Name.Local != "params" {
+			return "", nil, d.error("params", t.Name.Local, nil)
+		}
+	}
+	params, err := d.paramList(-1)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := d.expectEnd("methodCall"); err != nil {
+		return "", nil, err
+	}
+	if err := d.specError(); err != nil {
+		return "", nil, err
+	}
+	return name, params, nil
+}
+
+// tokenName returns the name of the element t starts or ends.
+func tokenName(t xml.Token) string {
+	switch t := t.(type) {
+	case xml.StartElement:
+		return t.Name.Local
+	case xml.EndElement:
+		return "/" + t.Name.Local
+	}
+	return ""
+}
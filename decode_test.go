@@ -0,0 +1,501 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNested(t *testing.T) {
+	v, err := newDecoder(strings.NewReader(`
+<?xml version="1.0"?>
+<methodResponse>
+  <params>
+    <param>
+      <value>
+        <struct>
+          <member>
+            <name>items</name>
+            <value>
+              <array>
+                <data>
+                  <value><array><data><value><int>1</int></value></data></array></value>
+                  <value>untyped</value>
+                </data>
+              </array>
+            </value>
+          </member>
+          <member>
+            <name>ok</name>
+            <value><boolean>1</boolean></value>
+          </member>
+        </struct>
+      </value>
+    </param>
+  </params>
+</methodResponse>
+`), decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, ok := v.(Struct)
+	if !ok {
+		t.Fatalf("want Struct but got %T: %v", v, v)
+	}
+	items, ok := st["items"].(Array)
+	if !ok || len(items) != 2 {
+		t.Fatalf("want array with 2 entries but got %v", st["items"])
+	}
+	if inner, ok := items[0].(Array); !ok || len(inner) != 1 || inner[0] != 1 {
+		t.Fatalf("want nested array [1] but got %v", items[0])
+	}
+	if items[1] != "untyped" {
+		t.Fatalf("want %q but got %v", "untyped", items[1])
+	}
+	if st["ok"] != true {
+		t.Fatalf("want true but got %v", st["ok"])
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	_, err := newDecoder(strings.NewReader(`
+<methodResponse><params><param><value><array><data>
+<value><int>1</int></value>
+<value><int>2</int></value>
+<value><struct><member><value><int>3</int></value></member></struct></value>
+</data></array></value></param></params></methodResponse>
+`), decodeOptions{}).response()
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("want *DecodeError but got %T: %v", err, err)
+	}
+	if de.Expected != "name" || de.Actual != "value" {
+		t.Fatalf("want expected name, actual value but got %q, %q", de.Expected, de.Actual)
+	}
+	want := "params[0].value.array.data[2].value.struct.member[0]"
+	if de.Path != want {
+		t.Fatalf("want path %q but got %q", want, de.Path)
+	}
+	if de.Offset == 0 {
+		t.Fatal("want non-zero offset")
+	}
+}
+
+func TestDecodeErrorInvalidScalar(t *testing.T) {
+	_, err := newDecoder(strings.NewReader(`
+<methodResponse><params><param><value><int>abc</int></value></param></params></methodResponse>
+`), decodeOptions{}).response()
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("want *DecodeError but got %T: %v", err, err)
+	}
+	if de.Actual != "int" || de.Err == nil {
+		t.Fatalf("want invalid int error but got %v", err)
+	}
+}
+
+func TestDecodeRejectsDirectives(t *testing.T) {
+	payloads := []string{
+		`<?xml version="1.0"?><!DOCTYPE foo [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><methodResponse><params><param><value><string>x</string></value></param></params></methodResponse>`,
+		`<?xml version="1.0"?><methodResponse><params><param><value><?php echo 1 ?><string>x</string></value></param></params></methodResponse>`,
+	}
+	for _, payload := range payloads {
+		_, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response()
+		if err == nil {
+			t.Fatalf("want error for %s", payload)
+		}
+		v, err := newDecoder(strings.NewReader(payload), decodeOptions{unsafeXML: true}).response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "x" {
+			t.Fatalf("want %q but got %v", "x", v)
+		}
+	}
+}
+
+func TestDecodeEntityMap(t *testing.T) {
+	payload := `<methodResponse><params><param><value><string>a&nbsp;b&copy;</string></value></param></params></methodResponse>`
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err == nil {
+		t.Fatal("want error for unknown entity")
+	}
+	v, err := newDecoder(strings.NewReader(payload), decodeOptions{entity: xml.HTMLEntity}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "a\u00a0b\u00a9" {
+		t.Fatalf("want %q but got %q", "a\u00a0b\u00a9", v)
+	}
+}
+
+func TestDecodeLeadingJunk(t *testing.T) {
+	payload := "\xef\xbb\xbf<br />\n<b>Warning</b>: Undefined variable $x\n" +
+		`<?xml version="1.0"?><methodResponse><params><param><value><int>1</int></value></param></params></methodResponse>`
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err == nil {
+		t.Fatal("want error for leading junk")
+	}
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{junkLimit: 10}).response(); err == nil {
+		t.Fatal("want error for leading junk beyond the limit")
+	}
+	v, err := newDecoder(strings.NewReader(payload), decodeOptions{junkLimit: 1024}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 {
+		t.Fatalf("want 1 but got %v", v)
+	}
+}
+
+func TestDecodeBOM(t *testing.T) {
+	payload := "\xef\xbb\xbf" + `<?xml version="1.0"?><methodResponse><params><param><value><int>1</int></value></param></params></methodResponse>`
+	v, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 {
+		t.Fatalf("want 1 but got %v", v)
+	}
+}
+
+func TestDecodeWhitespace(t *testing.T) {
+	payload := `<methodResponse><params><param><value><struct>
+  <member><name> key </name><value>
+    untyped
+  </value></member>
+  <member><name>typed</name><value><string>  x  </string></value></member>
+</struct></value></param></params></methodResponse>`
+
+	v, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := v.(Struct)
+	if st[" key "] != "\n    untyped\n  " || st["typed"] != "  x  " {
+		t.Fatalf("want whitespace preserved but got %q", st)
+	}
+
+	v, err = newDecoder(strings.NewReader(payload), decodeOptions{whitespace: TrimWhitespace}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st = v.(Struct)
+	if st["key"] != "untyped" || st["typed"] != "x" {
+		t.Fatalf("want whitespace trimmed but got %q", st)
+	}
+}
+
+func TestDecodeDuplicateMembers(t *testing.T) {
+	payload := `<methodResponse><params><param><value><struct>
+<member><name>tag</name><value><string>a</string></value></member>
+<member><name>tag</name><value><string>b</string></value></member>
+<member><name>tag</name><value><string>c</string></value></member>
+</struct></value></param></params></methodResponse>`
+
+	tests := []struct {
+		policy DuplicatePolicy
+		want   interface{}
+	}{
+		{DuplicateLastWins, "c"},
+		{DuplicateFirstWins, "a"},
+		{DuplicateCollect, Array{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		v, err := newDecoder(strings.NewReader(payload), decodeOptions{duplicates: tt.policy}).response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.(Struct)["tag"]; fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Fatalf("policy %d: want %v but got %v", tt.policy, tt.want, got)
+		}
+	}
+
+	_, err := newDecoder(strings.NewReader(payload), decodeOptions{duplicates: DuplicateError}).response()
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("want *DecodeError but got %T: %v", err, err)
+	}
+	if de.Path != "params[0].value.struct.member[1]" {
+		t.Fatalf("want error at second member but got %v", de.Path)
+	}
+}
+
+func TestDecodeLenient(t *testing.T) {
+	payload := `<methodResponse><params><param><value><struct>
+<member><name>a</name><value><ex:dom xmlns:ex="http://ws.apache.org/xmlrpc/namespaces/extensions"><p>x</p></ex:dom></value></member>
+<member><name>b</name><value><int>abc</int></value></member>
+<junk>1</junk>
+<member><name>c</name><value><array><data><junk/><value><int>3</int></value></data></array></value></member>
+</struct></value></param></params></methodResponse>`
+
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err == nil {
+		t.Fatal("want error")
+	}
+	v, err := newDecoder(strings.NewReader(payload), decodeOptions{lenient: true}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := v.(Struct)
+	if raw, ok := st["a"].(RawValue); !ok || raw.Type != "dom" || !strings.Contains(raw.XML, ">x</p>") {
+		t.Fatalf("want RawValue for dom but got %#v", st["a"])
+	}
+	if raw, ok := st["b"].(RawValue); !ok || raw.Type != "int" || raw.XML != "abc" {
+		t.Fatalf("want RawValue for int but got %#v", st["b"])
+	}
+	if ar, ok := st["c"].(Array); !ok || len(ar) != 1 || ar[0] != 3 {
+		t.Fatalf("want [3] but got %#v", st["c"])
+	}
+}
+
+func TestDecodeUnwrapValues(t *testing.T) {
+	payload := `<methodResponse><params><param><value><value><struct>
+<member><name>a</name><value><value><value><string>x</string></value></value></value></member>
+<member><name>b</name><value>
+	<value>untyped</value>
+</value></member>
+<member><name>c</name><value><array><data><value><value><int>3</int></value></value></data></array></value></member>
+</struct></value></value></param></params></methodResponse>`
+
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err == nil {
+		t.Fatal("want error")
+	}
+	c := NewClient("http://localhost/", WithUnwrapNestedValues())
+	v, err := newDecoder(strings.NewReader(payload), c.dec).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Struct{"a": "x", "b": "untyped", "c": Array{3}}); !Equal(v, want) {
+		t.Fatalf("want %v but got %v", want, v)
+	}
+	var se *SpecError
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{unwrapValues: true, strict: true}).response(); !errors.As(err, &se) {
+		t.Fatalf("want *SpecError but got %v", err)
+	}
+}
+
+func TestDecodeBase64Writer(t *testing.T) {
+	data := strings.Repeat("streamed base64 content ", 1000)
+	enc := base64.StdEncoding.EncodeToString([]byte(data))
+	wrapped := ""
+	for len(enc) > 76 {
+		wrapped += enc[:76] + "\n"
+		enc = enc[76:]
+	}
+	wrapped += enc
+
+	for _, payload := range []string{
+		`<methodResponse><params><param><value><struct><member><name>bits</name><value><base64>` + wrapped + `</base64></value></member><member><name>small</name><value><base64>YQ==</base64></value></member></struct></value></param></params></methodResponse>`,
+		`<?xml version="1.0" encoding="ISO-8859-1"?><methodResponse><params><param><value><struct><member><name>bits</name><value><base64><![CDATA[` + wrapped + `]]></base64></value></member><member><name>small</name><value><base64>YQ==</base64></value></member></struct></value></param></params></methodResponse>`,
+	} {
+		var buf bytes.Buffer
+		opts := decodeOptions{base64Writer: func(path string) io.Writer {
+			if path == "params[0].value.struct.member[0].value" {
+				return &buf
+			}
+			return nil
+		}}
+		v, err := newDecoder(strings.NewReader(payload), opts).response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		st := v.(Struct)
+		bw, ok := st["bits"].(Base64Writer)
+		if !ok {
+			t.Fatalf("want Base64Writer but got %T", st["bits"])
+		}
+		if bw.N != int64(len(data)) || buf.String() != data {
+			t.Fatalf("want %d bytes streamed but got %d", len(data), bw.N)
+		}
+		if b, ok := st["small"].([]byte); !ok || string(b) != "a" {
+			t.Fatalf("want []byte(\"a\") but got %#v", st["small"])
+		}
+	}
+}
+
+func TestDecodeIntType(t *testing.T) {
+	payload := `<methodResponse><params><param><value><array><data>
+<value><int>1</int></value><value><i4>-2</i4></value><value><i8>3000000000</i8></value>
+</data></array></value></param></params></methodResponse>`
+
+	for ints, want := range map[IntType]Array{
+		IntAsInt:   {1, -2, 3000000000},
+		IntAsInt32: {int32(1), int32(-2), int64(3000000000)},
+		IntAsInt64: {int64(1), int64(-2), int64(3000000000)},
+	} {
+		v, err := newDecoder(strings.NewReader(payload), decodeOptions{ints: ints}).response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Fatalf("want %#v for IntType %d but got %#v", want, ints, v)
+		}
+		var n []int64
+		if err := Unmarshal(v, &n); err != nil || n[2] != 3000000000 {
+			t.Fatalf("want integers unmarshaled but got %v, %v", n, err)
+		}
+	}
+
+	payload = `<methodResponse><params><param><value><int>3000000000</int></value></param></params></methodResponse>`
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{ints: IntAsInt32}).response(); err == nil {
+		t.Fatal("want int out of range of int32 rejected")
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	limits := DecodeLimits{MaxStringSize: 8, MaxElements: 2}
+	for _, value := range []string{
+		`<string>123456789</string>`,
+		`123456789`,
+		`<base64>MTIzNDU2Nzg5</base64>`,
+		`<array><data><value>1</value><value>2</value><value>3</value></data></array>`,
+		`<struct><member><name>a</name><value>1</value></member><member><name>b</name><value>2</value></member><member><name>c</name><value>3</value></member></struct>`,
+	} {
+		payload := `<methodResponse><params><param><value>` + value + `</value></param></params></methodResponse>`
+		_, err := newDecoder(strings.NewReader(payload), decodeOptions{limits: limits}).response()
+		var de *DecodeError
+		if !errors.Is(err, ErrDecodeLimit) || !errors.As(err, &de) {
+			t.Fatalf("want limit exceeded for %s but got %v", value, err)
+		}
+		if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err != nil {
+			t.Fatalf("want %s decoded without limits but got %v", value, err)
+		}
+	}
+
+	payload := `<methodResponse><params><param><value><array><data><value>12345678</value><value><string>x</string></value></data></array></value></param></params></methodResponse>`
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{limits: limits}).response(); err != nil {
+		t.Fatalf("want values within limits decoded but got %v", err)
+	}
+}
+
+func TestDecodeMismatchedElements(t *testing.T) {
+	for _, payload := range []string{
+		`<methodResponse><params><param><value><string>a</value></string></param></params></methodResponse>`,
+		`<methodResponse><params><param><value><string><b>a</c></string></value></param></params></methodResponse>`,
+		`<methodResponse><params><param><value><array><data><value>1</value></array></data></value></param></params></methodResponse>`,
+		`<methodResponse><params><param><value><struct><member><name>a</name><value>1</value>`,
+	} {
+		_, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response()
+		var se *xml.SyntaxError
+		if !errors.As(err, &se) {
+			t.Fatalf("want syntax error for %s but got %v", payload, err)
+		}
+	}
+}
+
+func TestDecodeStrictScalars(t *testing.T) {
+	for _, value := range []string{
+		`<string>a<b/>c</string>`,
+		`<int>1<!-- one --></int>`,
+		`a<!-- b -->c`,
+	} {
+		payload := `<methodResponse><params><param><value>` + value + `</value></param></params></methodResponse>`
+		if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err != nil {
+			t.Fatalf("want %s decoded by default but got %v", value, err)
+		}
+		if _, err := newDecoder(strings.NewReader(payload), decodeOptions{strictScalars: true}).response(); err == nil {
+			t.Fatalf("want %s rejected", value)
+		}
+	}
+
+	payload := `<methodResponse><params><param><value><!-- answer --><int>42</int></value></param></params></methodResponse>`
+	if v, err := newDecoder(strings.NewReader(payload), decodeOptions{strictScalars: true}).response(); err != nil || v != 42 {
+		t.Fatalf("want comment outside scalar allowed but got %v, %v", v, err)
+	}
+}
+
+func TestDecodeTrailing(t *testing.T) {
+	const response = `<methodResponse><params><param><value><array><data><value>1</value></data></array></value></param></params></methodResponse>`
+	const warning = "<br />\n<b>Warning</b>: Cannot modify header information"
+
+	for _, payload := range []string{response, response + "\n<!-- cached -->\n", response + "\n" + warning + "\n"} {
+		d := newDecoder(strings.NewReader(payload), decodeOptions{})
+		if _, err := d.response(); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.checkTrailing(); err != nil {
+			t.Fatalf("want trailing content ignored by default but got %v", err)
+		}
+	}
+
+	for _, payload := range []string{response, response + "\n<!-- cached -->\n"} {
+		d := newDecoder(strings.NewReader(payload), decodeOptions{trailing: TrailingError})
+		if _, err := d.response(); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.checkTrailing(); err != nil {
+			t.Fatalf("want whitespace and comments allowed but got %v", err)
+		}
+	}
+
+	var got []byte
+	d := newDecoder(strings.NewReader(response+"\n"+warning+"\n"), decodeOptions{trailing: TrailingHook, trailingHook: func(b []byte) { got = b }})
+	if _, err := d.response(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.checkTrailing(); err != nil || string(got) != warning {
+		t.Fatalf("want hook called with %q but got %q, %v", warning, got, err)
+	}
+
+	d = newDecoder(strings.NewReader(response+warning), decodeOptions{trailing: TrailingError})
+	if _, err := d.response(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.checkTrailing(); err == nil {
+		t.Fatal("want trailing content rejected")
+	}
+}
+
+func benchmarkDecodeArray(b *testing.B, value func(i int) string) {
+	var buf bytes.Buffer
+	buf.WriteString(`<methodResponse><params><param><value><array><data>`)
+	for i := 0; i < 10000; i++ {
+		buf.WriteString("<value>" + value(i) + "</value>")
+	}
+	buf.WriteString(`</data></array></value></param></params></methodResponse>`)
+	payload := buf.Bytes()
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := newDecoder(bytes.NewReader(payload), decodeOptions{}).response(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeInts(b *testing.B) {
+	benchmarkDecodeArray(b, func(i int) string {
+		return fmt.Sprintf("<i4>%d</i4>", i*104729)
+	})
+}
+
+func BenchmarkDecodeDoubles(b *testing.B) {
+	benchmarkDecodeArray(b, func(i int) string {
+		return fmt.Sprintf("<double>%v</double>", float64(i)*1.000001)
+	})
+}
+
+func BenchmarkDecodeStrings(b *testing.B) {
+	benchmarkDecodeArray(b, func(i int) string {
+		return fmt.Sprintf("<string>item %d</string>", i)
+	})
+}
+
+func TestDecodeScalarInPlace(t *testing.T) {
+	var o decodeOptions
+	for typ, b := range map[string][]byte{"i4": []byte(" 42 "), "boolean": []byte("1")} {
+		if n := testing.AllocsPerRun(100, func() {
+			if _, err := o.scalar(typ, b); err != nil {
+				t.Fatal(err)
+			}
+		}); n != 0 {
+			t.Fatalf("want %s parsed without allocating but got %v allocations", typ, n)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package xmlrpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// WithDialContext makes the client open connections with dial, e.g. to
+// go through a jump host or to reach a test listener. It applies to
+// transports which are *http.Transport, such as the default one and those
+// of WithHTTP2, which are copied first.
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(c *Client) {
+		c.setTransport(func(t *http.Transport) {
+			t.DialContext = dial
+		})
+	}
+}
+
+// WithResolveTo makes the client connect to addr in place of host, like
+// curl --resolve, keeping host for TLS and the Host header. host may have
+// a port, to only match it, and addr may lack one, to keep that of the
+// request. It applies like WithDialContext, on top of its dialer.
+func WithResolveTo(host, addr string) Option {
+	return func(c *Client) {
+		c.setTransport(func(t *http.Transport) {
+			dial := t.DialContext
+			if dial == nil {
+				dial = (&net.Dialer{}).DialContext
+			}
+			t.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+				h, port, err := net.SplitHostPort(address)
+				if err == nil && (h == host || address == host) {
+					address = addr
+					if _, _, err := net.SplitHostPort(addr); err != nil {
+						address = net.JoinHostPort(addr, port)
+					}
+				}
+				return dial(ctx, network, address)
+			}
+		})
+	}
+}
+
+// setTransport applies fn to copies of the transports of the client
+// which are *http.Transport, set on a copy of its http.Client.
+func (c *Client) setTransport(fn func(*http.Transport)) {
+	set := func(rt http.RoundTripper) http.RoundTripper {
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			return rt
+		}
+		t = t.Clone()
+		fn(t)
+		return t
+	}
+	hc := *c.HttpClient
+	switch rt := hc.Transport.(type) {
+	case *fallbackTransport:
+		hc.Transport = &fallbackTransport{h2: set(rt.h2), h1: set(rt.h1), hosts: map[string]bool{}}
+	case nil:
+		hc.Transport = set(http.DefaultTransport)
+	default:
+		hc.Transport = set(rt)
+	}
+	c.HttpClient = &hc
+}
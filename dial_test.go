@@ -0,0 +1,57 @@
+package xmlrpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestWithResolveTo(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+
+	for _, tt := range []struct{ host, addr string }{
+		{"xmlrpc.example", u.Host},
+		{"xmlrpc.example:" + u.Port(), u.Host},
+		{"xmlrpc.example", u.Hostname()},
+	} {
+		c := NewClient("http://xmlrpc.example:"+u.Port()+"/", WithResolveTo(tt.host, tt.addr))
+		if _, err := c.Call("system.listMethods"); err != nil {
+			t.Fatalf("%s=%s: %v", tt.host, tt.addr, err)
+		}
+	}
+	c := NewClient("http://xmlrpc.example:"+u.Port()+"/", WithResolveTo("other.example", u.Host), WithHTTP2(true))
+	if _, err := c.Call("system.listMethods"); err == nil {
+		t.Fatal("want unresolved host to fail")
+	}
+}
+
+func TestWithDialContext(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+
+	var dialed []string
+	var d net.Dialer
+	c := NewClient("http://jump.example/", WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return d.DialContext(ctx, network, u.Host)
+	}), WithResolveTo("jump.example", "10.0.0.1"))
+	if _, err := c.Call("system.listMethods"); err != nil {
+		t.Fatal(err)
+	}
+	if len(dialed) != 1 || dialed[0] != "10.0.0.1:80" {
+		t.Fatalf("want dial of 10.0.0.1:80 but got %v", dialed)
+	}
+
+	hc := &http.Client{Transport: http.DefaultTransport}
+	c.SetHTTPClient(hc)
+	WithResolveTo("jump.example", "10.0.0.2")(c)
+	if hc.Transport != http.DefaultTransport {
+		t.Fatal("want http.Client of the caller unchanged")
+	}
+}
@@ -0,0 +1,87 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dump writes an indented rendering of v, annotated with the XML-RPC types
+// of the values, to w. Members of structs are written in the order of their
+// names.
+func Dump(w io.Writer, v interface{}) error {
+	ew := &errWriter{w: w}
+	dump(ew, v, "")
+	ew.WriteString("\n")
+	return ew.err
+}
+
+// String returns the rendering of s written by Dump.
+func (s Struct) String() string {
+	var b bytes.Buffer
+	dump(&errWriter{w: &b}, s, "")
+	return b.String()
+}
+
+// String returns the rendering of a written by Dump.
+func (a Array) String() string {
+	var b bytes.Buffer
+	dump(&errWriter{w: &b}, a, "")
+	return b.String()
+}
+
+func dump(w *errWriter, v interface{}, indent string) {
+	switch v := v.(type) {
+	case nil:
+		w.WriteString("nil")
+	case Struct:
+		if len(v) == 0 {
+			w.WriteString("struct {}")
+			return
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		w.WriteString("struct {\n")
+		for _, name := range names {
+			w.WriteString(indent + "  " + name + ": ")
+			dump(w, v[name], indent+"  ")
+			w.WriteString("\n")
+		}
+		w.WriteString(indent + "}")
+	case Array:
+		if len(v) == 0 {
+			w.WriteString("array []")
+			return
+		}
+		w.WriteString("array [\n")
+		for i, e := range v {
+			w.WriteString(fmt.Sprintf("%s  %d: ", indent, i))
+			dump(w, e, indent+"  ")
+			w.WriteString("\n")
+		}
+		w.WriteString(indent + "]")
+	case string:
+		w.WriteString(fmt.Sprintf("string %q", v))
+	case int, int32, int64:
+		w.WriteString(fmt.Sprintf("int %d", v))
+	case float64:
+		w.WriteString(fmt.Sprintf("double %v", v))
+	case bool:
+		w.WriteString(fmt.Sprintf("boolean %v", v))
+	case time.Time:
+		w.WriteString("dateTime.iso8601 " + v.Format("20060102T15:04:05"))
+	case []byte:
+		w.WriteString(fmt.Sprintf("base64 (%d bytes) %s", len(v), base64.StdEncoding.EncodeToString(v)))
+	case RawValue:
+		w.WriteString(v.Type + " " + strings.TrimSpace(v.XML))
+	default:
+		w.WriteString(fmt.Sprintf("%T %v", v, v))
+	}
+}
@@ -0,0 +1,48 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDumpValue(t *testing.T) {
+	v := Struct{
+		"title": "hello",
+		"tags":  Array{"go", 1, Struct{}},
+		"data":  []byte("hello"),
+		"date":  time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC),
+		"score": 1.5,
+		"draft": false,
+		"none":  nil,
+		"empty": Array{},
+	}
+	want := `struct {
+  data: base64 (5 bytes) aGVsbG8=
+  date: dateTime.iso8601 19980717T14:08:55
+  draft: boolean false
+  empty: array []
+  none: nil
+  score: double 1.5
+  tags: array [
+    0: string "go"
+    1: int 1
+    2: struct {}
+  ]
+  title: string "hello"
+}`
+	var b bytes.Buffer
+	if err := Dump(&b, v); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want+"\n" {
+		t.Fatalf("want\n%s\nbut got\n%s", want, b.String())
+	}
+	if s := fmt.Sprint(v); s != want {
+		t.Fatalf("want String() to match Dump but got\n%s", s)
+	}
+	if s := fmt.Sprint(Array{1}); s != "array [\n  0: int 1\n]" {
+		t.Fatalf("unexpected Array.String(): %q", s)
+	}
+}
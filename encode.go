@@ -0,0 +1,556 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var xmlSpecial = map[byte]string{
+	'<':  "&lt;",
+	'>':  "&gt;",
+	'"':  "&quot;",
+	'\'': "&apos;",
+	'&':  "&amp;",
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if s, ok := xmlSpecial[c]; ok {
+			b.WriteString(s)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// encoder holds the settings that control how values are encoded.
+type encoder struct {
+	// cdata is the number of markup characters from which on strings are
+	// wrapped in a CDATA section instead of being escaped. Zero disables it.
+	cdata int
+
+	// omitEmptyParams leaves out the params element of calls without
+	// arguments.
+	omitEmptyParams bool
+
+	// anyMethodName disables the validation of method names.
+	anyMethodName bool
+
+	// indent, if not empty, makes requests indented by it per level.
+	indent string
+
+	// ascii escapes non-ASCII characters as numeric character references.
+	ascii bool
+
+	// i8 sends integers outside the range of i4 as i8.
+	i8 bool
+
+	// sortKeys writes the members of maps in the order of their names, for
+	// canonical requests.
+	sortKeys bool
+
+	// uint64 controls how unsigned integers beyond the range of i8 are
+	// encoded.
+	uint64 Uint64Policy
+
+	// invalidChars controls characters which XML doesn't allow.
+	invalidChars InvalidCharPolicy
+
+	// encoding is declared by the XML declaration if not empty.
+	encoding string
+
+	// noDeclaration leaves out the XML declaration.
+	noDeclaration bool
+}
+
+// InvalidCharPolicy controls how characters outside of the character range
+// of XML 1.0, such as most control characters, and invalid UTF-8 in strings
+// and member names are encoded.
+type InvalidCharPolicy int
+
+const (
+	// InvalidCharKeep writes them as they are, which makes the payload
+	// ill-formed. This is the default.
+	InvalidCharKeep InvalidCharPolicy = iota
+	// InvalidCharStrip leaves them out.
+	InvalidCharStrip
+	// InvalidCharReplace replaces them with U+FFFD.
+	InvalidCharReplace
+	// InvalidCharError fails encoding.
+	InvalidCharError
+)
+
+// Uint64Policy controls how unsigned integers greater than math.MaxInt64,
+// which don't fit in an i8, are encoded.
+type Uint64Policy int
+
+const (
+	// Uint64Error fails encoding. This is the default.
+	Uint64Error Uint64Policy = iota
+	// Uint64Clamp encodes them as math.MaxInt64.
+	Uint64Clamp
+	// Uint64AsI8 encodes them as i8 holding the same 64 bits, that is as
+	// negative numbers, as peers with unsigned 64-bit types expect.
+	Uint64AsI8
+	// Uint64String encodes them as decimal strings.
+	Uint64String
+)
+
+// Base64Reader is an argument which is sent as base64 encoded data read from
+// R. Its content is streamed into the request body rather than held in
+// memory, which makes it suitable for uploading large files.
+type Base64Reader struct {
+	R io.Reader
+}
+
+// errWriter remembers the first error returned by the underlying writer
+// and discards all writes after it.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	var n int
+	n, w.err = w.w.Write(p)
+	return n, w.err
+}
+
+func (w *errWriter) WriteString(s string) {
+	if w.err == nil {
+		_, w.err = io.WriteString(w.w, s)
+	}
+}
+
+func (e *encoder) toXml(v interface{}, typ bool) string {
+	var b bytes.Buffer
+	e.write(&errWriter{w: &b}, v, typ)
+	return b.String()
+}
+
+func (e *encoder) write(w *errWriter, v interface{}, typ bool) {
+	if v == nil {
+		w.WriteString("<nil/>")
+		return
+	}
+	r := reflect.ValueOf(v)
+	t := r.Type()
+	k := t.Kind()
+
+	switch v := v.(type) {
+	case []byte:
+		w.WriteString("<base64>" + base64.StdEncoding.EncodeToString(v) + "</base64>")
+		return
+	case Base64Reader:
+		w.WriteString("<base64>")
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		if _, err := io.Copy(enc, v.R); err != nil && w.err == nil {
+			w.err = err
+		}
+		enc.Close()
+		w.WriteString("</base64>")
+		return
+	case time.Time:
+		w.WriteString("<dateTime.iso8601>" + v.Format("20060102T15:04:05") + "</dateTime.iso8601>")
+		return
+	case ArrayStream:
+		w.WriteString("<array><data>")
+		for elem, err := range v {
+			if err != nil {
+				if w.err == nil {
+					w.err = err
+				}
+				return
+			}
+			w.WriteString("<value>")
+			e.write(w, elem, typ)
+			w.WriteString("</value>")
+			if w.err != nil {
+				return
+			}
+		}
+		w.WriteString("</data></array>")
+		return
+	}
+
+	switch k {
+	case reflect.Invalid:
+		panic("unsupported type")
+	case reflect.Bool:
+		w.WriteString(fmt.Sprintf("<boolean>%v</boolean>", v))
+	case reflect.Int,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if (k == reflect.Uint || k == reflect.Uint64) && r.Uint() > math.MaxInt64 {
+			e.writeUint64(w, r.Uint(), typ)
+		} else if typ && e.i8 && !fitsI4(r) {
+			w.WriteString(fmt.Sprintf("<i8>%v</i8>", v))
+		} else if typ {
+			w.WriteString(fmt.Sprintf("<int>%v</int>", v))
+		} else {
+			w.WriteString(fmt.Sprintf("%v", v))
+		}
+	case reflect.Uintptr:
+		panic("unsupported type")
+	case reflect.Float32, reflect.Float64:
+		if typ {
+			w.WriteString(fmt.Sprintf("<double>%v</double>", v))
+		} else {
+			w.WriteString(fmt.Sprintf("%v", v))
+		}
+	case reflect.Complex64, reflect.Complex128:
+		panic("unsupported type")
+	case reflect.Array, reflect.Slice:
+		w.WriteString("<array><data>")
+		for n := 0; n < r.Len(); n++ {
+			w.WriteString("<value>")
+			e.write(w, r.Index(n).Interface(), typ)
+			w.WriteString("</value>")
+		}
+		w.WriteString("</data></array>")
+	case reflect.Chan:
+		panic("unsupported type")
+	case reflect.Func:
+		panic("unsupported type")
+	case reflect.Interface:
+		e.write(w, r.Elem(), typ)
+	case reflect.Map:
+		w.WriteString("<struct>")
+		keys := r.MapKeys()
+		if e.sortKeys {
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		}
+		for _, key := range keys {
+			e.checkChars(w, key.Interface().(string))
+			w.WriteString("<member>")
+			w.WriteString("<name>" + e.escape(key.Interface().(string)) + "</name>")
+			w.WriteString("<value>")
+			e.write(w, r.MapIndex(key).Interface(), typ)
+			w.WriteString("</value>")
+			w.WriteString("</member>")
+		}
+		w.WriteString("</struct>")
+	case reflect.Ptr:
+		if r.IsNil() {
+			w.WriteString("<nil/>")
+		} else {
+			e.write(w, r.Elem().Interface(), typ)
+		}
+	case reflect.String:
+		e.checkChars(w, v.(string))
+		if typ {
+			w.WriteString("<string>" + e.escapeString(v.(string)) + "</string>")
+		} else {
+			w.WriteString(e.escapeString(v.(string)))
+		}
+	case reflect.Struct:
+		w.WriteString("<struct>")
+		for n := 0; n < r.NumField(); n++ {
+			name, ok := fieldName(t.Field(n))
+			if !ok || omitEmpty(t.Field(n)) && r.Field(n).IsZero() {
+				continue
+			}
+			w.WriteString("<member>")
+			w.WriteString("<name>" + e.escape(name) + "</name>")
+			w.WriteString("<value>")
+			e.write(w, r.FieldByIndex([]int{n}).Interface(), true)
+			w.WriteString("</value>")
+			w.WriteString("</member>")
+		}
+		w.WriteString("</struct>")
+	case reflect.UnsafePointer:
+		e.write(w, r.Elem(), typ)
+	}
+}
+
+// writeUint64 writes n, which doesn't fit in an i8, according to the
+// Uint64Policy.
+func (e *encoder) writeUint64(w *errWriter, n uint64, typ bool) {
+	switch e.uint64 {
+	case Uint64Clamp:
+		e.write(w, int64(math.MaxInt64), typ)
+	case Uint64AsI8:
+		if typ {
+			w.WriteString(fmt.Sprintf("<i8>%d</i8>", int64(n)))
+		} else {
+			w.WriteString(fmt.Sprintf("%d", int64(n)))
+		}
+	case Uint64String:
+		e.write(w, strconv.FormatUint(n, 10), typ)
+	default:
+		if w.err == nil {
+			w.err = fmt.Errorf("xmlrpc: %d overflows i8", n)
+		}
+	}
+}
+
+// fitsI4 reports whether the integer r fits in an i4.
+func fitsI4(r reflect.Value) bool {
+	switch r.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return r.Uint() <= math.MaxInt32
+	}
+	n := r.Int()
+	return n >= math.MinInt32 && n <= math.MaxInt32
+}
+
+// escapeString escapes s for use as character data.
+func (e *encoder) escapeString(s string) string {
+	if e.cdata > 0 {
+		s = e.sanitize(s)
+		n := 0
+		for i := 0; i < len(s); i++ {
+			if _, ok := xmlSpecial[s[i]]; ok {
+				n++
+			}
+		}
+		// Character references aren't recognized in CDATA sections.
+		if n >= e.cdata && !(e.ascii && !isASCII(s)) {
+			return "<![CDATA[" + strings.Replace(s, "]]>", "]]]]><![CDATA[>", -1) + "]]>"
+		}
+	}
+	return e.escape(s)
+}
+
+// isXMLChar reports whether r is in the character range of XML 1.0.
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// validXML reports whether s is valid UTF-8 of characters allowed by XML.
+func validXML(s string) bool {
+	for i, r := range s {
+		if r == utf8.RuneError && !strings.HasPrefix(s[i:], "\uFFFD") || !isXMLChar(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkChars fails encoding if s has characters XML doesn't allow and the
+// policy is InvalidCharError.
+func (e *encoder) checkChars(w *errWriter, s string) {
+	if e.invalidChars == InvalidCharError && w.err == nil && !validXML(s) {
+		w.err = fmt.Errorf("xmlrpc: string %q has characters invalid in XML", s)
+	}
+}
+
+// sanitize strips or replaces the characters of s which XML doesn't allow,
+// according to the policy.
+func (e *encoder) sanitize(s string) string {
+	if (e.invalidChars != InvalidCharStrip && e.invalidChars != InvalidCharReplace) || validXML(s) {
+		return s
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if r == utf8.RuneError && !strings.HasPrefix(s[i:], "\uFFFD") || !isXMLChar(r) {
+			if e.invalidChars == InvalidCharReplace {
+				b.WriteRune(utf8.RuneError)
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escape escapes the markup characters of s and, if ascii is set, all
+// non-ASCII characters.
+func (e *encoder) escape(s string) string {
+	s = xmlEscape(e.sanitize(s))
+	if !e.ascii || isASCII(s) {
+		return s
+	}
+	var b bytes.Buffer
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "&#x%X;", r)
+		}
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMethodName rejects method names which can't be right, such as
+// names containing whitespace, control characters or markup.
+func validateMethodName(name string) error {
+	if name == "" {
+		return errors.New("invalid method name: empty")
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("invalid method name %q: invalid UTF-8", name)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) || unicode.IsSpace(r) || strings.ContainsRune(`<>&"'`, r) {
+			return fmt.Errorf("invalid method name %q: contains %q", name, r)
+		}
+	}
+	return nil
+}
+
+// declaration returns the XML declaration starting payloads.
+func (e *encoder) declaration() string {
+	switch {
+	case e.noDeclaration:
+		return ""
+	case e.encoding != "":
+		return `<?xml version="1.0" encoding="` + xmlEscape(e.encoding) + `"?>`
+	}
+	return `<?xml version="1.0"?>`
+}
+
+// writeRequest writes a methodCall of name with args to w.
+func (e *encoder) writeRequest(w io.Writer, name string, args ...interface{}) error {
+	if e.indent != "" {
+		var buf bytes.Buffer
+		compact := *e
+		compact.indent = ""
+		// The declaration is written as is, as the indenter would decode
+		// the payload in the encoding it declares.
+		compact.noDeclaration = true
+		if err := compact.writeRequest(&buf, name, args...); err != nil {
+			return err
+		}
+		if decl := e.declaration(); decl != "" {
+			if _, err := io.WriteString(w, decl+"\n"); err != nil {
+				return err
+			}
+		}
+		return indentXML(w, &buf, "", e.indent, e.escape)
+	}
+	ew := &errWriter{w: w}
+	ew.WriteString(e.declaration() + "<methodCall>")
+	ew.WriteString("<methodName>" + e.escape(name) + "</methodName>")
+	if len(args) > 0 || !e.omitEmptyParams {
+		ew.WriteString("<params>")
+		for _, arg := range args {
+			ew.WriteString("<param><value>")
+			e.write(ew, arg, true)
+			ew.WriteString("</value></param>")
+		}
+		ew.WriteString("</params>")
+	}
+	ew.WriteString("</methodCall>")
+	return ew.err
+}
+
+// writeResponse writes a methodResponse carrying v to w.
+func (e *encoder) writeResponse(w io.Writer, v interface{}) error {
+	ew := &errWriter{w: w}
+	ew.WriteString(e.declaration() + "<methodResponse><params><param><value>")
+	e.write(ew, v, true)
+	ew.WriteString(`</value></param></params></methodResponse>`)
+	return ew.err
+}
+
+// makeRequest returns the body of a methodCall of name with args. Bodies
+// with Base64Reader or ArrayStream arguments are streamed, all others are
+// buffered. Indented bodies are only written once they are complete.
+func (e *encoder) makeRequest(name string, args ...interface{}) (io.Reader, error) {
+	if !e.anyMethodName {
+		if err := validateMethodName(name); err != nil {
+			return nil, err
+		}
+	}
+	if hasReader(args) {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(e.writeRequest(pw, name, args...))
+		}()
+		return pr, nil
+	}
+	var buf bytes.Buffer
+	if err := e.writeRequest(&buf, name, args...); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// hasReader reports whether v contains a Base64Reader or an ArrayStream,
+// walking it like write does.
+func hasReader(v interface{}) bool {
+	switch v.(type) {
+	case Base64Reader, ArrayStream:
+		return true
+	case nil, []byte, time.Time:
+		return false
+	}
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Array, reflect.Slice:
+		if isScalarKind(r.Type().Elem().Kind()) {
+			return false
+		}
+		for n := 0; n < r.Len(); n++ {
+			if hasReader(r.Index(n).Interface()) {
+				return true
+			}
+		}
+	case reflect.Map:
+		if isScalarKind(r.Type().Elem().Kind()) {
+			return false
+		}
+		iter := r.MapRange()
+		for iter.Next() {
+			if hasReader(iter.Value().Interface()) {
+				return true
+			}
+		}
+	case reflect.Ptr:
+		if !r.IsNil() {
+			return hasReader(r.Elem().Interface())
+		}
+	case reflect.Struct:
+		t := r.Type()
+		for n := 0; n < r.NumField(); n++ {
+			if _, ok := fieldName(t.Field(n)); ok && hasReader(r.Field(n).Interface()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isScalarKind reports whether values of kind k can't contain others, so
+// that hasReader can skip e.g. large []int.
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
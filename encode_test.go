@@ -0,0 +1,214 @@
+package xmlrpc
+
+import (
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustRequest(t *testing.T, e *encoder, name string, args ...interface{}) []byte {
+	t.Helper()
+	r, err := e.makeRequest(name, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEncodeCDATA(t *testing.T) {
+	e := &encoder{cdata: 3}
+	s := e.toXml("<p>a & b]]></p>", true)
+	want := "<string><![CDATA[<p>a & b]]]]><![CDATA[></p>]]></string>"
+	if s != want {
+		t.Fatalf("want %q but got %q", want, s)
+	}
+	if s := e.toXml("a < b", true); s != "<string>a &lt; b</string>" {
+		t.Fatalf("want escaped string but got %q", s)
+	}
+}
+
+func TestCDATARoundTrip(t *testing.T) {
+	in := "<p>a & b]]></p>"
+	for _, e := range []*encoder{{}, {cdata: 1}} {
+		payload := "<methodResponse><params><param><value>" + e.toXml(in, true) + "</value></param></params></methodResponse>"
+		v, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != in {
+			t.Fatalf("want %q but got %q", in, v)
+		}
+	}
+}
+
+func TestEncodeEmptyParams(t *testing.T) {
+	want := `<?xml version="1.0"?><methodCall><methodName>system.listMethods</methodName><params></params></methodCall>`
+	if s := string(mustRequest(t, &encoder{}, "system.listMethods")); s != want {
+		t.Fatalf("want %q but got %q", want, s)
+	}
+	want = `<?xml version="1.0"?><methodCall><methodName>system.listMethods</methodName></methodCall>`
+	if s := string(mustRequest(t, &encoder{omitEmptyParams: true}, "system.listMethods")); s != want {
+		t.Fatalf("want %q but got %q", want, s)
+	}
+	if s := string(mustRequest(t, &encoder{omitEmptyParams: true}, "f", 1)); !strings.Contains(s, "<params>") {
+		t.Fatalf("want params for call with arguments but got %q", s)
+	}
+}
+
+func TestEncodeMethodName(t *testing.T) {
+	for _, name := range []string{"examples.getStateName", "wp/v2:get_posts", "métodos.ñ"} {
+		if _, err := (&encoder{}).makeRequest(name); err != nil {
+			t.Fatalf("want %q to be valid but got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a b", "a\x00b", "a<b>", "a&b", "\xff"} {
+		if _, err := (&encoder{}).makeRequest(name); err == nil {
+			t.Fatalf("want %q to be invalid", name)
+		}
+	}
+	s := string(mustRequest(t, &encoder{anyMethodName: true}, "a <b>"))
+	if !strings.Contains(s, "<methodName>a &lt;b&gt;</methodName>") {
+		t.Fatalf("want escaped method name but got %q", s)
+	}
+}
+
+func TestEncodeASCII(t *testing.T) {
+	e := &encoder{ascii: true, indent: " "}
+	s := string(mustRequest(t, e, "f", map[string]interface{}{"名前": "café & 😀"}))
+	if !isASCII(s) {
+		t.Fatalf("want pure ASCII but got %q", s)
+	}
+	if !strings.Contains(s, "<name>&#x540D;&#x524D;</name>") || !strings.Contains(s, "<string>caf&#xE9; &amp; &#x1F600;</string>") {
+		t.Fatalf("want character references but got %q", s)
+	}
+	v, err := newDecoder(strings.NewReader("<methodResponse><params><param><value>"+e.toXml("café", true)+"</value></param></params></methodResponse>"), decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "café" {
+		t.Fatalf("want %q but got %q", "café", v)
+	}
+	if s := (&encoder{ascii: true, cdata: 1}).toXml("<é>", true); s != "<string>&lt;&#xE9;&gt;</string>" {
+		t.Fatalf("want no CDATA for non-ASCII strings but got %q", s)
+	}
+}
+
+func TestEncodeXMLDeclaration(t *testing.T) {
+	for _, tc := range []struct {
+		opt  Option
+		decl string
+	}{
+		{func(*Client) {}, `<?xml version="1.0"?>`},
+		{WithXMLDeclaration("UTF-8"), `<?xml version="1.0" encoding="UTF-8"?>`},
+		{WithoutXMLDeclaration(), ``},
+	} {
+		c := NewClient("http://localhost/", tc.opt)
+		if s := string(mustRequest(t, &c.enc, "f")); !strings.HasPrefix(s, tc.decl+"<methodCall>") {
+			t.Errorf("want declaration %q but got %q", tc.decl, s)
+		}
+		want := "<methodCall>\n"
+		if tc.decl != "" {
+			want = tc.decl + "\n" + want
+		}
+		c = NewClient("http://localhost/", tc.opt, WithIndent(" "))
+		if s := string(mustRequest(t, &c.enc, "f")); !strings.HasPrefix(s, want) {
+			t.Errorf("want indented declaration %q but got %q", tc.decl, s)
+		}
+	}
+
+	// An encoding other than UTF-8 is declared as is.
+	e := &encoder{encoding: "ISO-8859-1", indent: " "}
+	s := string(mustRequest(t, e, "f", "café"))
+	if !strings.HasPrefix(s, `<?xml version="1.0" encoding="ISO-8859-1"?>`) || !strings.Contains(s, "<string>café</string>") {
+		t.Fatalf("unexpected request %q", s)
+	}
+}
+
+func TestEncodeTime(t *testing.T) {
+	tm := time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC)
+	s := (&encoder{}).toXml(tm, true)
+	if want := "<dateTime.iso8601>19980717T14:08:55</dateTime.iso8601>"; s != want {
+		t.Fatalf("want %q but got %q", want, s)
+	}
+}
+
+func TestEncodeUint64(t *testing.T) {
+	const big = uint64(math.MaxInt64) + 2
+	for policy, want := range map[Uint64Policy]string{
+		Uint64Clamp:  "<int>9223372036854775807</int>",
+		Uint64AsI8:   "<i8>-9223372036854775807</i8>",
+		Uint64String: "<string>9223372036854775809</string>",
+	} {
+		if s := (&encoder{uint64: policy}).toXml(big, true); s != want {
+			t.Fatalf("want %q for policy %d but got %q", want, policy, s)
+		}
+	}
+	if s := (&encoder{}).toXml(uint64(math.MaxInt64), true); s != "<int>9223372036854775807</int>" {
+		t.Fatalf("want int within range but got %q", s)
+	}
+	if _, err := (&encoder{}).makeRequest("f", []interface{}{big}); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Fatalf("want overflow error but got %v", err)
+	}
+}
+
+func TestEncodeInvalidChars(t *testing.T) {
+	const in = "a\x00b\x1bc\xffd�e"
+	for policy, want := range map[InvalidCharPolicy]string{
+		InvalidCharKeep:    "<string>" + in + "</string>",
+		InvalidCharStrip:   "<string>abcd�e</string>",
+		InvalidCharReplace: "<string>a�b�c�d�e</string>",
+	} {
+		if s := (&encoder{invalidChars: policy}).toXml(in, true); s != want {
+			t.Fatalf("want %q for policy %d but got %q", want, policy, s)
+		}
+	}
+	if s := (&encoder{invalidChars: InvalidCharStrip, cdata: 1}).toXml("<\x01>", true); s != "<string><![CDATA[<>]]></string>" {
+		t.Fatalf("want CDATA stripped but got %q", s)
+	}
+
+	e := &encoder{invalidChars: InvalidCharError}
+	for _, arg := range []interface{}{in, Struct{"\x01": 1}} {
+		if _, err := e.makeRequest("f", arg); err == nil {
+			t.Fatalf("want error for %q", arg)
+		}
+	}
+	if _, err := e.makeRequest("f", "tab\tnewline\n\U0001F600"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHasReader(t *testing.T) {
+	type upload struct {
+		Name string
+		Data Base64Reader
+	}
+	type private struct {
+		data Base64Reader
+	}
+	r := Base64Reader{R: strings.NewReader("x")}
+	for _, tt := range []struct {
+		v    interface{}
+		want bool
+	}{
+		{Array{1, Struct{"a": r}}, true},
+		{upload{Name: "a", Data: r}, true},
+		{&upload{Data: r}, true},
+		{[]Struct{{"a": 1}, {"b": r}}, true},
+		{map[string][]interface{}{"a": {r}}, true},
+		{[][]Base64Reader{{r}}, true},
+		{[]int{1, 2, 3}, false},
+		{[]Struct{{"a": []byte("x")}}, false},
+		{private{data: r}, false},
+		{(*upload)(nil), false},
+	} {
+		if got := hasReader(tt.v); got != tt.want {
+			t.Fatalf("want %v for %#v but got %v", tt.want, tt.v, got)
+		}
+	}
+}
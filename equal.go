@@ -0,0 +1,132 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Equal reports whether a and b are the same XML-RPC value. Struct and
+// map[string]interface{}, Array and []interface{}, and integers of
+// different Go types are compared by content, []byte by bytes and
+// time.Time by instant.
+func Equal(a, b interface{}) bool {
+	var diffs []string
+	diff(a, b, "", &diffs)
+	return len(diffs) == 0
+}
+
+// Diff returns a description of the differences between a and b, one line
+// per differing value and prefixed with its path, or "" if they are Equal.
+func Diff(a, b interface{}) string {
+	var diffs []string
+	diff(a, b, "", &diffs)
+	return strings.Join(diffs, "\n")
+}
+
+func diff(a, b interface{}, path string, diffs *[]string) {
+	a, b = normalize(a), normalize(b)
+	report := func(format string, args ...interface{}) {
+		p := path
+		if p == "" {
+			p = "."
+		}
+		*diffs = append(*diffs, p+": "+fmt.Sprintf(format, args...))
+	}
+	switch av := a.(type) {
+	case Struct:
+		bv, ok := b.(Struct)
+		if !ok {
+			report("%s != %s", brief(a), brief(b))
+			return
+		}
+		names := make([]string, 0, len(av)+len(bv))
+		for name := range av {
+			names = append(names, name)
+		}
+		for name := range bv {
+			if _, ok := av[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ae, aok := av[name]
+			be, bok := bv[name]
+			switch {
+			case !bok:
+				*diffs = append(*diffs, path+"."+name+": only in a: "+brief(ae))
+			case !aok:
+				*diffs = append(*diffs, path+"."+name+": only in b: "+brief(be))
+			default:
+				diff(ae, be, path+"."+name, diffs)
+			}
+		}
+	case Array:
+		bv, ok := b.(Array)
+		if !ok {
+			report("%s != %s", brief(a), brief(b))
+			return
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(bv):
+				*diffs = append(*diffs, p+": only in a: "+brief(av[i]))
+			case i >= len(av):
+				*diffs = append(*diffs, p+": only in b: "+brief(bv[i]))
+			default:
+				diff(av[i], bv[i], p, diffs)
+			}
+		}
+	case []byte:
+		if bv, ok := b.([]byte); !ok || !bytes.Equal(av, bv) {
+			report("%s != %s", brief(a), brief(b))
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); !ok || !av.Equal(bv) {
+			report("%s != %s", brief(a), brief(b))
+		}
+	default:
+		if !reflect.DeepEqual(a, b) {
+			report("%s != %s", brief(a), brief(b))
+		}
+	}
+}
+
+// normalize converts v to the types the decoder returns.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return Struct(v)
+	case []interface{}:
+		return Array(v)
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
+
+// brief returns a one line rendering of v.
+func brief(v interface{}) string {
+	switch v := v.(type) {
+	case Struct:
+		return fmt.Sprintf("struct with %d members", len(v))
+	case Array:
+		return fmt.Sprintf("array of %d values", len(v))
+	}
+	var b bytes.Buffer
+	dump(&errWriter{w: &b}, v, "")
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package xmlrpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	tm := time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC)
+	a := Struct{
+		"a": Array{1, "x", []byte("hi")},
+		"t": tm,
+	}
+	b := map[string]interface{}{
+		"a": []interface{}{int64(1), "x", []byte("hi")},
+		"t": tm.In(time.FixedZone("JST", 9*60*60)),
+	}
+	if !Equal(a, b) {
+		t.Fatalf("want equal but got diff:\n%s", Diff(a, b))
+	}
+	if d := Diff(a, b); d != "" {
+		t.Fatalf("want empty diff but got %q", d)
+	}
+
+	c := Struct{
+		"a": Array{2, "x"},
+		"t": tm.Add(time.Second),
+		"n": nil,
+	}
+	want := `.a[0]: int 1 != int 2
+.a[2]: only in a: base64 (2 bytes) aGk=
+.n: only in b: nil
+.t: dateTime.iso8601 19980717T14:08:55 != dateTime.iso8601 19980717T14:08:56`
+	if d := Diff(a, c); d != want {
+		t.Fatalf("want\n%s\nbut got\n%s", want, d)
+	}
+	if Equal(a, c) {
+		t.Fatal("want not equal")
+	}
+	if d := Diff(1, "1"); d != `.: int 1 != string "1"` {
+		t.Fatalf("unexpected diff %q", d)
+	}
+	if d := Diff(Array{}, Struct{}); d != ".: array of 0 values != struct with 0 members" {
+		t.Fatalf("unexpected diff %q", d)
+	}
+}
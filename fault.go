@@ -0,0 +1,116 @@
+package xmlrpc
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Fault codes defined by the Fault Code Interoperability specification,
+// http://xmlrpc-epi.sourceforge.net/specs/rfc.fault_codes.php.
+const (
+	ParseError          = -32700 // not well formed
+	UnsupportedEncoding = -32701
+	InvalidCharacter    = -32702 // invalid character for encoding
+	InvalidRequest      = -32600 // not conforming to the XML-RPC spec
+	MethodNotFound      = -32601
+	InvalidParams       = -32602
+	InternalError       = -32603
+	ApplicationError    = -32500
+	SystemError         = -32400
+	TransportError      = -32300
+)
+
+// Fault is an error reported by the server in a fault response.
+type Fault struct {
+	Code   int
+	String string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("xmlrpc: fault %d: %s", f.Code, f.String)
+}
+
+// requestFault returns the fault reporting err, which d returned while
+// decoding a request.
+func requestFault(d *decoder, err error) *Fault {
+	code := InvalidRequest
+	var se *xml.SyntaxError
+	switch {
+	case d.charsetErr != nil:
+		code = UnsupportedEncoding
+	case errors.As(err, &se) && (se.Msg == "invalid UTF-8" || strings.HasPrefix(se.Msg, "illegal character code")):
+		code = InvalidCharacter
+	case se != nil, errors.Is(err, io.ErrUnexpectedEOF):
+		code = ParseError
+	}
+	return &Fault{Code: code, String: err.Error()}
+}
+
+// fault decodes the content of a <fault> element whose start element has
+// already been read.
+func (d *decoder) fault() error {
+	d.push("fault")
+	defer d.pop()
+	if _, err := d.expect("value"); err != nil {
+		return err
+	}
+	d.push("value")
+	v, err := d.value()
+	if err != nil {
+		return err
+	}
+	d.pop()
+	st, ok := v.(Struct)
+	if !ok {
+		return d.error("struct", "", fmt.Errorf("fault value is %T", v))
+	}
+	f := &Fault{}
+	switch code := st["faultCode"].(type) {
+	case int, int32, int64:
+		n, _ := toInt64(code)
+		f.Code = int(n)
+	case string:
+		// Some servers send the code as a string.
+		d.violate("faultCode is a string")
+		fmt.Sscan(code, &f.Code)
+	default:
+		d.violate("faultCode is missing or not an int")
+	}
+	if f.String, ok = st["faultString"].(string); !ok {
+		d.violate("faultString is missing or not a string")
+	}
+	if len(st) != 2 {
+		d.violate("fault has %d members instead of faultCode and faultString", len(st))
+	}
+	if err := d.expectEnd("fault"); err != nil {
+		return err
+	}
+	return f
+}
+
+// FaultRenderer returns the fault string sent for f in response to the
+// call whose context is ctx, e.g. translated to the language of the
+// Accept-Language header of its Peer. The code of f is sent unchanged.
+type FaultRenderer func(ctx context.Context, f *Fault) string
+
+// WithFaultRenderer makes the server send the fault strings rendered by fn
+// for all faults, including those of the server itself such as
+// MethodNotFound. Hooks and metrics see the original faults.
+func WithFaultRenderer(fn FaultRenderer) ServerOption {
+	return func(s *Server) {
+		s.faultRenderer = fn
+	}
+}
+
+// writeFault writes a methodResponse carrying f to w.
+func (e *encoder) writeFault(w io.Writer, f *Fault) error {
+	ew := &errWriter{w: w}
+	ew.WriteString(e.declaration() + "<methodResponse><fault><value>")
+	e.write(ew, Struct{"faultCode": f.Code, "faultString": f.String}, true)
+	ew.WriteString(`</value></fault></methodResponse>`)
+	return ew.err
+}
@@ -0,0 +1,54 @@
+package xmlrpc
+
+import (
+	"net/http"
+	"runtime"
+)
+
+// FetchOptions are options of the Fetch API of browsers, used by clients
+// compiled for GOOS=js and GOARCH=wasm. Empty options are left to the
+// browser.
+type FetchOptions struct {
+	Mode        string // "cors", "no-cors" or "same-origin"
+	Credentials string // "omit", "same-origin" or "include", e.g. for the cookies of a gateway
+	Redirect    string // "follow", "error" or "manual"
+}
+
+// WithFetch makes the client send requests with the Fetch API of the
+// browser, with opts, when compiled for GOOS=js and GOARCH=wasm. It
+// replaces the transport of a copy of the http.Client of the client, as
+// transports which dial themselves, such as those of WithHTTP2 or
+// WithDialContext, can't be used in browsers. It has no effect on other
+// platforms, so code sharing the client needn't be built for each.
+func WithFetch(opts FetchOptions) Option {
+	return func(c *Client) {
+		if runtime.GOOS == "js" {
+			hc := *c.HttpClient
+			hc.Transport = &fetchTransport{opts: opts, base: http.DefaultTransport}
+			c.HttpClient = &hc
+		}
+	}
+}
+
+// fetchTransport passes the FetchOptions to the transport of net/http,
+// which uses the Fetch API unless dialing is customized, in the headers it
+// takes them from.
+type fetchTransport struct {
+	opts FetchOptions
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *fetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range map[string]string{
+		"js.fetch:mode":        t.opts.Mode,
+		"js.fetch:credentials": t.opts.Credentials,
+		"js.fetch:redirect":    t.opts.Redirect,
+	} {
+		if value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
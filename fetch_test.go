@@ -0,0 +1,40 @@
+package xmlrpc
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+	"testing"
+)
+
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestWithFetch(t *testing.T) {
+	c := NewClient("http://localhost/", WithFetch(FetchOptions{Mode: "cors"}))
+	if _, ok := c.HttpClient.Transport.(*fetchTransport); ok != (runtime.GOOS == "js") {
+		t.Fatalf("unexpected transport %T on %s", c.HttpClient.Transport, runtime.GOOS)
+	}
+
+	var got http.Header
+	rt := &fetchTransport{
+		opts: FetchOptions{Mode: "cors", Credentials: "include"},
+		base: transportFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header
+			return nil, errors.New("offline")
+		}),
+	}
+	req, _ := http.NewRequest("POST", "http://localhost/", nil)
+	req.Header.Set("Content-Type", "text/xml")
+	rt.RoundTrip(req)
+	if got.Get("js.fetch:mode") != "cors" || got.Get("js.fetch:credentials") != "include" || got.Get("Content-Type") != "text/xml" {
+		t.Fatalf("unexpected header %v", got)
+	}
+	if _, ok := got["Js.fetch:redirect"]; ok {
+		t.Fatal("want empty options left out")
+	}
+	if req.Header.Get("js.fetch:mode") != "" {
+		t.Fatal("want request unchanged")
+	}
+}
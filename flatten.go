@@ -0,0 +1,132 @@
+package xmlrpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flatten returns the scalars of v keyed by their path, as accepted by
+// Struct.Get, e.g. "post.categories[0]". Scalars are formatted as in XML,
+// except for booleans which are "true" or "false". nil and empty structs
+// and arrays are "". A scalar v has the key "". Member names containing .
+// or [ give ambiguous keys.
+func Flatten(v interface{}) map[string]string {
+	m := map[string]string{}
+	flatten(m, "", v)
+	return m
+}
+
+func flatten(m map[string]string, key string, v interface{}) {
+	switch v := v.(type) {
+	case Struct:
+		if len(v) == 0 {
+			m[key] = ""
+		}
+		for name, e := range v {
+			if key != "" {
+				name = key + "." + name
+			}
+			flatten(m, name, e)
+		}
+	case Array:
+		if len(v) == 0 {
+			m[key] = ""
+		}
+		for i, e := range v {
+			flatten(m, key+"["+strconv.Itoa(i)+"]", e)
+		}
+	case nil:
+		m[key] = ""
+	case string:
+		m[key] = v
+	case int, int32, int64:
+		n, _ := toInt64(v)
+		m[key] = strconv.FormatInt(n, 10)
+	case float64:
+		m[key] = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		m[key] = strconv.FormatBool(v)
+	case time.Time:
+		m[key] = v.Format("20060102T15:04:05")
+	case []byte:
+		m[key] = base64.StdEncoding.EncodeToString(v)
+	case RawValue:
+		m[key] = strings.TrimSpace(v.XML)
+	default:
+		m[key] = fmt.Sprint(v)
+	}
+}
+
+// Unflatten is the inverse of Flatten, building the Struct or Array, or
+// the string with the key "", of which m holds the scalars. All scalars
+// are strings. Missing array elements are nil.
+func Unflatten(m map[string]string) (interface{}, error) {
+	var v interface{}
+	for key, s := range m {
+		var steps []step
+		if key != "" {
+			var err error
+			if steps, err = parsePath(key); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if v, err = unflatten(v, steps, s, len(m)); err != nil {
+			return nil, fmt.Errorf("xmlrpc: can't unflatten %q: %v", key, err)
+		}
+	}
+	return v, nil
+}
+
+// unflatten sets the value at steps in v to s, returning the updated v.
+// Indexes must be less than n, the number of keys.
+func unflatten(v interface{}, steps []step, s string, n int) (interface{}, error) {
+	if len(steps) == 0 {
+		if v != nil {
+			return nil, fmt.Errorf("conflicts with %T", v)
+		}
+		return s, nil
+	}
+	st := steps[0]
+	switch {
+	case st.all:
+		return nil, fmt.Errorf("wildcard not allowed")
+	case st.index < 0:
+		m, ok := v.(Struct)
+		if v == nil {
+			m, ok = Struct{}, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("member %q conflicts with %T", st.name, v)
+		}
+		e, err := unflatten(m[st.name], steps[1:], s, n)
+		if err != nil {
+			return nil, err
+		}
+		m[st.name] = e
+		return m, nil
+	default:
+		a, ok := v.(Array)
+		if v == nil {
+			ok = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("index %d conflicts with %T", st.index, v)
+		}
+		if st.index >= n {
+			return nil, fmt.Errorf("index %d out of range", st.index)
+		}
+		for len(a) <= st.index {
+			a = append(a, nil)
+		}
+		e, err := unflatten(a[st.index], steps[1:], s, n)
+		if err != nil {
+			return nil, err
+		}
+		a[st.index] = e
+		return a, nil
+	}
+}
@@ -0,0 +1,91 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlatten(t *testing.T) {
+	v := Struct{
+		"post": Struct{
+			"title":      "hello",
+			"id":         42,
+			"score":      1.5,
+			"draft":      true,
+			"date":       time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC),
+			"data":       []byte("hi"),
+			"categories": Array{"go", Struct{"name": "xml"}},
+			"tags":       Array{},
+		},
+		"next": nil,
+	}
+	want := map[string]string{
+		"post.title":              "hello",
+		"post.id":                 "42",
+		"post.score":              "1.5",
+		"post.draft":              "true",
+		"post.date":               "19980717T14:08:55",
+		"post.data":               "aGk=",
+		"post.categories[0]":      "go",
+		"post.categories[1].name": "xml",
+		"post.tags":               "",
+		"next":                    "",
+	}
+	m := Flatten(v)
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("want %v but got %v", want, m)
+	}
+	for key, s := range m {
+		if e, ok := v.Get(key); !ok || (s != "" && e == nil) {
+			t.Errorf("%s: got %v, %v", key, e, ok)
+		}
+	}
+
+	if m := Flatten(Array{1, Array{2}}); !reflect.DeepEqual(m, map[string]string{"[0]": "1", "[1][0]": "2"}) {
+		t.Fatalf("unexpected flattened array %v", m)
+	}
+	if m := Flatten("x"); !reflect.DeepEqual(m, map[string]string{"": "x"}) {
+		t.Fatalf("unexpected flattened scalar %v", m)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	v, err := Unflatten(map[string]string{
+		"post.title":              "hello",
+		"post.categories[0]":      "go",
+		"post.categories[1].name": "xml",
+		"post.categories[3]":      "rpc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Struct{"post": Struct{
+		"title":      "hello",
+		"categories": Array{"go", Struct{"name": "xml"}, nil, "rpc"},
+	}}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("want %v but got %v", want, v)
+	}
+
+	a := Array{"a", Array{"b", Struct{"c": "d"}}}
+	if v, err := Unflatten(Flatten(a)); err != nil || !reflect.DeepEqual(v, a) {
+		t.Fatalf("want %v but got %v, %v", a, v, err)
+	}
+	if v, err := Unflatten(map[string]string{"": "x"}); err != nil || v != "x" {
+		t.Fatalf("want scalar but got %v, %v", v, err)
+	}
+
+	for _, m := range []map[string]string{
+		{"a": "1", "a.b": "2"},
+		{"a[0]": "1", "a.b": "2"},
+		{"a[5]": "1"},
+		{"a.*": "1"},
+		{"a..b": "1"},
+		{"": "1", "a": "2"},
+	} {
+		if v, err := Unflatten(m); err == nil {
+			t.Errorf("%v: want error but got %v", m, v)
+		}
+	}
+}
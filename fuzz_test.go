@@ -0,0 +1,72 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fuzzResponses are payloads of the tests, used as seed corpus.
+var fuzzResponses = []string{
+	`<?xml version="1.0"?><methodResponse><params><param><value><string>South Dakota</string></value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value><array><data><value><i4>1</i4></value><value><int>-2</int></value><value><i8>3000000000</i8></value></data></array></value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value><struct><member><name>a</name><value><double>1.5</double></value></member><member><name>b</name><value><boolean>1</boolean></value></member></struct></value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value><dateTime.iso8601>19980717T14:08:55</dateTime.iso8601></value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value><base64>eW91IGNhbid0IHJlYWQgdGhpcyE=</base64></value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value><nil/></value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value>untyped</value></param></params></methodResponse>`,
+	`<methodResponse><params><param><value><string><![CDATA[<p>a & b]]></string></value></param></params></methodResponse>`,
+	`<methodResponse><fault><value><struct><member><name>faultCode</name><value><int>4</int></value></member><member><name>faultString</name><value><string>Too many parameters.</string></value></member></struct></value></fault></methodResponse>`,
+	`<?xml version="1.0" encoding="ISO-8859-1"?><methodResponse><params><param><value>caf` + "\xe9" + `</value></param></params></methodResponse>`,
+	"\xef\xbb\xbf<methodResponse><params><param><value>bom</value></param></params></methodResponse>",
+}
+
+// fuzzRequests are payloads of the tests, used as seed corpus.
+var fuzzRequests = []string{
+	`<?xml version="1.0"?><methodCall><methodName>examples.getStateName</methodName><params><param><value><i4>41</i4></value></param></params></methodCall>`,
+	`<methodCall><methodName>system.listMethods</methodName></methodCall>`,
+	`<methodCall><methodName>f</methodName><params><param><value><array><data><value><struct><member><name>x</name><value>1</value></member></struct></value></data></array></value></param></params></methodCall>`,
+}
+
+func FuzzParseResponse(f *testing.F) {
+	for _, s := range fuzzResponses {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		v, err := ParseResponse(b)
+		if err == nil {
+			// Whatever decodes must encode.
+			if err := (&MethodResponse{Value: v}).Encode(io.Discard); err != nil {
+				t.Fatalf("can't encode %#v: %v", v, err)
+			}
+		}
+		for _, opts := range []decodeOptions{
+			{strict: true},
+			{lenient: true},
+			{trailing: TrailingError, strictScalars: true},
+			{limits: DecodeLimits{MaxStringSize: 16, MaxElements: 4}},
+		} {
+			d := newDecoder(bytes.NewReader(b), opts)
+			if _, err := d.response(); err == nil {
+				d.checkTrailing()
+			}
+		}
+		ValidateResponse(bytes.NewReader(b))
+	})
+}
+
+func FuzzParseRequest(f *testing.F) {
+	for _, s := range fuzzRequests {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		method, args, err := ParseRequest(b)
+		if err == nil {
+			if err := (&MethodCall{Name: method, Params: args}).Encode(io.Discard); err != nil && !strings.HasPrefix(err.Error(), "invalid method name") {
+				t.Fatalf("can't encode %q %#v: %v", method, args, err)
+			}
+		}
+		ValidateRequest(bytes.NewReader(b))
+	})
+}
@@ -0,0 +1,32 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DecodeMap stores the members of s in a map of T, converting them as
+// Unmarshal does.
+func DecodeMap[T any](s Struct) (map[string]T, error) {
+	m := make(map[string]T, len(s))
+	for name, v := range s {
+		var t T
+		if err := unmarshal(v, reflect.ValueOf(&t).Elem(), "."+name); err != nil {
+			return nil, err
+		}
+		m[name] = t
+	}
+	return m, nil
+}
+
+// DecodeSlice stores the elements of a in a slice of T, converting them as
+// Unmarshal does.
+func DecodeSlice[T any](a Array) ([]T, error) {
+	s := make([]T, len(a))
+	for i, v := range a {
+		if err := unmarshal(v, reflect.ValueOf(&s[i]).Elem(), fmt.Sprintf("[%d]", i)); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,50 @@
+package xmlrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeMap(t *testing.T) {
+	m, err := DecodeMap[int](Struct{"a": 1, "b": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, map[string]int{"a": 1, "b": 2}) {
+		t.Fatalf("unexpected map: %v", m)
+	}
+
+	type post struct {
+		Title string `xmlrpc:"title"`
+	}
+	posts, err := DecodeMap[post](Struct{"first": Struct{"title": "hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts["first"].Title != "hello" {
+		t.Fatalf("unexpected posts: %v", posts)
+	}
+
+	_, err = DecodeMap[int](Struct{"a": "x"})
+	var te *UnmarshalTypeError
+	if !errors.As(err, &te) || te.Path != ".a" {
+		t.Fatalf("want UnmarshalTypeError at .a but got %v", err)
+	}
+}
+
+func TestDecodeSlice(t *testing.T) {
+	s, err := DecodeSlice[string](Array{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, []string{"a", "b"}) {
+		t.Fatalf("unexpected slice: %v", s)
+	}
+
+	_, err = DecodeSlice[string](Array{"a", 1})
+	var te *UnmarshalTypeError
+	if !errors.As(err, &te) || te.Path != "[1]" {
+		t.Fatalf("want UnmarshalTypeError at [1] but got %v", err)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/mattn/go-xmlrpc
 
-go 1.10
+go 1.24
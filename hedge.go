@@ -0,0 +1,85 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// hedgePolicy is the configuration of WithHedging.
+type hedgePolicy struct {
+	delay time.Duration
+	urls  []string
+}
+
+// WithHedging makes the client send a duplicate of a call which got no
+// response after delay to the next of urls, or to its own URL again if
+// there are none, and so on, taking the first response and canceling the
+// others. A failed attempt starts the next one at once. Faults are
+// responses like results. Only calls which may be retried, as of
+// RetryPolicy.Idempotent and WithIdempotent, are hedged, and never calls
+// with Base64Reader arguments.
+func WithHedging(delay time.Duration, urls ...string) Option {
+	return func(c *Client) {
+		c.hedge = &hedgePolicy{delay: delay, urls: urls}
+	}
+}
+
+type hedgeResult struct {
+	v   interface{}
+	err error
+}
+
+// attempt makes an attempt of a call, hedging it if configured.
+func (c *Client) attempt(ctx context.Context, name string, body []byte, stream io.Reader, decode func(*decoder) (interface{}, error)) (interface{}, error) {
+	if c.hedge == nil || stream != nil || !c.retryPolicy(ctx).idempotent(ctx, name) {
+		return c.do(ctx, c.url, name, body, stream, decode)
+	}
+	urls := append([]string{c.url}, c.hedge.urls...)
+	if len(urls) == 1 {
+		urls = append(urls, c.url)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan hedgeResult, len(urls))
+	start := func(url string) {
+		go func() {
+			v, err := c.do(ctx, url, name, body, nil, decode)
+			results <- hedgeResult{v, err}
+		}()
+	}
+	start(urls[0])
+	next, pending := 1, 1
+	t := time.NewTimer(c.hedge.delay)
+	defer t.Stop()
+	var err error
+	for pending > 0 {
+		select {
+		case <-t.C:
+			if next < len(urls) {
+				start(urls[next])
+				next++
+				pending++
+				t.Reset(c.hedge.delay)
+			}
+		case r := <-results:
+			pending--
+			var f *Fault
+			if r.err == nil || errors.As(r.err, &f) {
+				return r.v, r.err
+			}
+			if err == nil {
+				err = r.err
+			}
+			if next < len(urls) {
+				start(urls[next])
+				next++
+				pending++
+				t.Reset(c.hedge.delay)
+			}
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,85 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedging(t *testing.T) {
+	slow := NewServer()
+	var canceled int32
+	slow.RegisterContext("get", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			atomic.AddInt32(&canceled, 1)
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "slow", nil
+		}
+	})
+	sts := httptest.NewServer(slow)
+	defer sts.Close()
+	fast := NewServer()
+	fast.Register("get", func(args ...interface{}) (interface{}, error) {
+		return "fast", nil
+	})
+	fts := httptest.NewServer(fast)
+	defer fts.Close()
+
+	c := NewClient(sts.URL, WithHedging(10*time.Millisecond, fts.URL))
+	start := time.Now()
+	v, err := c.Call("get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "fast" {
+		t.Fatalf("want fast but got %v", v)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("want hedged response but took %v", d)
+	}
+	for i := 0; atomic.LoadInt32(&canceled) == 0; i++ {
+		if i > 100 {
+			t.Fatal("want slow call canceled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Calls which aren't idempotent aren't hedged.
+	c = NewClient(sts.URL, WithHedging(10*time.Millisecond, fts.URL))
+	ctx, cancel := context.WithTimeout(WithIdempotent(context.Background(), false), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.CallContext(ctx, "get"); err == nil {
+		t.Fatal("want timeout for call which isn't hedged")
+	}
+}
+
+func TestHedgingFailover(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	s := NewServer()
+	s.Register("get", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 4, String: "no"}
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	// A failed attempt starts the next one without waiting, and faults are
+	// responses.
+	c := NewClient(down.URL, WithHedging(time.Hour, ts.URL))
+	_, err := c.Call("get")
+	if f, ok := err.(*Fault); !ok || f.Code != 4 {
+		t.Fatalf("want fault 4 but got %v", err)
+	}
+
+	c = NewClient(down.URL, WithHedging(time.Hour))
+	if _, err := c.Call("get"); err == nil {
+		t.Fatal("want error when all attempts fail")
+	}
+}
@@ -0,0 +1,81 @@
+package xmlrpc
+
+import (
+	"context"
+	"time"
+)
+
+// ServerCall describes a call handled by a Server, as passed to the hooks
+// set with WithHooks.
+type ServerCall struct {
+	Method     string
+	Args       int    // number of arguments
+	RemoteAddr string // empty if unknown
+	Start      time.Time
+
+	// Set for the after hook only.
+	Duration time.Duration
+	Result   interface{}
+	Err      error // the fault or error the call failed with
+}
+
+// WithHooks sets functions called before and after every call which names
+// a method, such as for access logs. Either may be nil. The after hook
+// receives the same ServerCall as the before hook, completed with the
+// outcome of the call.
+func WithHooks(before, after func(*ServerCall)) ServerOption {
+	return func(s *Server) {
+		s.before = before
+		s.after = after
+	}
+}
+
+// dispatch calls the method name with args.
+func (s *Server) dispatch(ctx context.Context, name string, args []interface{}) (v interface{}, err error) {
+	start := time.Now()
+	if s.before != nil || s.after != nil {
+		call := &ServerCall{Method: name, Args: len(args), Start: start}
+		if p, ok := PeerFromContext(ctx); ok {
+			call.RemoteAddr = p.RemoteAddr
+		}
+		if s.before != nil {
+			s.before(call)
+		}
+		if s.after != nil {
+			defer func() {
+				call.Duration = time.Since(call.Start)
+				call.Result, call.Err = v, err
+				s.after(call)
+			}()
+		}
+	}
+
+	ctx, done, err := s.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if s.halted() {
+			v, err = nil, &Fault{Code: SystemError, String: "server shut down"}
+		}
+		done()
+	}()
+
+	h, fallback, ok := s.lookup(name)
+	if s.metrics != nil {
+		known := name
+		switch {
+		case !ok:
+			known = unknownMethod
+		case fallback:
+			known = fallbackMethod
+		}
+		defer func() {
+			s.metrics.Observe(known, time.Since(start), err)
+		}()
+	}
+	if !ok {
+		return nil, &Fault{Code: MethodNotFound, String: "method not found: " + name}
+	}
+	return s.invoke(ctx, name, h, args)
+}
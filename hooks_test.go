@@ -0,0 +1,43 @@
+package xmlrpc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerHooks(t *testing.T) {
+	var before []string
+	var after []*ServerCall
+	s := NewServer(WithHooks(func(c *ServerCall) {
+		before = append(before, c.Method)
+	}, func(c *ServerCall) {
+		after = append(after, c)
+	}))
+	s.Register("add", func(args ...interface{}) (interface{}, error) {
+		return args[0].(int) + args[1].(int), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	if _, err := c.Call("add", 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	c.Call("missing")
+
+	if len(before) != 2 || before[0] != "add" || before[1] != "missing" {
+		t.Fatalf("unexpected before hook calls: %v", before)
+	}
+	if len(after) != 2 {
+		t.Fatalf("want 2 after hook calls but got %d", len(after))
+	}
+	a := after[0]
+	if a.Method != "add" || a.Args != 2 || a.Result != 3 || a.Err != nil || a.RemoteAddr == "" || a.Start.IsZero() {
+		t.Fatalf("unexpected call: %+v", a)
+	}
+	var f *Fault
+	if !errors.As(after[1].Err, &f) || f.Code != MethodNotFound {
+		t.Fatalf("want MethodNotFound fault but got %v", after[1].Err)
+	}
+}
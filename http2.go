@@ -0,0 +1,92 @@
+package xmlrpc
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// WithHTTP2 makes the client speak HTTP/2 only, negotiated by TLS for
+// https URLs and with prior knowledge, h2c, for http URLs, so that
+// concurrent calls share a single connection. If fallback is set, calls
+// to servers which fail to speak HTTP/2 are made with HTTP/1.1 from then
+// on; only calls which the server can't have seen, as it rejected the
+// connection, are made again. It replaces the transport of a copy of the
+// http.Client of the client.
+func WithHTTP2(fallback bool) Option {
+	return func(c *Client) {
+		var p http.Protocols
+		p.SetHTTP2(true)
+		p.SetUnencryptedHTTP2(true)
+		var rt http.RoundTripper = newTransport(p)
+		if fallback {
+			var p1 http.Protocols
+			p1.SetHTTP1(true)
+			rt = &fallbackTransport{h2: rt, h1: newTransport(p1), hosts: map[string]bool{}}
+		}
+		hc := *c.HttpClient
+		hc.Transport = rt
+		c.HttpClient = &hc
+	}
+}
+
+// newTransport returns a copy of the default transport speaking p.
+func newTransport(p http.Protocols) *http.Transport {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if ok {
+		t = t.Clone()
+	} else {
+		t = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	t.Protocols = &p
+	return t
+}
+
+// fallbackTransport makes requests with HTTP/2, falling back to HTTP/1.1
+// for hosts which fail.
+type fallbackTransport struct {
+	h2, h1 http.RoundTripper
+
+	mu    sync.Mutex
+	hosts map[string]bool // hosts speaking HTTP/1.1 only
+}
+
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	h1 := t.hosts[req.URL.Host]
+	t.mu.Unlock()
+	if h1 {
+		return t.h1.RoundTrip(req)
+	}
+	var wrote atomic.Bool
+	ctx := httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		WroteHeaders: func() { wrote.Store(true) },
+	})
+	r, err := t.h2.RoundTrip(req.WithContext(ctx))
+	if err == nil || req.Context().Err() != nil || req.Body != nil && req.GetBody == nil {
+		return r, err
+	}
+	// Only fall back if the server can't have seen the request, as it may
+	// not be idempotent: nothing was sent, or the server answered the
+	// preface of HTTP/2 with HTTP/1, rejecting the connection.
+	if wrote.Load() && !strings.Contains(err.Error(), "looked like an HTTP/1.1 header") {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	r, err1 := t.h1.RoundTrip(req)
+	if err1 != nil {
+		// The failure wasn't about the protocol.
+		return nil, err
+	}
+	t.mu.Lock()
+	t.hosts[req.URL.Host] = true
+	t.mu.Unlock()
+	return r, nil
+}
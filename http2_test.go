@@ -0,0 +1,173 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHTTP2(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("proto", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		return p.RemoteAddr, nil
+	})
+	var mu sync.Mutex
+	protos := map[string]bool{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// HTTP/1.1 servers see the preface of HTTP/2 as a PRI request.
+		if r.Method == "POST" {
+			mu.Lock()
+			protos[r.Proto] = true
+			mu.Unlock()
+		}
+		s.ServeHTTP(w, r)
+	})
+
+	// h2c with prior knowledge, sharing a connection.
+	ts := httptest.NewUnstartedServer(handler)
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+	c := NewClient(ts.URL, WithHTTP2(false))
+	var wg sync.WaitGroup
+	addrs := make([]interface{}, 5)
+	for i := range addrs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := c.Call("proto")
+			if err != nil {
+				t.Error(err)
+			}
+			addrs[i] = v
+		}(i)
+	}
+	wg.Wait()
+	for _, a := range addrs {
+		if a != addrs[0] {
+			t.Fatalf("want calls sharing a connection but got %v", addrs)
+		}
+	}
+	if !protos["HTTP/2.0"] || len(protos) != 1 {
+		t.Fatalf("want HTTP/2.0 only but got %v", protos)
+	}
+
+	// HTTP/2 over TLS.
+	protos = map[string]bool{}
+	tls := httptest.NewUnstartedServer(handler)
+	tls.EnableHTTP2 = true
+	tls.StartTLS()
+	defer tls.Close()
+	c = NewClient(tls.URL, WithHTTP2(false))
+	c.HttpClient.Transport.(*http.Transport).TLSClientConfig = tls.Client().Transport.(*http.Transport).TLSClientConfig
+	if _, err := c.Call("proto"); err != nil {
+		t.Fatal(err)
+	}
+	if !protos["HTTP/2.0"] {
+		t.Fatalf("want HTTP/2.0 but got %v", protos)
+	}
+
+	// Fallback to HTTP/1.1.
+	protos = map[string]bool{}
+	h1 := httptest.NewServer(handler)
+	defer h1.Close()
+	if _, err := NewClient(h1.URL, WithHTTP2(false)).Call("proto"); err == nil {
+		t.Fatal("want error without fallback")
+	}
+	c = NewClient(h1.URL, WithHTTP2(true))
+	for i := 0; i < 2; i++ {
+		if _, err := c.Call("proto"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !protos["HTTP/1.1"] || len(protos) != 1 {
+		t.Fatalf("want HTTP/1.1 but got %v", protos)
+	}
+}
+
+type protoTransport struct {
+	base  http.RoundTripper
+	proto string
+}
+
+func (t *protoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.proto = r.Proto
+	}
+	return r, err
+}
+
+func TestWithTransport(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	rt := &protoTransport{base: http.DefaultTransport}
+	if _, err := NewClient(ts.URL, WithTransport(rt)).Call("system.listMethods"); err != nil {
+		t.Fatal(err)
+	}
+	if rt.proto != "HTTP/1.1" {
+		t.Fatalf("want call through transport but got %q", rt.proto)
+	}
+
+	hc := &http.Client{}
+	c := NewClient(ts.URL)
+	c.SetHTTPClient(hc)
+	WithTransport(rt)(c)
+	if hc.Transport != nil {
+		t.Fatal("want http.Client of the caller unchanged")
+	}
+	rt.proto = ""
+	if _, err := c.Call("system.listMethods"); err != nil || rt.proto == "" {
+		t.Fatalf("want call through transport but got %v", err)
+	}
+}
+
+func TestHTTP2FallbackOnlyUnseen(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Proto)
+		mu.Unlock()
+		if r.ProtoMajor == 2 {
+			// Fail after the request was seen.
+			panic(http.ErrAbortHandler)
+		}
+		NewServer().ServeHTTP(w, r)
+	}))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.Start()
+	defer ts.Close()
+
+	if _, err := NewClient(ts.URL, WithHTTP2(true)).Call("system.listMethods"); err == nil {
+		t.Fatal("want error of HTTP/2")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 1 {
+		t.Fatalf("want call made once but got %v", calls)
+	}
+}
+
+func TestWithHTTP2Shared(t *testing.T) {
+	hc := &http.Client{}
+	c := NewClient("http://localhost/")
+	c.SetHTTPClient(hc)
+	WithHTTP2(true)(c)
+	if hc.Transport != nil {
+		t.Fatal("want http.Client of the caller unchanged")
+	}
+	if _, ok := c.HttpClient.Transport.(*fallbackTransport); !ok {
+		t.Fatalf("want HTTP/2 transport but got %T", c.HttpClient.Transport)
+	}
+}
@@ -0,0 +1,89 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Indent reads the XML-RPC payload from src and writes it to dst with each
+// element on its own line, indented by prefix and one copy of indent per
+// nesting level. Character data of leaf elements such as <string> is written
+// unchanged, so the result decodes to the same values as the input.
+func Indent(dst io.Writer, src io.Reader, prefix, indent string) error {
+	return indentXML(dst, src, prefix, indent, xmlEscape)
+}
+
+func indentXML(dst io.Writer, src io.Reader, prefix, indent string, escape func(string) string) error {
+	p := xml.NewDecoder(src)
+	p.CharsetReader = defaultCharsetReader
+	w := bufio.NewWriter(dst)
+
+	var text []byte
+	depth := 0
+	first := true
+	leaf := false // the last token was a start element
+	line := func() {
+		// Character data between elements is only kept if it is more
+		// than formatting.
+		if strings.TrimSpace(string(text)) != "" {
+			w.WriteString(escape(string(text)))
+		}
+		if !first {
+			w.WriteString("\n")
+		}
+		first = false
+		w.WriteString(prefix + strings.Repeat(indent, depth))
+	}
+	for {
+		t, err := p.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			text = append(text, t...)
+			continue
+		case xml.StartElement:
+			line()
+			w.WriteString("<" + qname(t.Name))
+			for _, a := range t.Attr {
+				w.WriteString(" " + qname(a.Name) + `="` + escape(a.Value) + `"`)
+			}
+			w.WriteString(">")
+			depth++
+		case xml.EndElement:
+			depth--
+			if leaf {
+				w.WriteString(escape(string(text)))
+			} else {
+				line()
+			}
+			w.WriteString("</" + qname(t.Name) + ">")
+		case xml.ProcInst:
+			line()
+			w.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+		case xml.Comment:
+			line()
+			w.WriteString("<!--" + string(t) + "-->")
+		case xml.Directive:
+			line()
+			w.WriteString("<!" + string(t) + ">")
+		}
+		_, leaf = t.(xml.StartElement)
+		text = text[:0]
+	}
+	w.WriteString("\n")
+	return w.Flush()
+}
+
+func qname(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
@@ -0,0 +1,49 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIndent(t *testing.T) {
+	var buf bytes.Buffer
+	err := Indent(&buf, strings.NewReader(`<?xml version="1.0"?><methodResponse><params><param><value><struct><member><name>a</name><value><string>  x &amp; y  </string></value></member><member><name>b</name><value>untyped</value></member></struct></value></param></params></methodResponse>`), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0"?>
+<methodResponse>
+  <params>
+    <param>
+      <value>
+        <struct>
+          <member>
+            <name>a</name>
+            <value>
+              <string>  x &amp; y  </string>
+            </value>
+          </member>
+          <member>
+            <name>b</name>
+            <value>untyped</value>
+          </member>
+        </struct>
+      </value>
+    </param>
+  </params>
+</methodResponse>
+`
+	if buf.String() != want {
+		t.Fatalf("want:\n%s\nbut got:\n%s", want, buf.String())
+	}
+}
+
+func TestIndentRequest(t *testing.T) {
+	e := &encoder{indent: "\t"}
+	b := mustRequest(t, e, "f", Struct{"a": []interface{}{1, " x "}})
+	want := "<?xml version=\"1.0\"?>\n<methodCall>\n\t<methodName>f</methodName>\n\t<params>\n\t\t<param>\n\t\t\t<value>\n\t\t\t\t<struct>\n\t\t\t\t\t<member>\n\t\t\t\t\t\t<name>a</name>\n\t\t\t\t\t\t<value>\n\t\t\t\t\t\t\t<array>\n\t\t\t\t\t\t\t\t<data>\n\t\t\t\t\t\t\t\t\t<value>\n\t\t\t\t\t\t\t\t\t\t<int>1</int>\n\t\t\t\t\t\t\t\t\t</value>\n\t\t\t\t\t\t\t\t\t<value>\n\t\t\t\t\t\t\t\t\t\t<string> x </string>\n\t\t\t\t\t\t\t\t\t</value>\n\t\t\t\t\t\t\t\t</data>\n\t\t\t\t\t\t\t</array>\n\t\t\t\t\t\t</value>\n\t\t\t\t\t</member>\n\t\t\t\t</struct>\n\t\t\t</value>\n\t\t</param>\n\t</params>\n</methodCall>\n"
+	if string(b) != want {
+		t.Fatalf("want:\n%s\nbut got:\n%s", want, b)
+	}
+}
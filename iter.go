@@ -0,0 +1,52 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"sort"
+)
+
+// Values returns an iterator over the elements of a.
+func (a Array) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, v := range a {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Members returns an iterator over the members of s in the order of their
+// names.
+func (s Struct) Members() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		names := make([]string, 0, len(s))
+		for name := range s {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !yield(name, s[name]) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesAs returns an iterator over the elements of a converted to T as
+// Unmarshal does, each paired with the error of its conversion:
+//
+//	for post, err := range xmlrpc.ValuesAs[Post](posts) {
+func ValuesAs[T any](a Array) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for i, v := range a {
+			var t T
+			err := unmarshal(v, reflect.ValueOf(&t).Elem(), fmt.Sprintf("[%d]", i))
+			if !yield(t, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArrayValues(t *testing.T) {
+	var got []interface{}
+	for v := range (Array{1, "a", true}).Values() {
+		got = append(got, v)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(got, []interface{}{1, "a"}) {
+		t.Fatalf("unexpected values: %v", got)
+	}
+}
+
+func TestStructMembers(t *testing.T) {
+	var names []string
+	var values []interface{}
+	for name, v := range (Struct{"b": 2, "a": 1, "c": 3}).Members() {
+		names = append(names, name)
+		values = append(values, v)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b", "c"}) || !reflect.DeepEqual(values, []interface{}{1, 2, 3}) {
+		t.Fatalf("unexpected members: %v %v", names, values)
+	}
+}
+
+func TestValuesAs(t *testing.T) {
+	type post struct {
+		Title string `xmlrpc:"title"`
+	}
+	var titles []string
+	var errs int
+	for p, err := range ValuesAs[post](Array{Struct{"title": "a"}, "bad", Struct{"title": "b"}}) {
+		if err != nil {
+			errs++
+			continue
+		}
+		titles = append(titles, p.Title)
+	}
+	if !reflect.DeepEqual(titles, []string{"a", "b"}) || errs != 1 {
+		t.Fatalf("unexpected titles %v with %d errors", titles, errs)
+	}
+}
@@ -0,0 +1,118 @@
+// Package jira is a client for the legacy XML-RPC API of Jira Server,
+// served at /rpc/xmlrpc, with the methods of its jira1 namespace.
+package jira
+
+import (
+	"context"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a Jira. It calls them anonymously until it
+// logs in.
+type Client struct {
+	c     *xmlrpc.Client
+	token string
+}
+
+// New returns a Client of the Jira at url, e.g.
+// https://jira.example.com/rpc/xmlrpc.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap. They take the token of the login as first argument.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Token returns the token of the login, or "" if not logged in.
+func (c *Client) Token() string {
+	return c.token
+}
+
+// call calls the method jira1.name with the token and args, and stores
+// the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, "jira1."+name, append([]interface{}{c.token}, args...)...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Login logs in as username. The token it returns is sent with later
+// calls until Logout.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	v, err := c.c.CallContext(ctx, "jira1.login", username, password)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, &c.token)
+}
+
+// Logout logs out.
+func (c *Client) Logout(ctx context.Context) error {
+	var ok bool
+	err := c.call(ctx, &ok, "logout")
+	c.token = ""
+	return err
+}
+
+// Issue is an issue. The API sends all its values as strings; Type,
+// Status, Priority and Resolution are IDs, and the dates are formatted
+// like "2006-01-02 15:04:05.0".
+type Issue struct {
+	ID          string `xmlrpc:"id"`
+	Key         string `xmlrpc:"key"`
+	Project     string `xmlrpc:"project"`
+	Summary     string `xmlrpc:"summary"`
+	Description string `xmlrpc:"description"`
+	Environment string `xmlrpc:"environment"`
+	Type        string `xmlrpc:"type"`
+	Status      string `xmlrpc:"status"`
+	Priority    string `xmlrpc:"priority"`
+	Resolution  string `xmlrpc:"resolution"`
+	Assignee    string `xmlrpc:"assignee"`
+	Reporter    string `xmlrpc:"reporter"`
+	Created     string `xmlrpc:"created"`
+	Updated     string `xmlrpc:"updated"`
+	DueDate     string `xmlrpc:"duedate"`
+	Votes       string `xmlrpc:"votes"`
+
+	Components      []Version     `xmlrpc:"components"`
+	AffectsVersions []Version     `xmlrpc:"affectsVersions"`
+	FixVersions     []Version     `xmlrpc:"fixVersions"`
+	CustomFields    []CustomField `xmlrpc:"customFieldValues"`
+}
+
+// Version is a version or component an issue refers to.
+type Version struct {
+	ID   string `xmlrpc:"id"`
+	Name string `xmlrpc:"name"`
+}
+
+// CustomField is the value of a custom field of an issue.
+type CustomField struct {
+	ID     string   `xmlrpc:"customfieldId"`
+	Values []string `xmlrpc:"values"`
+}
+
+// GetIssue returns the issue key, e.g. "PROJ-123".
+func (c *Client) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var i Issue
+	if err := c.call(ctx, &i, "getIssue", key); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// GetIssuesFromJQL returns at most max issues matching the JQL query jql.
+func (c *Client) GetIssuesFromJQL(ctx context.Context, jql string, max int) ([]Issue, error) {
+	var r []Issue
+	if err := c.call(ctx, &r, "getIssuesFromJqlSearch", jql, max); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
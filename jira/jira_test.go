@@ -0,0 +1,54 @@
+package jira
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestIssues(t *testing.T) {
+	issue := xmlrpc.Struct{
+		"id": "10001", "key": "PROJ-1", "project": "PROJ", "summary": "Broken",
+		"type": "1", "status": "3", "created": "2024-01-02 03:04:05.0",
+		"fixVersions":       xmlrpc.Array{xmlrpc.Struct{"id": "100", "name": "1.0", "released": "false"}},
+		"customFieldValues": xmlrpc.Array{xmlrpc.Struct{"customfieldId": "customfield_10000", "values": xmlrpc.Array{"x"}}},
+	}
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"jira1.login":                  xmlrpctest.Value("tok"),
+		"jira1.getIssue":               xmlrpctest.Value(issue),
+		"jira1.getIssuesFromJqlSearch": xmlrpctest.Value(xmlrpc.Array{issue}),
+	})
+	defer s.Close()
+	c := New(s.URL)
+	ctx := context.Background()
+
+	if err := c.Login(ctx, "admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	i, err := c.GetIssue(ctx, "PROJ-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "jira1.getIssue", "tok", "PROJ-1")
+	if i.Key != "PROJ-1" || i.Summary != "Broken" || i.Status != "3" {
+		t.Fatalf("unexpected issue %+v", i)
+	}
+	if want := []Version{{"100", "1.0"}}; !reflect.DeepEqual(i.FixVersions, want) {
+		t.Fatalf("want %v but got %v", want, i.FixVersions)
+	}
+	if want := []CustomField{{"customfield_10000", []string{"x"}}}; !reflect.DeepEqual(i.CustomFields, want) {
+		t.Fatalf("want %v but got %v", want, i.CustomFields)
+	}
+
+	issues, err := c.GetIssuesFromJQL(ctx, "project = PROJ", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "PROJ-1" {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	s.AssertCalled(t, "jira1.getIssuesFromJqlSearch", "tok", "project = PROJ", 10)
+}
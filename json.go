@@ -0,0 +1,93 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ToJSON converts v, a value as returned by Call, to JSON. Structs become
+// objects, arrays become arrays, base64 values become base64 encoded
+// strings and dateTime values become RFC 3339 strings.
+func ToJSON(v interface{}) ([]byte, error) {
+	j, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, bool, int, int32, int64, float64, string:
+		return v, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case Array:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			j, err := toJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = j
+		}
+		return a, nil
+	case Struct:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			j, err := toJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = j
+		}
+		return m, nil
+	case RawValue:
+		return v.XML, nil
+	}
+	return nil, fmt.Errorf("xmlrpc: cannot convert %T to JSON", v)
+}
+
+// FromJSON converts JSON to a value which can be passed to Call. Objects
+// become Struct, arrays become Array, integral numbers which fit in an int
+// become int and other numbers float64. Strings stay strings; base64 and
+// dateTime values can't be told apart from them.
+func FromJSON(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var j interface{}
+	if err := d.Decode(&j); err != nil {
+		return nil, err
+	}
+	return fromJSONValue(j), nil
+}
+
+func fromJSONValue(j interface{}) interface{} {
+	switch j := j.(type) {
+	case json.Number:
+		if i, err := j.Int64(); err == nil && i >= math.MinInt32 && i <= math.MaxInt32 {
+			return int(i)
+		}
+		f, _ := j.Float64()
+		return f
+	case []interface{}:
+		a := make(Array, len(j))
+		for i, e := range j {
+			a[i] = fromJSONValue(e)
+		}
+		return a
+	case map[string]interface{}:
+		st := make(Struct, len(j))
+		for k, e := range j {
+			st[k] = fromJSONValue(e)
+		}
+		return st
+	}
+	return j
+}
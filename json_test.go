@@ -0,0 +1,38 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToJSON(t *testing.T) {
+	v := Struct{
+		"a": Array{1, 2.5, "x", true, nil},
+		"b": []byte("hello"),
+		"c": time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC),
+		"d": Array{int32(7), int64(3000000000)},
+	}
+	b, err := ToJSON(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":[1,2.5,"x",true,null],"b":"aGVsbG8=","c":"1998-07-17T14:08:55Z","d":[7,3000000000]}`
+	if string(b) != want {
+		t.Fatalf("want %s but got %s", want, b)
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	v, err := FromJSON([]byte(`{"a":[1,2.5,"x",true,null],"b":{"c":10000000000}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Struct{
+		"a": Array{1, 2.5, "x", true, nil},
+		"b": Struct{"c": 1e10},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("want %v but got %v", want, v)
+	}
+}
@@ -0,0 +1,210 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// JSONRPCHandler returns an http.Handler which accepts JSON-RPC 2.0 requests,
+// including batches, and dispatches them to the methods registered with s.
+// Positional params are passed as arguments, named params as a single Struct
+// argument. Values are converted as by FromJSON and ToJSON, and faults are
+// reported as errors with the fault code. JSON-RPC shares the codes of
+// ParseError, InvalidRequest, MethodNotFound, InvalidParams and
+// InternalError with XML-RPC.
+func (s *Server) JSONRPCHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := peerContext(r)
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			writeJSON(w, jsonrpcResponse{JSONRPC: "2.0", Error: &jsonrpcError{ParseError, err.Error()}, ID: json.RawMessage("null")})
+			return
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '[' {
+			var reqs []jsonrpcRequest
+			if err := json.Unmarshal(raw, &reqs); err != nil || len(reqs) == 0 {
+				writeJSON(w, jsonrpcResponse{JSONRPC: "2.0", Error: &jsonrpcError{InvalidRequest, "invalid batch"}, ID: json.RawMessage("null")})
+				return
+			}
+			var res []jsonrpcResponse
+			for _, req := range reqs {
+				if out, ok := s.serveJSONRPC(ctx, req); ok {
+					res = append(res, out)
+				}
+			}
+			if len(res) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			writeJSON(w, res)
+			return
+		}
+		var req jsonrpcRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			writeJSON(w, jsonrpcResponse{JSONRPC: "2.0", Error: &jsonrpcError{InvalidRequest, err.Error()}, ID: json.RawMessage("null")})
+			return
+		}
+		if res, ok := s.serveJSONRPC(ctx, req); ok {
+			writeJSON(w, res)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// serveJSONRPC handles a single request. It returns false for notifications,
+// which get no response.
+func (s *Server) serveJSONRPC(ctx context.Context, req jsonrpcRequest) (jsonrpcResponse, bool) {
+	res := jsonrpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if res.ID == nil {
+		res.ID = json.RawMessage("null")
+	}
+	fail := func(code int, msg string) (jsonrpcResponse, bool) {
+		res.Error = &jsonrpcError{code, msg}
+		return res, req.ID != nil
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return fail(InvalidRequest, "invalid request")
+	}
+
+	var args []interface{}
+	if len(req.Params) > 0 {
+		p, err := FromJSON(req.Params)
+		if err != nil {
+			return fail(InvalidRequest, err.Error())
+		}
+		switch p := p.(type) {
+		case Array:
+			args = p
+		case Struct:
+			args = []interface{}{p}
+		default:
+			return fail(InvalidRequest, "params must be an array or an object")
+		}
+	}
+
+	v, err := s.dispatch(ctx, req.Method, args)
+	if err != nil {
+		if f, ok := err.(*Fault); ok {
+			return fail(f.Code, f.String)
+		}
+		return fail(InternalError, err.Error())
+	}
+	if res.Result, err = toJSONValue(v); err != nil {
+		return fail(InternalError, err.Error())
+	}
+	if res.Result == nil {
+		res.Result = json.RawMessage("null")
+	}
+	return res, req.ID != nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// NewJSONRPCGateway returns an http.Handler which serves XML-RPC calls by
+// forwarding them as JSON-RPC 2.0 requests to the server at url, using
+// client or http.DefaultClient if client is nil. JSON-RPC errors are
+// returned as faults with the error code, other responses with a status
+// other than 2xx as faults with code TransportError. The upstream call is
+// canceled if the caller disconnects.
+func NewJSONRPCGateway(url string, client *http.Client) http.Handler {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var id int64
+	var enc encoder
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		d := newDecoder(r.Body, decodeOptions{})
+		name, args, err := d.call()
+		if err != nil {
+			enc.writeFault(w, requestFault(d, err))
+			return
+		}
+		params, err := toJSONValue(Array(args))
+		if err != nil {
+			enc.writeFault(w, &Fault{Code: InvalidParams, String: err.Error()})
+			return
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  name,
+			"params":  params,
+			"id":      atomic.AddInt64(&id, 1),
+		})
+		if err != nil {
+			enc.writeFault(w, &Fault{Code: InternalError, String: err.Error()})
+			return
+		}
+
+		// The call upstream is canceled when the caller goes away.
+		req, err := http.NewRequestWithContext(r.Context(), "POST", url, bytes.NewReader(body))
+		if err != nil {
+			enc.writeFault(w, &Fault{Code: InternalError, String: err.Error()})
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		if err != nil {
+			enc.writeFault(w, &Fault{Code: TransportError, String: err.Error()})
+			return
+		}
+		defer res.Body.Close()
+		var out struct {
+			Result json.RawMessage `json:"result"`
+			Error  *jsonrpcError   `json:"error"`
+		}
+		if res.StatusCode/100 != 2 {
+			// Only trust the body if it is a JSON-RPC error.
+			if err := json.NewDecoder(res.Body).Decode(&out); err == nil && out.Error != nil {
+				enc.writeFault(w, &Fault{Code: out.Error.Code, String: out.Error.Message})
+			} else {
+				enc.writeFault(w, &Fault{Code: TransportError, String: "JSON-RPC server responded with status " + res.Status})
+			}
+			return
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			enc.writeFault(w, &Fault{Code: ParseError, String: fmt.Sprintf("invalid JSON-RPC response: %v", err)})
+			return
+		}
+		if out.Error != nil {
+			enc.writeFault(w, &Fault{Code: out.Error.Code, String: out.Error.Message})
+			return
+		}
+		var v interface{}
+		if len(out.Result) > 0 {
+			if v, err = FromJSON(out.Result); err != nil {
+				enc.writeFault(w, &Fault{Code: ParseError, String: err.Error()})
+				return
+			}
+		}
+		enc.writeResponse(w, v)
+	})
+}
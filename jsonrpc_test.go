@@ -0,0 +1,137 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newJSONRPCServer() *Server {
+	s := NewServer()
+	s.Register("add", func(args ...interface{}) (interface{}, error) {
+		return args[0].(int) + args[1].(int), nil
+	})
+	s.Register("name", func(args ...interface{}) (interface{}, error) {
+		return args[0].(Struct)["name"], nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 4, String: "too many params"}
+	})
+	s.Register("broken", func(args ...interface{}) (interface{}, error) {
+		return nil, errors.New("broken")
+	})
+	return s
+}
+
+func TestJSONRPCHandler(t *testing.T) {
+	ts := httptest.NewServer(newJSONRPCServer().JSONRPCHandler())
+	defer ts.Close()
+
+	tests := []struct {
+		req  string
+		want string
+	}{
+		{`{"jsonrpc":"2.0","method":"add","params":[2,3],"id":1}`, `{"jsonrpc":"2.0","result":5,"id":1}`},
+		{`{"jsonrpc":"2.0","method":"name","params":{"name":"go"},"id":"a"}`, `{"jsonrpc":"2.0","result":"go","id":"a"}`},
+		{`{"jsonrpc":"2.0","method":"fail","id":2}`, `{"jsonrpc":"2.0","error":{"code":4,"message":"too many params"},"id":2}`},
+		{`{"jsonrpc":"2.0","method":"broken","id":3}`, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"broken"},"id":3}`},
+		{`{"jsonrpc":"2.0","method":"nope","id":4}`, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found: nope"},"id":4}`},
+		{`{"jsonrpc":"1.0","method":"add","id":5}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":5}`},
+		{`{"jsonrpc":`, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"unexpected EOF"},"id":null}`},
+		{`[{"jsonrpc":"2.0","method":"add","params":[1,1],"id":1},{"jsonrpc":"2.0","method":"add","params":[1,2]}]`, `[{"jsonrpc":"2.0","result":2,"id":1}]`},
+		{`{"jsonrpc":"2.0","method":"add","params":[1,2]}`, ``},
+	}
+	for _, test := range tests {
+		res, err := http.Post(ts.URL, "application/json", strings.NewReader(test.req))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(b)); got != test.want {
+			t.Errorf("%s: want %s but got %s", test.req, test.want, got)
+		}
+	}
+}
+
+func TestJSONRPCGateway(t *testing.T) {
+	upstream := httptest.NewServer(newJSONRPCServer().JSONRPCHandler())
+	defer upstream.Close()
+	ts := httptest.NewServer(NewJSONRPCGateway(upstream.URL, nil))
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	v, err := c.Call("add", 40, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 {
+		t.Fatalf("want 42 but got %v", v)
+	}
+	v, err = c.Call("name", Struct{"name": "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "go" {
+		t.Fatalf("want go but got %v", v)
+	}
+
+	_, err = c.Call("fail")
+	f, ok := err.(*Fault)
+	if !ok || f.Code != 4 || f.String != "too many params" {
+		t.Fatalf("want fault 4 but got %v", err)
+	}
+	_, err = c.Call("nope")
+	if f, ok := err.(*Fault); !ok || f.Code != -32601 {
+		t.Fatalf("want fault -32601 but got %v", err)
+	}
+}
+
+func TestJSONRPCGatewayUpstream(t *testing.T) {
+	canceled := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/down":
+			http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32000,"message":"boom"},"id":1}`))
+		case "/slow":
+			io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+			close(canceled)
+		}
+	}))
+	defer upstream.Close()
+
+	for path, want := range map[string]int{"/down": TransportError, "/error": -32000} {
+		ts := httptest.NewServer(NewJSONRPCGateway(upstream.URL+path, nil))
+		_, err := NewClient(ts.URL).Call("f")
+		ts.Close()
+		if f, ok := err.(*Fault); !ok || f.Code != want {
+			t.Fatalf("%s: want fault %d but got %v", path, want, err)
+		}
+	}
+
+	ts := httptest.NewServer(NewJSONRPCGateway(upstream.URL+"/slow", nil))
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := NewClient(ts.URL).CallContext(ctx, "f"); err == nil {
+		t.Fatal("want error of canceled call")
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("want upstream call canceled with the call")
+	}
+}
@@ -0,0 +1,202 @@
+// Package koji is a client for the XML-RPC API of the hub of the Koji
+// build system, e.g. https://koji.fedoraproject.org/kojihub.
+package koji
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a Koji hub. Reading methods need no
+// authentication; others need a login.
+type Client struct {
+	c       *xmlrpc.Client
+	session *sessionTransport
+}
+
+// New returns a Client of the Koji hub at url.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	return newClient(url, nil, opts)
+}
+
+// NewSSL returns a Client of the Koji hub at url which presents the
+// client certificate of config, for SSLLogin.
+func NewSSL(url string, config *tls.Config, opts ...xmlrpc.Option) *Client {
+	return newClient(url, &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: config,
+	}, opts)
+}
+
+// newClient returns a Client of the Koji hub at url using the transport
+// base, or the default one if nil.
+func newClient(url string, base http.RoundTripper, opts []xmlrpc.Option) *Client {
+	c := xmlrpc.NewClient(url, opts...)
+	if base == nil {
+		base = c.HttpClient.Transport
+	}
+	session := &sessionTransport{base: base}
+	c.HttpClient.Transport = session
+	return &Client{c: c, session: session}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap. Its calls carry the session of the login.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// sessionTransport adds the session of the login to the query of
+// requests, where the hub expects it.
+type sessionTransport struct {
+	base http.RoundTripper
+
+	mu  sync.Mutex
+	id  int
+	key string
+}
+
+func (t *sessionTransport) set(id int, key string) {
+	t.mu.Lock()
+	t.id, t.key = id, key
+	t.mu.Unlock()
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	id, key := t.id, t.key
+	t.mu.Unlock()
+	if key == "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	q := req.URL.Query()
+	q.Set("session-id", strconv.Itoa(id))
+	q.Set("session-key", key)
+	req.URL.RawQuery = q.Encode()
+	return t.base.RoundTrip(req)
+}
+
+// call calls the method name with args and stores the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// SSLLogin logs in with the client certificate of a Client made by
+// NewSSL. Later calls carry the session until Logout.
+func (c *Client) SSLLogin(ctx context.Context) error {
+	var r struct {
+		ID  int    `xmlrpc:"session-id"`
+		Key string `xmlrpc:"session-key"`
+	}
+	if err := c.call(ctx, &r, "sslLogin"); err != nil {
+		return err
+	}
+	c.session.set(r.ID, r.Key)
+	return nil
+}
+
+// Logout ends the session.
+func (c *Client) Logout(ctx context.Context) error {
+	_, err := c.c.CallContext(ctx, "logout")
+	c.session.set(0, "")
+	return err
+}
+
+// kwargs returns the keyword arguments kw in the form the hub accepts
+// as last argument.
+func kwargs(kw xmlrpc.Struct) xmlrpc.Struct {
+	kw["__starstar"] = true
+	return kw
+}
+
+// States of builds.
+const (
+	Building = 0
+	Complete = 1
+	Deleted  = 2
+	Failed   = 3
+	Canceled = 4
+)
+
+// Build is a build. Koji sends nil for values which aren't set, such as
+// the epoch of most packages, which leaves them zero.
+type Build struct {
+	ID           int     `xmlrpc:"build_id"`
+	PackageID    int     `xmlrpc:"package_id"`
+	PackageName  string  `xmlrpc:"package_name"`
+	Name         string  `xmlrpc:"name"`
+	Version      string  `xmlrpc:"version"`
+	Release      string  `xmlrpc:"release"`
+	Epoch        int     `xmlrpc:"epoch"`
+	NVR          string  `xmlrpc:"nvr"`
+	State        int     `xmlrpc:"state"`
+	TaskID       int     `xmlrpc:"task_id"`
+	OwnerName    string  `xmlrpc:"owner_name"`
+	CreationTS   float64 `xmlrpc:"creation_ts"` // Unix time
+	CompletionTS float64 `xmlrpc:"completion_ts"`
+	TagName      string  `xmlrpc:"tag_name"` // only set by ListTagged
+	VolumeName   string  `xmlrpc:"volume_name"`
+}
+
+// GetBuild returns the build of the NVR or ID build, or nil if there is
+// none.
+func (c *Client) GetBuild(ctx context.Context, build interface{}) (*Build, error) {
+	v, err := c.c.CallContext(ctx, "getBuild", build)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var b Build
+	if err := xmlrpc.Unmarshal(v, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// TaggedOptions select the builds ListTagged returns. Zero values don't
+// restrict them.
+type TaggedOptions struct {
+	Package string
+	Owner   string
+	Inherit bool // include builds of inherited tags
+	Latest  bool // only the latest build of each package
+}
+
+// ListTagged returns the builds tagged with tag.
+func (c *Client) ListTagged(ctx context.Context, tag string, opts TaggedOptions) ([]Build, error) {
+	kw := xmlrpc.Struct{}
+	if opts.Package != "" {
+		kw["package"] = opts.Package
+	}
+	if opts.Owner != "" {
+		kw["owner"] = opts.Owner
+	}
+	if opts.Inherit {
+		kw["inherit"] = true
+	}
+	if opts.Latest {
+		kw["latest"] = true
+	}
+	var r []Build
+	if err := c.call(ctx, &r, "listTagged", tag, kwargs(kw)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TagBuild tags the NVR or ID build with tag, returning the ID of the
+// task doing so.
+func (c *Client) TagBuild(ctx context.Context, tag string, build interface{}) (int, error) {
+	var id int
+	err := c.call(ctx, &id, "tagBuild", tag, build)
+	return id, err
+}
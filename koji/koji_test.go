@@ -0,0 +1,137 @@
+package koji
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func clientCert(t *testing.T, cn string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSSLLogin(t *testing.T) {
+	s := xmlrpc.NewServer()
+	var user string
+	s.RegisterContext("sslLogin", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		return xmlrpc.Struct{"session-id": 12, "session-key": "12-abc"}, nil
+	})
+	s.RegisterContext("tagBuild", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := xmlrpc.PeerFromContext(ctx)
+		if p.Header.Get("X-Session") != "12 12-abc" {
+			return nil, &xmlrpc.Fault{Code: 1000, String: "not logged in"}
+		}
+		return 99, nil
+	})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.TLS.PeerCertificates[0].Subject.CommonName
+		// Expose the session of the query to the handlers.
+		if q := r.URL.Query(); q.Get("session-key") != "" {
+			r.Header.Set("X-Session", q.Get("session-id")+" "+q.Get("session-key"))
+		}
+		s.ServeHTTP(w, r)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	c := NewSSL(ts.URL, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientCert(t, "builder")}})
+	ctx := context.Background()
+
+	if _, err := c.TagBuild(ctx, "f40", "foo-1.0-1"); err == nil {
+		t.Fatal("want fault before login")
+	}
+	if err := c.SSLLogin(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if user != "builder" {
+		t.Fatalf("want client certificate of builder but got %q", user)
+	}
+	id, err := c.TagBuild(ctx, "f40", "foo-1.0-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 99 {
+		t.Fatalf("want task 99 but got %d", id)
+	}
+}
+
+func TestBuilds(t *testing.T) {
+	build := xmlrpc.Struct{
+		"build_id": 1, "package_name": "foo", "name": "foo", "version": "1.0", "release": "1.fc40",
+		"epoch": nil, "nvr": "foo-1.0-1.fc40", "state": Complete, "task_id": nil, "owner_name": "alice",
+		"completion_ts": 1700000000.25,
+	}
+	s := xmlrpc.NewServer()
+	s.Register("getBuild", func(args ...interface{}) (interface{}, error) {
+		if args[0] == "foo-1.0-1.fc40" {
+			return build, nil
+		}
+		return nil, nil
+	})
+	var kw xmlrpc.Struct
+	s.Register("listTagged", func(args ...interface{}) (interface{}, error) {
+		kw = args[1].(xmlrpc.Struct)
+		b := xmlrpc.Struct{"tag_name": args[0]}
+		for k, v := range build {
+			b[k] = v
+		}
+		return xmlrpc.Array{b}, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := New(ts.URL)
+	ctx := context.Background()
+
+	b, err := c.GetBuild(ctx, "foo-1.0-1.fc40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.NVR != "foo-1.0-1.fc40" || b.Epoch != 0 || b.TaskID != 0 || b.State != Complete || b.CompletionTS != 1700000000.25 {
+		t.Fatalf("unexpected build %+v", b)
+	}
+	if b, err := c.GetBuild(ctx, "missing"); err != nil || b != nil {
+		t.Fatalf("want no build but got %+v, %v", b, err)
+	}
+
+	builds, err := c.ListTagged(ctx, "f40", TaggedOptions{Package: "foo", Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 1 || builds[0].TagName != "f40" {
+		t.Fatalf("unexpected builds %+v", builds)
+	}
+	want := xmlrpc.Struct{"__starstar": true, "package": "foo", "latest": true}
+	if !reflect.DeepEqual(kw, want) {
+		t.Fatalf("want kwargs %v but got %v", want, kw)
+	}
+}
@@ -0,0 +1,136 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+)
+
+// LimitPolicy decides what happens to calls beyond a concurrency limit.
+type LimitPolicy int
+
+const (
+	// QueueWhenLimited makes calls wait for a running call to finish, or
+	// for their request to be canceled.
+	QueueWhenLimited LimitPolicy = iota
+
+	// RejectWhenLimited answers calls with a fault with code SystemError,
+	// or fails them with ErrTooManyCalls on a Client.
+	RejectWhenLimited
+)
+
+// ErrTooManyCalls is returned by calls of a Client beyond the limit of
+// WithMaxConcurrentCalls with RejectWhenLimited.
+var ErrTooManyCalls = errors.New("xmlrpc: too many concurrent calls")
+
+// clientLimit is the configuration of WithMaxConcurrentCalls.
+type clientLimit struct {
+	sem    chan struct{}
+	policy LimitPolicy
+}
+
+// WithMaxConcurrentCalls limits the number of requests the client makes at
+// the same time to n, handling the calls beyond it according to policy.
+// Each attempt of a call takes a slot, which is not held while waiting to
+// retry.
+func WithMaxConcurrentCalls(n int, policy LimitPolicy) Option {
+	return func(c *Client) {
+		c.limit = nil
+		if n > 0 {
+			c.limit = &clientLimit{sem: make(chan struct{}, n), policy: policy}
+		}
+	}
+}
+
+// acquire takes a slot, returning a function releasing it.
+func (l *clientLimit) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	release := func() { <-l.sem }
+	select {
+	case l.sem <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if l.policy == RejectWhenLimited {
+		return nil, ErrTooManyCalls
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WithConcurrencyLimit limits the number of handlers running at the same
+// time to n, handling the calls beyond it according to policy. The policy
+// applies to the limits of methods as well. See Server.SetConcurrencyLimit.
+func WithConcurrencyLimit(n int, policy LimitPolicy) ServerOption {
+	return func(s *Server) {
+		s.limitPolicy = policy
+		if n > 0 {
+			s.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// SetConcurrencyLimit limits the number of running handlers of the method
+// name to n, in addition to the limit of the server. Zero removes the
+// limit. It must not be called while the server is handling calls of the
+// method.
+func (s *Server) SetConcurrencyLimit(name string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sems == nil {
+		s.sems = map[string]chan struct{}{}
+	}
+	if n > 0 {
+		s.sems[name] = make(chan struct{}, n)
+	} else {
+		delete(s.sems, name)
+	}
+}
+
+// acquire takes a slot of the method name and of the server, returning a
+// function releasing them.
+func (s *Server) acquire(ctx context.Context, name string) (func(), error) {
+	s.mu.RLock()
+	sem := s.sems[name]
+	s.mu.RUnlock()
+
+	var taken []chan struct{}
+	release := func() {
+		for _, c := range taken {
+			<-c
+		}
+	}
+	for _, c := range []chan struct{}{sem, s.sem} {
+		if c == nil {
+			continue
+		}
+		if err := s.take(ctx, c, name); err != nil {
+			release()
+			return nil, err
+		}
+		taken = append(taken, c)
+	}
+	return release, nil
+}
+
+func (s *Server) take(ctx context.Context, c chan struct{}, name string) error {
+	select {
+	case c <- struct{}{}:
+		return nil
+	default:
+	}
+	if s.limitPolicy == RejectWhenLimited {
+		return &Fault{Code: SystemError, String: "server busy: " + name}
+	}
+	select {
+	case c <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimitReject(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	s := NewServer(WithConcurrencyLimit(1, RejectWhenLimited))
+	s.Register("block", func(args ...interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-unblock
+		return "ok", nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Call("block")
+		done <- err
+	}()
+	<-started
+	_, err := c.Call("block")
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != SystemError {
+		t.Fatalf("want SystemError fault but got %v", err)
+	}
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrencyLimitQueue(t *testing.T) {
+	var mu sync.Mutex
+	running, max := 0, 0
+	s := NewServer(WithConcurrencyLimit(10, QueueWhenLimited))
+	s.SetConcurrencyLimit("work", 2)
+	s.Register("work", func(args ...interface{}) (interface{}, error) {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Call("work"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if max != 2 {
+		t.Fatalf("want at most 2 running handlers but got %d", max)
+	}
+}
+
+func TestConcurrencyLimitCanceled(t *testing.T) {
+	s := NewServer(WithConcurrencyLimit(1, QueueWhenLimited))
+	release, err := s.acquire(context.Background(), "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx, "x"); err != context.DeadlineExceeded {
+		t.Fatalf("want deadline exceeded but got %v", err)
+	}
+}
+
+func TestMaxConcurrentCalls(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	release := make(chan struct{})
+	s := NewServer()
+	s.Register("slow", func(args ...interface{}) (interface{}, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithMaxConcurrentCalls(2, QueueWhenLimited))
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Call("slow"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if peak != 2 {
+		t.Fatalf("want at most 2 concurrent calls but got %d", peak)
+	}
+
+	release = make(chan struct{})
+	c = NewClient(ts.URL, WithMaxConcurrentCalls(1, RejectWhenLimited))
+	done := make(chan struct{})
+	go func() {
+		c.Call("slow")
+		close(done)
+	}()
+	for {
+		mu.Lock()
+		n := running
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := c.Call("slow"); !errors.Is(err, ErrTooManyCalls) {
+		t.Fatalf("want ErrTooManyCalls but got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	queued := NewClient(ts.URL, WithMaxConcurrentCalls(1, QueueWhenLimited))
+	queued.limit.sem <- struct{}{}
+	if _, err := queued.CallContext(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded but got %v", err)
+	}
+	close(release)
+	<-done
+}
@@ -0,0 +1,185 @@
+// Package livejournal is a client for the XML-RPC API of LiveJournal and
+// its forks such as Dreamwidth, served at /interface/xmlrpc, with the
+// methods of the LJ.XMLRPC namespace.
+package livejournal
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a LiveJournal as a user. Each call is
+// authenticated by a response to a fresh challenge, so the password is
+// never sent.
+type Client struct {
+	c        *xmlrpc.Client
+	username string
+	password string // MD5 hex digest
+}
+
+// New returns a Client of the LiveJournal at url, e.g.
+// https://www.livejournal.com/interface/xmlrpc, calling as username.
+func New(url, username, password string, opts ...xmlrpc.Option) *Client {
+	return &Client{
+		c:        xmlrpc.NewClient(url, opts...),
+		username: username,
+		password: md5hex(password),
+	}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+func md5hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Call calls the method LJ.XMLRPC.name with params, adding the user and
+// the response to a challenge, and stores the result in r.
+func (c *Client) Call(ctx context.Context, r interface{}, name string, params xmlrpc.Struct) error {
+	var ch struct {
+		Challenge string `xmlrpc:"challenge"`
+	}
+	v, err := c.c.CallContext(ctx, "LJ.XMLRPC.getchallenge")
+	if err != nil {
+		return err
+	}
+	if err := xmlrpc.Unmarshal(v, &ch); err != nil {
+		return err
+	}
+	params["username"] = c.username
+	params["auth_method"] = "challenge"
+	params["auth_challenge"] = ch.Challenge
+	params["auth_response"] = md5hex(ch.Challenge + c.password)
+	params["ver"] = 1 // UTF-8
+	if v, err = c.c.CallContext(ctx, "LJ.XMLRPC."+name, params); err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// User is the user logged in.
+type User struct {
+	ID       int    `xmlrpc:"userid"`
+	FullName string `xmlrpc:"fullname"`
+	Message  string `xmlrpc:"message"` // to show to the user, if any
+}
+
+// Login checks the credentials, returning the user.
+func (c *Client) Login(ctx context.Context) (*User, error) {
+	var r map[string]interface{}
+	if err := c.Call(ctx, &r, "login", xmlrpc.Struct{}); err != nil {
+		return nil, err
+	}
+	var u User
+	u.ID, _ = r["userid"].(int)
+	u.FullName = text(r["fullname"])
+	u.Message = text(r["message"])
+	return &u, nil
+}
+
+// text returns the string v, which LiveJournal sends as base64 if it isn't
+// ASCII.
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// Event is an entry of a journal.
+type Event struct {
+	ItemID   int
+	ANum     int
+	Time     time.Time // local to the journal
+	Subject  string
+	Event    string
+	URL      string
+	Security string // "public", "private" or "usemask"
+	Props    map[string]interface{}
+}
+
+// timeLayout is the layout of times of events.
+const timeLayout = "2006-01-02 15:04:05"
+
+// GetEvents returns the last n events of the journal of the user, or of
+// the community journal if not empty.
+func (c *Client) GetEvents(ctx context.Context, journal string, n int) ([]Event, error) {
+	params := xmlrpc.Struct{"selecttype": "lastn", "howmany": n, "lineendings": "unix"}
+	if journal != "" {
+		params["usejournal"] = journal
+	}
+	var r struct {
+		Events []map[string]interface{} `xmlrpc:"events"`
+	}
+	if err := c.Call(ctx, &r, "getevents", params); err != nil {
+		return nil, err
+	}
+	events := make([]Event, len(r.Events))
+	for i, e := range r.Events {
+		ev := &events[i]
+		ev.ItemID, _ = e["itemid"].(int)
+		ev.ANum, _ = e["anum"].(int)
+		ev.Time, _ = time.Parse(timeLayout, text(e["eventtime"]))
+		ev.Subject = text(e["subject"])
+		ev.Event = text(e["event"])
+		ev.URL = text(e["url"])
+		ev.Security = text(e["security"])
+		if ev.Security == "" {
+			ev.Security = "public"
+		}
+		if props, ok := e["props"].(xmlrpc.Struct); ok {
+			ev.Props = props
+		}
+	}
+	return events, nil
+}
+
+// PostEvent posts e to the journal of the user, or to the community
+// journal if not empty, returning it with its ItemID, ANum and URL set. A
+// zero Time is the current time.
+func (c *Client) PostEvent(ctx context.Context, journal string, e Event) (*Event, error) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	params := xmlrpc.Struct{
+		"event":       e.Event,
+		"subject":     e.Subject,
+		"lineendings": "unix",
+		"year":        e.Time.Year(),
+		"mon":         int(e.Time.Month()),
+		"day":         e.Time.Day(),
+		"hour":        e.Time.Hour(),
+		"min":         e.Time.Minute(),
+	}
+	if e.Security != "" {
+		params["security"] = e.Security
+	}
+	if e.Props != nil {
+		params["props"] = xmlrpc.Struct(e.Props)
+	}
+	if journal != "" {
+		params["usejournal"] = journal
+	}
+	var r struct {
+		ItemID int    `xmlrpc:"itemid"`
+		ANum   int    `xmlrpc:"anum"`
+		URL    string `xmlrpc:"url"`
+	}
+	if err := c.Call(ctx, &r, "postevent", params); err != nil {
+		return nil, err
+	}
+	e.ItemID, e.ANum, e.URL = r.ItemID, r.ANum, r.URL
+	return &e, nil
+}
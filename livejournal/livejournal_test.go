@@ -0,0 +1,78 @@
+package livejournal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestClient(t *testing.T) {
+	auth := func(h xmlrpc.HandlerFunc) xmlrpc.HandlerFunc {
+		return func(args ...interface{}) (interface{}, error) {
+			p := args[0].(xmlrpc.Struct)
+			if p["username"] != "alice" || p["auth_challenge"] != "c0:1:2:3" || p["auth_response"] != md5hex("c0:1:2:3"+md5hex("secret")) {
+				return nil, &xmlrpc.Fault{Code: 101, String: "Invalid password"}
+			}
+			return h(args...)
+		}
+	}
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"LJ.XMLRPC.getchallenge": xmlrpctest.Value(xmlrpc.Struct{"challenge": "c0:1:2:3", "auth_scheme": "c0"}),
+		"LJ.XMLRPC.login":        auth(xmlrpctest.Value(xmlrpc.Struct{"userid": 7, "fullname": []byte("Alice Ł")})),
+		"LJ.XMLRPC.getevents": auth(xmlrpctest.Value(xmlrpc.Struct{"events": xmlrpc.Array{
+			xmlrpc.Struct{"itemid": 5, "anum": 12, "eventtime": "2024-01-02 03:04:00", "subject": "Hi",
+				"event": []byte("héllo"), "url": "https://alice.example.com/1292.html", "props": xmlrpc.Struct{"current_mood": "happy"}},
+		}})),
+		"LJ.XMLRPC.postevent": auth(xmlrpctest.Value(xmlrpc.Struct{"itemid": 6, "anum": 34, "url": "https://alice.example.com/1570.html"})),
+	})
+	defer s.Close()
+	ctx := context.Background()
+
+	if _, err := New(s.URL, "alice", "wrong").Login(ctx); err == nil {
+		t.Fatal("want error for wrong password")
+	}
+	c := New(s.URL, "alice", "secret")
+	u, err := c.Login(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 7 || u.FullName != "Alice Ł" {
+		t.Fatalf("unexpected user %+v", u)
+	}
+
+	events, err := c.GetEvents(ctx, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("want 1 event but got %d", len(events))
+	}
+	e := events[0]
+	if e.ItemID != 5 || e.Event != "héllo" || e.Security != "public" || e.Props["current_mood"] != "happy" ||
+		!e.Time.Equal(time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	posted, err := c.PostEvent(ctx, "community", Event{
+		Subject: "New", Event: "text", Security: "private",
+		Time: time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted.ItemID != 6 || posted.URL != "https://alice.example.com/1570.html" {
+		t.Fatalf("unexpected event %+v", posted)
+	}
+	for _, call := range s.Calls() {
+		if call.Method != "LJ.XMLRPC.postevent" {
+			continue
+		}
+		p := call.Args[0].(xmlrpc.Struct)
+		if p["usejournal"] != "community" || p["year"] != 2024 || p["mon"] != 5 || p["min"] != 8 || p["security"] != "private" {
+			t.Fatalf("unexpected params %v", p)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package magento is a client for the legacy XML-RPC API of Magento 1.x,
+// served at /api/xmlrpc, whose resources are called by path through the
+// call and multiCall methods.
+package magento
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the resources of a Magento store with the session of its
+// login.
+type Client struct {
+	c       *xmlrpc.Client
+	session string
+}
+
+// New returns a Client of the Magento store at url, e.g.
+// https://shop.example.com/api/xmlrpc.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Login starts a session as the API user username with apiKey.
+func (c *Client) Login(ctx context.Context, username, apiKey string) error {
+	v, err := c.c.CallContext(ctx, "login", username, apiKey)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, &c.session)
+}
+
+// EndSession ends the session.
+func (c *Client) EndSession(ctx context.Context) error {
+	_, err := c.c.CallContext(ctx, "endSession", c.session)
+	c.session = ""
+	return err
+}
+
+// Call calls the resource path, e.g. "catalog_product.info", with args
+// and stores the result in r, if not nil.
+func (c *Client) Call(ctx context.Context, r interface{}, path string, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	v, err := c.c.CallContext(ctx, "call", c.session, path, args)
+	if err != nil || r == nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// MultiCall calls several resources in a single request, with the paths
+// as names of calls. A failing call doesn't fail the others; its fault is
+// reported in its result.
+func (c *Client) MultiCall(ctx context.Context, calls ...xmlrpc.MethodCall) (xmlrpc.MultiCallResults, error) {
+	arg := make(xmlrpc.Array, len(calls))
+	for i, call := range calls {
+		params := call.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		arg[i] = xmlrpc.Array{call.Name, params}
+	}
+	v, err := c.c.CallContext(ctx, "multiCall", c.session, arg, xmlrpc.Struct{"break": false})
+	if err != nil {
+		return nil, err
+	}
+	a, ok := v.(xmlrpc.Array)
+	if !ok || len(a) != len(calls) {
+		return nil, fmt.Errorf("magento: multiCall returned %T with %d results for %d calls", v, len(a), len(calls))
+	}
+	results := make(xmlrpc.MultiCallResults, len(a))
+	for i, e := range a {
+		// Failed calls are structs with isFault set.
+		if st, ok := e.(xmlrpc.Struct); ok && st["isFault"] == true {
+			f := &xmlrpc.Fault{}
+			switch code := st["faultCode"].(type) {
+			case int:
+				f.Code = code
+			case string:
+				fmt.Sscan(code, &f.Code)
+			}
+			f.String, _ = st["faultMessage"].(string)
+			results[i].Fault = f
+			continue
+		}
+		results[i].Value = e
+	}
+	return results, nil
+}
@@ -0,0 +1,64 @@
+package magento
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestClient(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"login":      xmlrpctest.Value("sess"),
+		"endSession": xmlrpctest.Value(true),
+		"call":       xmlrpctest.Value(xmlrpc.Struct{"product_id": "1", "sku": "abc"}),
+		"multiCall": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"product_id": "1"},
+			xmlrpc.Struct{"isFault": true, "faultCode": 101, "faultMessage": "Product not exists."},
+		}),
+	})
+	defer s.Close()
+	c := New(s.URL)
+	ctx := context.Background()
+
+	if err := c.Login(ctx, "api", "key"); err != nil {
+		t.Fatal(err)
+	}
+	var p struct {
+		ID  string `xmlrpc:"product_id"`
+		SKU string `xmlrpc:"sku"`
+	}
+	if err := c.Call(ctx, &p, "catalog_product.info", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "call", "sess", "catalog_product.info", xmlrpc.Array{"abc"})
+	if p.ID != "1" || p.SKU != "abc" {
+		t.Fatalf("unexpected product %+v", p)
+	}
+
+	results, err := c.MultiCall(ctx,
+		xmlrpc.MethodCall{Name: "catalog_product.info", Params: []interface{}{"abc"}},
+		xmlrpc.MethodCall{Name: "catalog_product.info", Params: []interface{}{"missing"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "multiCall", "sess", xmlrpc.Array{
+		xmlrpc.Array{"catalog_product.info", xmlrpc.Array{"abc"}},
+		xmlrpc.Array{"catalog_product.info", xmlrpc.Array{"missing"}},
+	}, xmlrpc.Struct{"break": false})
+	if results[0].Fault != nil {
+		t.Fatalf("unexpected fault %v", results[0].Fault)
+	}
+	var f *xmlrpc.Fault
+	if !errors.As(results.Err(), &f) || f.Code != 101 {
+		t.Fatalf("want fault 101 but got %v", results.Err())
+	}
+
+	if err := c.EndSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "endSession", "sess")
+}
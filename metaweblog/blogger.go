@@ -0,0 +1,34 @@
+package metaweblog
+
+import "context"
+
+// GetUsersBlogs returns the blogs of the user, using blogger.getUsersBlogs.
+func (c *Client) GetUsersBlogs(ctx context.Context) ([]Blog, error) {
+	var blogs []Blog
+	err := c.call(ctx, &blogs, "blogger.getUsersBlogs", c.AppKey, c.Username, c.Password)
+	return blogs, err
+}
+
+// BloggerNewPost creates a post of content with blogger.newPost, for
+// engines without the MetaWeblog API, and returns its ID. The Blogger API
+// has no titles; most engines take the title from a leading
+// <title>...</title> element of content.
+func (c *Client) BloggerNewPost(ctx context.Context, content string, publish bool) (string, error) {
+	var id string
+	err := c.call(ctx, &id, "blogger.newPost", c.AppKey, c.BlogID, c.Username, c.Password, content, publish)
+	return id, err
+}
+
+// BloggerEditPost replaces the content of the post postID with
+// blogger.editPost.
+func (c *Client) BloggerEditPost(ctx context.Context, postID, content string, publish bool) error {
+	var ok bool
+	return c.call(ctx, &ok, "blogger.editPost", c.AppKey, postID, c.Username, c.Password, content, publish)
+}
+
+// DeletePost deletes the post postID with blogger.deletePost, which the
+// MetaWeblog API lacks.
+func (c *Client) DeletePost(ctx context.Context, postID string, publish bool) error {
+	var ok bool
+	return c.call(ctx, &ok, "blogger.deletePost", c.AppKey, postID, c.Username, c.Password, publish)
+}
@@ -0,0 +1,40 @@
+package metaweblog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestBlogger(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"blogger.newPost":    xmlrpctest.Value("42"),
+		"blogger.editPost":   xmlrpctest.Value(true),
+		"blogger.deletePost": xmlrpctest.Value(true),
+	})
+	defer s.Close()
+	c := New(s.URL, "1", "user", "pass")
+	c.AppKey = "key"
+	ctx := context.Background()
+
+	id, err := c.BloggerNewPost(ctx, "<title>Hi</title>Hello", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "42" {
+		t.Fatalf("want post 42 but got %q", id)
+	}
+	s.AssertCalled(t, "blogger.newPost", "key", "1", "user", "pass", "<title>Hi</title>Hello", true)
+
+	if err := c.BloggerEditPost(ctx, "42", "Bye", false); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "blogger.editPost", "key", "42", "user", "pass", "Bye", false)
+
+	if err := c.DeletePost(ctx, "42", true); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "blogger.deletePost", "key", "42", "user", "pass", true)
+}
@@ -0,0 +1,142 @@
+// Package metaweblog is a client for the MetaWeblog API and the parts of
+// the Blogger API it builds on, which most blog engines serve over XML-RPC.
+package metaweblog
+
+import (
+	"context"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a blog as a user.
+type Client struct {
+	c        *xmlrpc.Client
+	BlogID   string
+	Username string
+	Password string
+	AppKey   string // sent with blogger.* methods, ignored by most servers
+}
+
+// New returns a Client of the blog blogID whose XML-RPC endpoint is url.
+func New(url, blogID, username, password string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...), BlogID: blogID, Username: username, Password: password}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// call calls the method name with args, storing the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Post is a blog post. Fields with their zero value are left out when
+// posting.
+type Post struct {
+	PostID        string    `xmlrpc:"postid,omitempty"`
+	Title         string    `xmlrpc:"title,omitempty"`
+	Description   string    `xmlrpc:"description,omitempty"` // the body
+	DateCreated   time.Time `xmlrpc:"dateCreated,omitempty"`
+	Link          string    `xmlrpc:"link,omitempty"`
+	PermaLink     string    `xmlrpc:"permaLink,omitempty"`
+	Categories    []string  `xmlrpc:"categories,omitempty"`
+	Excerpt       string    `xmlrpc:"mt_excerpt,omitempty"`
+	TextMore      string    `xmlrpc:"mt_text_more,omitempty"`
+	Keywords      string    `xmlrpc:"mt_keywords,omitempty"`
+	AllowComments int       `xmlrpc:"mt_allow_comments,omitempty"`
+	AllowPings    int       `xmlrpc:"mt_allow_pings,omitempty"`
+	UserID        string    `xmlrpc:"userid,omitempty"`
+	Slug          string    `xmlrpc:"wp_slug,omitempty"`
+	Status        string    `xmlrpc:"post_status,omitempty"`
+}
+
+// Category is a category of a blog.
+type Category struct {
+	CategoryID   string `xmlrpc:"categoryId"`
+	ParentID     string `xmlrpc:"parentId"`
+	Name         string `xmlrpc:"categoryName"`
+	Description  string `xmlrpc:"description"`
+	HTMLURL      string `xmlrpc:"htmlUrl"`
+	RSSURL       string `xmlrpc:"rssUrl"`
+	CategoryDesc string `xmlrpc:"categoryDescription"`
+}
+
+// MediaObject is a file to upload with NewMediaObject.
+type MediaObject struct {
+	Name      string `xmlrpc:"name"`
+	Type      string `xmlrpc:"type"` // MIME type
+	Bits      []byte `xmlrpc:"bits"`
+	Overwrite bool   `xmlrpc:"overwrite,omitempty"`
+}
+
+// Media describes an uploaded file. Servers other than WordPress only
+// return its URL.
+type Media struct {
+	URL  string `xmlrpc:"url"`
+	ID   string `xmlrpc:"id"`
+	File string `xmlrpc:"file"`
+	Type string `xmlrpc:"type"`
+}
+
+// Blog is a blog of a user.
+type Blog struct {
+	BlogID    string `xmlrpc:"blogid"`
+	Name      string `xmlrpc:"blogName"`
+	URL       string `xmlrpc:"url"`
+	IsAdmin   bool   `xmlrpc:"isAdmin"`
+	XMLRPCURL string `xmlrpc:"xmlrpc"`
+}
+
+// GetRecentPosts returns the n most recent posts of the blog.
+func (c *Client) GetRecentPosts(ctx context.Context, n int) ([]Post, error) {
+	var posts []Post
+	err := c.call(ctx, &posts, "metaWeblog.getRecentPosts", c.BlogID, c.Username, c.Password, n)
+	return posts, err
+}
+
+// GetPost returns the post postID.
+func (c *Client) GetPost(ctx context.Context, postID string) (*Post, error) {
+	var p Post
+	if err := c.call(ctx, &p, "metaWeblog.getPost", postID, c.Username, c.Password); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// NewPost creates a post, published unless publish is false, and returns
+// its ID.
+func (c *Client) NewPost(ctx context.Context, p Post, publish bool) (string, error) {
+	var id string
+	err := c.call(ctx, &id, "metaWeblog.newPost", c.BlogID, c.Username, c.Password, p, publish)
+	return id, err
+}
+
+// EditPost replaces the post postID with p.
+func (c *Client) EditPost(ctx context.Context, postID string, p Post, publish bool) error {
+	var ok bool
+	return c.call(ctx, &ok, "metaWeblog.editPost", postID, c.Username, c.Password, p, publish)
+}
+
+// GetCategories returns the categories of the blog.
+func (c *Client) GetCategories(ctx context.Context) ([]Category, error) {
+	var categories []Category
+	err := c.call(ctx, &categories, "metaWeblog.getCategories", c.BlogID, c.Username, c.Password)
+	return categories, err
+}
+
+// NewMediaObject uploads a file to the blog.
+func (c *Client) NewMediaObject(ctx context.Context, m MediaObject) (*Media, error) {
+	var media Media
+	if err := c.call(ctx, &media, "metaWeblog.newMediaObject", c.BlogID, c.Username, c.Password, m); err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
@@ -0,0 +1,98 @@
+package metaweblog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestGetRecentPosts(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"metaWeblog.getRecentPosts": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"postid": "1", "title": "Hello", "description": "<p>hi</p>", "dateCreated": date, "categories": xmlrpc.Array{"News"}},
+		}),
+	})
+	defer s.Close()
+
+	posts, err := New(s.URL, "blog", "user", "pass").GetRecentPosts(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0].Title != "Hello" || !posts[0].DateCreated.Equal(date) || posts[0].Categories[0] != "News" {
+		t.Fatalf("unexpected posts: %+v", posts)
+	}
+	s.AssertCalled(t, "metaWeblog.getRecentPosts", "blog", "user", "pass", 10)
+}
+
+func TestNewPost(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"metaWeblog.newPost":  xmlrpctest.Value("2"),
+		"metaWeblog.editPost": xmlrpctest.Value(true),
+	})
+	defer s.Close()
+	c := New(s.URL, "blog", "user", "pass")
+
+	id, err := c.NewPost(context.Background(), Post{Title: "Hello", Description: "hi"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "2" {
+		t.Fatalf("want 2 but got %q", id)
+	}
+	s.AssertCalled(t, "metaWeblog.newPost", "blog", "user", "pass", xmlrpc.Struct{"title": "Hello", "description": "hi"}, true)
+
+	if err := c.EditPost(context.Background(), "2", Post{Title: "Bye"}, false); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "metaWeblog.editPost", "2", "user", "pass", xmlrpc.Struct{"title": "Bye"}, false)
+}
+
+func TestNewMediaObject(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"metaWeblog.newMediaObject": xmlrpctest.Value(xmlrpc.Struct{"url": "http://example.com/a.png"}),
+	})
+	defer s.Close()
+
+	m, err := New(s.URL, "blog", "user", "pass").NewMediaObject(context.Background(), MediaObject{Name: "a.png", Type: "image/png", Bits: []byte("png")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.URL != "http://example.com/a.png" {
+		t.Fatalf("unexpected media: %+v", m)
+	}
+	s.AssertCalled(t, "metaWeblog.newMediaObject", "blog", "user", "pass", xmlrpc.Struct{"name": "a.png", "type": "image/png", "bits": []byte("png")})
+}
+
+func TestGetUsersBlogs(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"blogger.getUsersBlogs": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"blogid": "1", "blogName": "Mine", "url": "http://example.com/", "isAdmin": true},
+		}),
+		"metaWeblog.getCategories": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"categoryId": "3", "categoryName": "News", "htmlUrl": "http://example.com/news"},
+		}),
+	})
+	defer s.Close()
+	c := New(s.URL, "1", "user", "pass")
+
+	blogs, err := c.GetUsersBlogs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Name != "Mine" || !blogs[0].IsAdmin {
+		t.Fatalf("unexpected blogs: %+v", blogs)
+	}
+	s.AssertCalled(t, "blogger.getUsersBlogs", "", "user", "pass")
+
+	categories, err := c.GetCategories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0].Name != "News" {
+		t.Fatalf("unexpected categories: %+v", categories)
+	}
+}
@@ -0,0 +1,47 @@
+package metaweblog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Upload uploads the content of r as the file name of MIME type typ with
+// metaWeblog.newMediaObject. The content is streamed into the request
+// rather than held in memory. Uploads of large files may take longer than
+// the timeout of the http.Client of the underlying xmlrpc.Client.
+func (c *Client) Upload(ctx context.Context, name, typ string, r io.Reader) (*Media, error) {
+	var media Media
+	err := c.call(ctx, &media, "metaWeblog.newMediaObject", c.BlogID, c.Username, c.Password, xmlrpc.Struct{
+		"name": name,
+		"type": typ,
+		"bits": xmlrpc.Base64Reader{R: r},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// UploadFile uploads the file at path like Upload. Its MIME type is
+// guessed from its extension or, failing that, from its content.
+func (c *Client) UploadFile(ctx context.Context, path string) (*Media, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReaderSize(f, 512)
+	typ := mime.TypeByExtension(filepath.Ext(path))
+	if typ == "" {
+		head, _ := br.Peek(512)
+		typ = http.DetectContentType(head)
+	}
+	return c.Upload(ctx, filepath.Base(path), typ, br)
+}
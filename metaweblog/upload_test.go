@@ -0,0 +1,42 @@
+package metaweblog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestUploadFile(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"metaWeblog.newMediaObject": xmlrpctest.Value(xmlrpc.Struct{"url": "http://example.com/upload"}),
+	})
+	defer s.Close()
+	c := New(s.URL, "blog", "user", "pass")
+
+	dir := t.TempDir()
+	png := []byte("\x89PNG\r\n\x1a\nrest of the image")
+	tests := []struct {
+		name, typ string
+	}{
+		{"a.html", "text/html; charset=utf-8"},
+		{"image", "image/png"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, png, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		m, err := c.UploadFile(context.Background(), path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.URL != "http://example.com/upload" {
+			t.Fatalf("unexpected media: %+v", m)
+		}
+		s.AssertCalled(t, "metaWeblog.newMediaObject", "blog", "user", "pass", xmlrpc.Struct{"name": tt.name, "type": tt.typ, "bits": png})
+	}
+}
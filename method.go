@@ -0,0 +1,45 @@
+package xmlrpc
+
+// Method is a method of the server of a Client, optionally with leading
+// arguments bound to it.
+type Method struct {
+	c      *Client
+	name   string
+	prefix []interface{}
+}
+
+// Method returns the method name of the server.
+func (c *Client) Method(name string) *Method {
+	return &Method{c: c, name: name}
+}
+
+// WithPrefixArgs returns a copy of m which passes args, such as a blog ID
+// and credentials, ahead of the arguments of every call.
+func (m *Method) WithPrefixArgs(args ...interface{}) *Method {
+	prefix := make([]interface{}, 0, len(m.prefix)+len(args))
+	prefix = append(append(prefix, m.prefix...), args...)
+	return &Method{c: m.c, name: m.name, prefix: prefix}
+}
+
+// Name returns the name of the method.
+func (m *Method) Name() string {
+	return m.name
+}
+
+// Call calls the method with the prefix arguments followed by args.
+func (m *Method) Call(args ...interface{}) (interface{}, error) {
+	return m.c.Call(m.name, m.args(args)...)
+}
+
+// CallMulti is like Call but returns all params of the response.
+func (m *Method) CallMulti(args ...interface{}) (Array, error) {
+	return m.c.CallMulti(m.name, m.args(args)...)
+}
+
+func (m *Method) args(args []interface{}) []interface{} {
+	if len(m.prefix) == 0 {
+		return args
+	}
+	all := make([]interface{}, 0, len(m.prefix)+len(args))
+	return append(append(all, m.prefix...), args...)
+}
@@ -0,0 +1,36 @@
+package xmlrpc
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMethodWithPrefixArgs(t *testing.T) {
+	ts := httptest.NewServer(createServer("/api", "metaWeblog.getRecentPosts", func(args ...interface{}) (interface{}, error) {
+		return Array(args), nil
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL + "/api")
+	m := client.Method("metaWeblog.getRecentPosts")
+	bound := m.WithPrefixArgs("blog-id").WithPrefixArgs("user", "password")
+	v, err := bound.Call(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Array{"blog-id", "user", "password", 10}); !reflect.DeepEqual(v, want) {
+		t.Fatalf("want %v but got %v", want, v)
+	}
+
+	v, err = m.Call(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Array{1}); !reflect.DeepEqual(v, want) {
+		t.Fatalf("want unbound method to be unchanged, %v but got %v", want, v)
+	}
+	if bound.Name() != "metaWeblog.getRecentPosts" {
+		t.Fatalf("unexpected name %q", bound.Name())
+	}
+}
@@ -0,0 +1,148 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds of the latency histograms of Metrics
+// in seconds, the same as the default buckets of Prometheus.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// unknownMethod is the method calls of unknown methods are counted under,
+// so that clients can't make the metrics grow without bound.
+const unknownMethod = "(unknown)"
+
+// fallbackMethod is the method calls served by the fallback function of
+// SetFallback are counted under, which may be of any name.
+const fallbackMethod = "(fallback)"
+
+// MethodStats are the statistics of a method collected by Metrics.
+type MethodStats struct {
+	Calls  uint64
+	Faults uint64
+	Sum    time.Duration // total time spent in calls
+
+	// Buckets counts the calls which took at most the corresponding
+	// bucket bound, cumulatively as in Prometheus.
+	Buckets []uint64
+}
+
+// Metrics collects call counts, fault counts and latency histograms by
+// method. Its ServeHTTP method writes them in the Prometheus text format,
+// so it can be mounted as a scrape target, e.g. at /metrics.
+type Metrics struct {
+	prefix  string
+	buckets []float64
+
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+}
+
+// NewMetrics returns Metrics using the histogram bucket bounds buckets,
+// in seconds, or DefaultBuckets if none are given. The names of the
+// metrics begin with prefix, e.g. "xmlrpc_server".
+func NewMetrics(prefix string, buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{prefix: prefix, buckets: buckets, methods: map[string]*MethodStats{}}
+}
+
+// WithMetrics makes the server record its calls in m. Calls of unknown
+// methods are recorded as method "(unknown)", and those served by the
+// fallback function of SetFallback as "(fallback)".
+func WithMetrics(m *Metrics) ServerOption {
+	return func(s *Server) {
+		s.metrics = m
+	}
+}
+
+// Observe records a call of the method name which took d and failed with
+// err, if not nil.
+func (m *Metrics) Observe(name string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.methods[name]
+	if !ok {
+		st = &MethodStats{Buckets: make([]uint64, len(m.buckets))}
+		m.methods[name] = st
+	}
+	st.Calls++
+	if err != nil {
+		st.Faults++
+	}
+	st.Sum += d
+	for i, b := range m.buckets {
+		if d.Seconds() <= b {
+			st.Buckets[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the statistics by method.
+func (m *Metrics) Snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := make(map[string]MethodStats, len(m.methods))
+	for name, st := range m.methods {
+		c := *st
+		c.Buckets = append([]uint64(nil), st.Buckets...)
+		r[name] = c
+	}
+	return r
+}
+
+// ServeHTTP implements http.Handler.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.Write(w)
+}
+
+// Write writes the metrics to w in the Prometheus text format.
+func (m *Metrics) Write(w io.Writer) error {
+	stats := m.Snapshot()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ew := &errWriter{w: w}
+	fmt.Fprintf(ew, "# HELP %s_calls_total Number of calls by method.\n", m.prefix)
+	fmt.Fprintf(ew, "# TYPE %s_calls_total counter\n", m.prefix)
+	for _, name := range names {
+		fmt.Fprintf(ew, "%s_calls_total{method=%s} %d\n", m.prefix, label(name), stats[name].Calls)
+	}
+	fmt.Fprintf(ew, "# HELP %s_faults_total Number of calls answered with a fault by method.\n", m.prefix)
+	fmt.Fprintf(ew, "# TYPE %s_faults_total counter\n", m.prefix)
+	for _, name := range names {
+		fmt.Fprintf(ew, "%s_faults_total{method=%s} %d\n", m.prefix, label(name), stats[name].Faults)
+	}
+	fmt.Fprintf(ew, "# HELP %s_call_duration_seconds Latency of calls by method.\n", m.prefix)
+	fmt.Fprintf(ew, "# TYPE %s_call_duration_seconds histogram\n", m.prefix)
+	for _, name := range names {
+		st := stats[name]
+		for i, b := range m.buckets {
+			fmt.Fprintf(ew, "%s_call_duration_seconds_bucket{method=%s,le=\"%g\"} %d\n", m.prefix, label(name), b, st.Buckets[i])
+		}
+		fmt.Fprintf(ew, "%s_call_duration_seconds_bucket{method=%s,le=\"+Inf\"} %d\n", m.prefix, label(name), st.Calls)
+		fmt.Fprintf(ew, "%s_call_duration_seconds_sum{method=%s} %g\n", m.prefix, label(name), st.Sum.Seconds())
+		fmt.Fprintf(ew, "%s_call_duration_seconds_count{method=%s} %d\n", m.prefix, label(name), st.Calls)
+	}
+	return ew.err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes s as a Prometheus label value.
+func label(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,91 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics("xmlrpc_server", 1, 0.5)
+	s := NewServer(WithMetrics(m))
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, errors.New("no args")
+		}
+		return args[0], nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	c.Call("echo", 1)
+	c.Call("echo", 2)
+	c.Call("echo")
+	c.Call("missing")
+
+	stats := m.Snapshot()
+	if st := stats["echo"]; st.Calls != 3 || st.Faults != 1 || st.Buckets[0] != 3 || st.Buckets[1] != 3 {
+		t.Fatalf("unexpected stats of echo: %+v", st)
+	}
+	if st := stats[unknownMethod]; st.Calls != 1 || st.Faults != 1 {
+		t.Fatalf("unexpected stats of unknown methods: %+v", st)
+	}
+	if _, ok := stats["missing"]; ok {
+		t.Fatal("want unknown methods counted together")
+	}
+
+	var b bytes.Buffer
+	if err := m.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE xmlrpc_server_calls_total counter\n",
+		`xmlrpc_server_calls_total{method="echo"} 3` + "\n",
+		`xmlrpc_server_faults_total{method="(unknown)"} 1` + "\n",
+		"# TYPE xmlrpc_server_call_duration_seconds histogram\n",
+		`xmlrpc_server_call_duration_seconds_bucket{method="echo",le="0.5"} 3` + "\n",
+		`xmlrpc_server_call_duration_seconds_bucket{method="echo",le="+Inf"} 3` + "\n",
+		`xmlrpc_server_call_duration_seconds_count{method="echo"} 3` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("want %q in:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestMetricsFallback(t *testing.T) {
+	m := NewMetrics("xmlrpc_server")
+	s := NewServer(WithMetrics(m))
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	s.SetFallback(func(ctx context.Context, method string, args []interface{}) (interface{}, error) {
+		return method, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	c.Call("echo", 1)
+	for i := 0; i < 3; i++ {
+		if v, err := c.Call(fmt.Sprintf("any.%d", i)); err != nil || v != fmt.Sprintf("any.%d", i) {
+			t.Fatalf("want call served by fallback but got %v, %v", v, err)
+		}
+	}
+
+	stats := m.Snapshot()
+	if len(stats) != 2 || stats["echo"].Calls != 1 || stats[fallbackMethod].Calls != 3 {
+		t.Fatalf("want calls of the fallback counted together but got %+v", stats)
+	}
+}
+
+func TestMetricsLabel(t *testing.T) {
+	if got, want := label("a\"b\\c\n"), `"a\"b\\c\n"`; got != want {
+		t.Fatalf("want %s but got %s", want, got)
+	}
+}
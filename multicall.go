@@ -0,0 +1,94 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+)
+
+// MultiCallResult is the outcome of one call of a MultiCall: either the
+// returned value or the fault it failed with.
+type MultiCallResult struct {
+	Value interface{}
+	Fault *Fault
+}
+
+// MultiCallResults holds the results of a MultiCall in the order of the
+// calls.
+type MultiCallResults []MultiCallResult
+
+// Split returns the values of the successful calls and the faults of the
+// failed ones, keyed by the index of the call.
+func (r MultiCallResults) Split() (values map[int]interface{}, faults map[int]*Fault) {
+	values = map[int]interface{}{}
+	faults = map[int]*Fault{}
+	for i, res := range r {
+		if res.Fault != nil {
+			faults[i] = res.Fault
+		} else {
+			values[i] = res.Value
+		}
+	}
+	return values, faults
+}
+
+// Err returns the fault of the first failed call, or nil if all succeeded.
+func (r MultiCallResults) Err() error {
+	for _, res := range r {
+		if res.Fault != nil {
+			return res.Fault
+		}
+	}
+	return nil
+}
+
+// MultiCall makes calls in a single request using system.multicall. A
+// failing call doesn't fail the others; its fault is reported in its
+// result. The error is only set if the request as a whole failed.
+func (c *Client) MultiCall(calls ...MethodCall) (MultiCallResults, error) {
+	return c.MultiCallContext(context.Background(), calls...)
+}
+
+// MultiCallContext is like MultiCall but aborts the request when ctx is
+// done.
+func (c *Client) MultiCallContext(ctx context.Context, calls ...MethodCall) (MultiCallResults, error) {
+	arg := make(Array, len(calls))
+	for i, call := range calls {
+		params := call.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		arg[i] = Struct{"methodName": call.Name, "params": params}
+	}
+	v, err := c.CallContext(ctx, "system.multicall", arg)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := v.(Array)
+	if !ok || len(a) != len(calls) {
+		return nil, fmt.Errorf("xmlrpc: system.multicall returned %T with %d results for %d calls", v, len(a), len(calls))
+	}
+	results := make(MultiCallResults, len(a))
+	for i, e := range a {
+		switch e := e.(type) {
+		case Array:
+			if len(e) != 1 {
+				return nil, fmt.Errorf("xmlrpc: system.multicall result %d has %d values", i, len(e))
+			}
+			results[i].Value = e[0]
+		case Struct:
+			f := &Fault{}
+			switch code := e["faultCode"].(type) {
+			case int, int32, int64:
+				n, _ := toInt64(code)
+				f.Code = int(n)
+			case string:
+				fmt.Sscan(code, &f.Code)
+			}
+			f.String, _ = e["faultString"].(string)
+			results[i].Fault = f
+		default:
+			return nil, fmt.Errorf("xmlrpc: system.multicall result %d is %T", i, e)
+		}
+	}
+	return results, nil
+}
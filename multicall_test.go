@@ -0,0 +1,58 @@
+package xmlrpc
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMultiCall(t *testing.T) {
+	s := NewServer()
+	s.Register("system.multicall", func(args ...interface{}) (interface{}, error) {
+		var res Array
+		for _, c := range args[0].(Array) {
+			c := c.(Struct)
+			params := c["params"].(Array)
+			switch c["methodName"] {
+			case "add":
+				res = append(res, Array{params[0].(int) + params[1].(int)})
+			default:
+				res = append(res, Struct{"faultCode": MethodNotFound, "faultString": "no such method"})
+			}
+		}
+		return res, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	results, err := NewClient(ts.URL).MultiCall(
+		MethodCall{Name: "add", Params: []interface{}{1, 2}},
+		MethodCall{Name: "nope"},
+		MethodCall{Name: "add", Params: []interface{}{3, 4}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MultiCallResults{
+		{Value: 3},
+		{Fault: &Fault{Code: MethodNotFound, String: "no such method"}},
+		{Value: 7},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("want %v but got %v", want, results)
+	}
+
+	values, faults := results.Split()
+	if !reflect.DeepEqual(values, map[int]interface{}{0: 3, 2: 7}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+	if len(faults) != 1 || faults[1].Code != MethodNotFound {
+		t.Fatalf("unexpected faults: %v", faults)
+	}
+	if err := results.Err(); err != faults[1] {
+		t.Fatalf("want first fault but got %v", err)
+	}
+	if err := results[:1].Err(); err != nil {
+		t.Fatalf("want nil but got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+// Package negotiate authenticates XML-RPC calls with HTTP Negotiate, the
+// SPNEGO scheme of RFC 4559 which intranet services use for Kerberos,
+// e.g. Koji hubs. It doesn't implement Kerberos itself: a Provider, such
+// as one built on gokrb5 or a GSSAPI binding, makes the tokens.
+//
+//	c := xmlrpc.NewClient(url, negotiate.WithProvider(provider))
+package negotiate
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Provider makes SPNEGO tokens.
+type Provider interface {
+	// Token returns the initial token of a security context with the
+	// service principal spn, e.g. "HTTP/koji.example.com".
+	Token(ctx context.Context, spn string) ([]byte, error)
+}
+
+// ProviderFunc adapts a function to a Provider.
+type ProviderFunc func(ctx context.Context, spn string) ([]byte, error)
+
+// Token implements Provider.
+func (f ProviderFunc) Token(ctx context.Context, spn string) ([]byte, error) {
+	return f(ctx, spn)
+}
+
+// WithProvider makes the client authenticate with tokens of p, wrapping
+// the transport of its http.Client in a Transport.
+func WithProvider(p Provider) xmlrpc.Option {
+	return func(c *xmlrpc.Client) {
+		c.HttpClient.Transport = &Transport{Provider: p, Base: c.HttpClient.Transport}
+	}
+}
+
+// Transport is an http.RoundTripper which authenticates requests with
+// Negotiate when the server asks for it, by a response with status 401
+// and a WWW-Authenticate header of Negotiate. Bodies of requests must be
+// replayable by GetBody, as those of Client are.
+type Transport struct {
+	Provider Provider
+
+	// SPN returns the service principal of the host of a request,
+	// "HTTP/" followed by the host if nil.
+	SPN func(host string) string
+
+	// Preemptive sends a token with every request rather than waiting for
+	// the server to ask for it, saving a round trip.
+	Preemptive bool
+
+	// Base makes the requests, http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Preemptive {
+		req2, err := t.authorize(req)
+		if err != nil {
+			return nil, err
+		}
+		return base.RoundTrip(req2)
+	}
+
+	r, err := base.RoundTrip(req)
+	if err != nil || !asked(r) {
+		return r, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return r, nil
+	}
+	io.Copy(io.Discard, r.Body)
+	r.Body.Close()
+	req2, err := t.authorize(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if req2.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return base.RoundTrip(req2)
+}
+
+// authorize returns a copy of req with a token.
+func (t *Transport) authorize(req *http.Request) (*http.Request, error) {
+	if t.Provider == nil {
+		return nil, errors.New("negotiate: no provider")
+	}
+	host := req.URL.Hostname()
+	spn := "HTTP/" + host
+	if t.SPN != nil {
+		spn = t.SPN(host)
+	}
+	token, err := t.Provider.Token(req.Context(), spn)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Negotiate "+base64.StdEncoding.EncodeToString(token))
+	return req, nil
+}
+
+// asked reports whether the response r asks for Negotiate.
+func asked(r *http.Response) bool {
+	if r.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	for _, h := range r.Header.Values("Www-Authenticate") {
+		if scheme, _, _ := strings.Cut(h, " "); strings.EqualFold(scheme, "Negotiate") {
+			return true
+		}
+	}
+	return false
+}
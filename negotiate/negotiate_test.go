@@ -0,0 +1,60 @@
+package negotiate
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestTransport(t *testing.T) {
+	s := xmlrpc.NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Negotiate "+base64.StdEncoding.EncodeToString([]byte("token for HTTP/127.0.0.1")) {
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	if u, _ := url.Parse(ts.URL); u.Hostname() != "127.0.0.1" {
+		t.Skip("test server not on 127.0.0.1")
+	}
+	p := ProviderFunc(func(ctx context.Context, spn string) ([]byte, error) {
+		return []byte("token for " + spn), nil
+	})
+
+	v, err := xmlrpc.NewClient(ts.URL, WithProvider(p)).Call("echo", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hello" || requests != 2 {
+		t.Fatalf("want hello after 2 requests but got %v after %d", v, requests)
+	}
+
+	requests = 0
+	c := xmlrpc.NewClient(ts.URL)
+	c.HttpClient.Transport = &Transport{Provider: p, Preemptive: true}
+	if _, err := c.Call("echo", "hello"); err != nil || requests != 1 {
+		t.Fatalf("want 1 request but got %d, %v", requests, err)
+	}
+
+	errNoTicket := errors.New("no ticket")
+	c = xmlrpc.NewClient(ts.URL, WithProvider(ProviderFunc(func(ctx context.Context, spn string) ([]byte, error) {
+		return nil, errNoTicket
+	})))
+	if _, err := c.Call("echo", "hello"); !errors.Is(err, errNoTicket) {
+		t.Fatalf("want provider error but got %v", err)
+	}
+}
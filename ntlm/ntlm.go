@@ -0,0 +1,253 @@
+// Package ntlm authenticates XML-RPC calls to servers behind NTLM, the
+// Windows Integrated Authentication of IIS, with NTLMv2.
+//
+//	c := xmlrpc.NewClient(url, ntlm.WithAuth(`DOMAIN\user`, password))
+package ntlm
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// WithAuth makes the client authenticate as username, optionally prefixed
+// by its domain as in `DOMAIN\user`, with password, wrapping the transport
+// of its http.Client in a Transport.
+func WithAuth(username, password string) xmlrpc.Option {
+	return func(c *xmlrpc.Client) {
+		c.HttpClient.Transport = &Transport{
+			Username: username,
+			Password: password,
+			Base:     c.HttpClient.Transport,
+		}
+	}
+}
+
+// Transport is an http.RoundTripper which authenticates requests with
+// NTLM. The handshake takes two round trips on the same connection, so
+// bodies of requests must be replayable by GetBody, as those of Client
+// are.
+type Transport struct {
+	Username string // `DOMAIN\user` or user
+	Password string
+
+	// Base makes the requests, http.DefaultTransport if nil. It must keep
+	// connections alive, to which NTLM binds the authentication.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return nil, errors.New("ntlm: request body can't be replayed")
+	}
+
+	r, err := base.RoundTrip(withAuth(req, negotiateMessage()))
+	if err != nil {
+		return nil, err
+	}
+	challenge, ok := challengeOf(r)
+	if !ok {
+		return r, nil
+	}
+	io.Copy(io.Discard, r.Body)
+	r.Body.Close()
+
+	domain, user := "", t.Username
+	if i := strings.IndexByte(user, '\\'); i >= 0 {
+		domain, user = user[:i], user[i+1:]
+	}
+	auth, err := authenticateMessage(challenge, domain, user, t.Password)
+	if err != nil {
+		return nil, err
+	}
+	req2 := withAuth(req, auth)
+	if req.GetBody != nil {
+		if req2.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return base.RoundTrip(req2)
+}
+
+// withAuth returns a copy of req with the NTLM message msg.
+func withAuth(req *http.Request, msg []byte) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(msg))
+	return req
+}
+
+// challengeOf returns the challenge message of the response r, if it asks
+// to continue the handshake.
+func challengeOf(r *http.Response) ([]byte, bool) {
+	if r.StatusCode != http.StatusUnauthorized {
+		return nil, false
+	}
+	for _, h := range r.Header.Values("Www-Authenticate") {
+		if s, ok := strings.CutPrefix(h, "NTLM "); ok {
+			b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+			return b, err == nil
+		}
+	}
+	return nil, false
+}
+
+// Flags of the messages.
+const (
+	negotiateUnicode         = 0x00000001
+	negotiateOEM             = 0x00000002
+	requestTarget            = 0x00000004
+	negotiateNTLM            = 0x00000200
+	negotiateAlwaysSign      = 0x00008000
+	negotiateExtendedSession = 0x00080000
+	negotiateTargetInfo      = 0x00800000
+	negotiate128             = 0x20000000
+	negotiate56              = 0x80000000
+
+	defaultFlags = negotiateUnicode | negotiateOEM | requestTarget | negotiateNTLM |
+		negotiateAlwaysSign | negotiateExtendedSession | negotiateTargetInfo | negotiate128 | negotiate56
+)
+
+var signature = []byte("NTLMSSP\x00")
+
+// negotiateMessage returns the message starting the handshake.
+func negotiateMessage() []byte {
+	b := append([]byte{}, signature...)
+	b = binary.LittleEndian.AppendUint32(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, defaultFlags)
+	return append(b, make([]byte, 16)...) // no domain or workstation
+}
+
+// authenticateMessage returns the message answering challenge.
+func authenticateMessage(challenge []byte, domain, user, password string) ([]byte, error) {
+	if len(challenge) < 48 || !bytes.Equal(challenge[:8], signature) || binary.LittleEndian.Uint32(challenge[8:]) != 2 {
+		return nil, errors.New("ntlm: invalid challenge message")
+	}
+	flags := binary.LittleEndian.Uint32(challenge[20:])
+	serverChallenge := challenge[24:32]
+	targetInfo, err := field(challenge, 40)
+	if err != nil {
+		return nil, err
+	}
+
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+	ts, ok := timestamp(targetInfo)
+	if !ok {
+		ts = filetime(time.Now())
+	}
+	nt := ntlmv2Response(ntowfv2(user, password, domain), serverChallenge, clientChallenge, ts, targetInfo)
+
+	str := func(s string) []byte {
+		if flags&negotiateUnicode != 0 {
+			return utf16le(s)
+		}
+		return []byte(s)
+	}
+	payload := [][]byte{
+		make([]byte, 24), // LMv2 is left out as of NTLMv2 with timestamps
+		nt,
+		str(domain),
+		str(user),
+		nil, // workstation
+		nil, // session key
+	}
+	const header = 64
+	b := append([]byte{}, signature...)
+	b = binary.LittleEndian.AppendUint32(b, 3)
+	offset := header
+	for _, p := range payload {
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(p)))
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(p)))
+		b = binary.LittleEndian.AppendUint32(b, uint32(offset))
+		offset += len(p)
+	}
+	b = binary.LittleEndian.AppendUint32(b, flags&defaultFlags|negotiateNTLM)
+	for _, p := range payload {
+		b = append(b, p...)
+	}
+	return b, nil
+}
+
+// field returns the payload of the field whose descriptor is at offset i
+// of msg.
+func field(msg []byte, i int) ([]byte, error) {
+	n := int(binary.LittleEndian.Uint16(msg[i:]))
+	off := int(binary.LittleEndian.Uint32(msg[i+4:]))
+	if off+n > len(msg) {
+		return nil, errors.New("ntlm: invalid challenge message")
+	}
+	return msg[off : off+n], nil
+}
+
+// timestamp returns the MsvAvTimestamp of targetInfo, if present.
+func timestamp(targetInfo []byte) ([]byte, bool) {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		n := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == 0 || len(targetInfo) < 4+n {
+			break
+		}
+		if id == 7 && n == 8 {
+			return targetInfo[4:12], true
+		}
+		targetInfo = targetInfo[4+n:]
+	}
+	return nil, false
+}
+
+// filetime returns t as a Windows FILETIME, in 100ns since 1601.
+func filetime(t time.Time) []byte {
+	ft := uint64(t.UnixNano()/100) + 116444736000000000
+	return binary.LittleEndian.AppendUint64(nil, ft)
+}
+
+func utf16le(s string) []byte {
+	var b []byte
+	for _, r := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, r)
+	}
+	return b
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	m := hmac.New(md5.New, key)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// ntowfv2 returns the NTLMv2 key of the user.
+func ntowfv2(user, password, domain string) []byte {
+	hash := md4(utf16le(password))
+	return hmacMD5(hash[:], utf16le(strings.ToUpper(user)+domain))
+}
+
+// ntlmv2Response returns the NTLMv2 response to serverChallenge.
+func ntlmv2Response(key, serverChallenge, clientChallenge, ts, targetInfo []byte) []byte {
+	temp := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	temp = append(temp, ts...)
+	temp = append(temp, clientChallenge...)
+	temp = append(temp, 0, 0, 0, 0)
+	temp = append(temp, targetInfo...)
+	temp = append(temp, 0, 0, 0, 0)
+	return append(hmacMD5(key, serverChallenge, temp), temp...)
+}
@@ -0,0 +1,96 @@
+package ntlm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestNTLMv2Response(t *testing.T) {
+	// MS-NLMP 4.2.4
+	key := ntowfv2("User", "Password", "Domain")
+	if got := hex.EncodeToString(key); got != "0c868a403bfd7a93a3001ef22ef02e3f" {
+		t.Fatalf("unexpected NTOWFv2 %s", got)
+	}
+	serverChallenge, _ := hex.DecodeString("0123456789abcdef")
+	clientChallenge := bytes.Repeat([]byte{0xaa}, 8)
+	targetInfo, _ := hex.DecodeString("02000c0044006f006d00610069006e0001000c0053006500720076006500720000000000")
+	nt := ntlmv2Response(key, serverChallenge, clientChallenge, make([]byte, 8), targetInfo)
+	if got := hex.EncodeToString(nt[:16]); got != "68cd0ab851e51c96aabc927bebef6a1c" {
+		t.Fatalf("unexpected NTProofStr %s", got)
+	}
+}
+
+// challengeMessage returns a challenge message with serverChallenge and a
+// target info with a timestamp.
+func challengeMessage(serverChallenge []byte) []byte {
+	targetInfo := []byte{7, 0, 8, 0, 1, 2, 3, 4, 5, 6, 7, 8, 0, 0, 0, 0}
+	b := append([]byte{}, signature...)
+	b = binary.LittleEndian.AppendUint32(b, 2)
+	b = append(b, 0, 0, 0, 0, 48, 0, 0, 0) // no target name
+	b = binary.LittleEndian.AppendUint32(b, defaultFlags)
+	b = append(b, serverChallenge...)
+	b = append(b, make([]byte, 8)...)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(targetInfo)))
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(targetInfo)))
+	b = binary.LittleEndian.AppendUint32(b, 48)
+	return append(b, targetInfo...)
+}
+
+func TestTransport(t *testing.T) {
+	s := xmlrpc.NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	serverChallenge := []byte("servchal")
+	var negotiated string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(r.Header.Get("Authorization"), "NTLM "))
+		if len(msg) < 12 || !bytes.Equal(msg[:8], signature) {
+			w.Header().Set("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch binary.LittleEndian.Uint32(msg[8:]) {
+		case 1:
+			negotiated = r.RemoteAddr
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challengeMessage(serverChallenge)))
+			w.WriteHeader(http.StatusUnauthorized)
+		case 3:
+			nt, _ := field(msg, 20)
+			domain, _ := field(msg, 28)
+			user, _ := field(msg, 36)
+			key := ntowfv2("alice", "secret", "CORP")
+			want := hmacMD5(key, serverChallenge, nt[16:])
+			// The response must carry the timestamp of the server.
+			if r.RemoteAddr != negotiated || !bytes.Equal(domain, utf16le("CORP")) || !bytes.Equal(user, utf16le("alice")) ||
+				!bytes.Equal(nt[:16], want) || !bytes.Equal(nt[24:32], []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			s.ServeHTTP(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := xmlrpc.NewClient(ts.URL, WithAuth(`CORP\alice`, "secret"))
+	for i := 0; i < 2; i++ {
+		v, err := c.Call("echo", "hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "hello" {
+			t.Fatalf("want hello but got %v", v)
+		}
+	}
+	if _, err := xmlrpc.NewClient(ts.URL, WithAuth(`CORP\alice`, "wrong")).Call("echo", "hello"); err == nil {
+		t.Fatal("want wrong password rejected")
+	}
+}
@@ -0,0 +1,82 @@
+package odoo
+
+import "github.com/mattn/go-xmlrpc"
+
+// Domain is a search domain, a list of conditions and of the prefix
+// operators "&", "|" and "!". The conditions of a domain without operators
+// must all match.
+type Domain []interface{}
+
+// Where returns the domain of the condition that field compares to value
+// by op, such as "=", "!=", "ilike", "in" or "child_of".
+func Where(field, op string, value interface{}) Domain {
+	return Domain{xmlrpc.Array{field, op, value}}
+}
+
+// And returns the domain matching all of domains.
+func And(domains ...Domain) Domain {
+	return combine("&", domains)
+}
+
+// Or returns the domain matching any of domains.
+func Or(domains ...Domain) Domain {
+	return combine("|", domains)
+}
+
+// Not returns the domain matching what d doesn't match.
+func Not(d Domain) Domain {
+	return append(Domain{"!"}, group(d)...)
+}
+
+// combine joins domains with the prefix operator op, skipping empty ones.
+func combine(op string, domains []Domain) Domain {
+	var r Domain
+	for _, d := range domains {
+		if len(d) == 0 {
+			continue
+		}
+		if len(r) > 0 {
+			r = append(Domain{op}, r...)
+		}
+		r = append(r, group(d)...)
+	}
+	return r
+}
+
+// group returns d as a single term, making its implicit conjunction
+// explicit.
+func group(d Domain) Domain {
+	for n := countTerms(d); n > 1; n-- {
+		d = append(Domain{"&"}, d...)
+	}
+	return d
+}
+
+// countTerms returns the number of top level terms of d, which are
+// and-ed implicitly.
+func countTerms(d Domain) int {
+	n, need := 0, 0
+	for _, e := range d {
+		if need == 0 {
+			n++
+			need = 1
+		}
+		switch e {
+		case "&", "|":
+			need++
+		case "!":
+		default:
+			need--
+		}
+	}
+	return n
+}
+
+// value returns d as sent, an empty array matching all records if d is
+// empty.
+func (d Domain) value() xmlrpc.Array {
+	if d == nil {
+		return xmlrpc.Array{}
+	}
+	return xmlrpc.Array(d)
+}
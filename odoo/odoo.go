@@ -0,0 +1,181 @@
+// Package odoo is a client for the external API of Odoo, formerly OpenERP,
+// which serves XML-RPC at /xmlrpc/2/common and /xmlrpc/2/object.
+package odoo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// ErrAuth is returned by Authenticate for invalid credentials.
+var ErrAuth = errors.New("odoo: authentication failed")
+
+// Client calls the methods of the models of an Odoo database as a user.
+type Client struct {
+	common *xmlrpc.Client
+	object *xmlrpc.Client
+
+	db       string
+	username string
+	password string // or API key
+	uid      int
+}
+
+// New returns a Client of the database db of the Odoo at url, e.g.
+// https://mycompany.odoo.com. It must authenticate before calling methods
+// of models.
+func New(url, db, username, password string, opts ...xmlrpc.Option) *Client {
+	url = strings.TrimSuffix(url, "/")
+	return &Client{
+		common:   xmlrpc.NewClient(url+"/xmlrpc/2/common", opts...),
+		object:   xmlrpc.NewClient(url+"/xmlrpc/2/object", opts...),
+		db:       db,
+		username: username,
+		password: password,
+	}
+}
+
+// Version returns the version info of the server.
+func (c *Client) Version(ctx context.Context) (xmlrpc.Struct, error) {
+	v, err := c.common.CallContext(ctx, "version")
+	if err != nil {
+		return nil, err
+	}
+	st, _ := v.(xmlrpc.Struct)
+	return st, nil
+}
+
+// Authenticate logs in, returning the ID of the user.
+func (c *Client) Authenticate(ctx context.Context) (int, error) {
+	v, err := c.common.CallContext(ctx, "authenticate", c.db, c.username, c.password, xmlrpc.Struct{})
+	if err != nil {
+		return 0, err
+	}
+	// The result is false rather than a user ID for invalid credentials.
+	uid, ok := v.(int)
+	if !ok {
+		return 0, ErrAuth
+	}
+	c.uid = uid
+	return uid, nil
+}
+
+// ExecuteKw calls the method of model with args and kwargs, which may be
+// nil.
+func (c *Client) ExecuteKw(ctx context.Context, model, method string, args []interface{}, kwargs map[string]interface{}) (interface{}, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	if kwargs == nil {
+		kwargs = map[string]interface{}{}
+	}
+	return c.object.CallContext(ctx, "execute_kw", c.db, c.uid, c.password, model, method, args, kwargs)
+}
+
+// Options are the common keyword arguments of search methods. Zero values
+// are left out.
+type Options struct {
+	Offset int
+	Limit  int
+	Order  string // e.g. "name asc, id desc"
+}
+
+func (o *Options) kwargs(kw map[string]interface{}) map[string]interface{} {
+	if o == nil {
+		return kw
+	}
+	if o.Offset > 0 {
+		kw["offset"] = o.Offset
+	}
+	if o.Limit > 0 {
+		kw["limit"] = o.Limit
+	}
+	if o.Order != "" {
+		kw["order"] = o.Order
+	}
+	return kw
+}
+
+// Search returns the IDs of the records of model matching domain.
+func (c *Client) Search(ctx context.Context, model string, domain Domain, opts *Options) ([]int, error) {
+	v, err := c.ExecuteKw(ctx, model, "search", []interface{}{domain.value()}, opts.kwargs(map[string]interface{}{}))
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	err = xmlrpc.Unmarshal(v, &ids)
+	return ids, err
+}
+
+// SearchRead stores the fields of the records of model matching domain in
+// dst, a pointer to a slice of structs or of maps. All fields are read if
+// fields is empty. As Odoo sends false for empty fields which aren't
+// booleans, false is stored as the zero value of fields of other types.
+func (c *Client) SearchRead(ctx context.Context, model string, domain Domain, fields []string, opts *Options, dst interface{}) error {
+	kw := map[string]interface{}{}
+	if len(fields) > 0 {
+		kw["fields"] = fields
+	}
+	v, err := c.ExecuteKw(ctx, model, "search_read", []interface{}{domain.value()}, opts.kwargs(kw))
+	if err != nil {
+		return err
+	}
+	return unmarshalRecords(v, dst)
+}
+
+// Read stores the fields of the records ids of model in dst like
+// SearchRead.
+func (c *Client) Read(ctx context.Context, model string, ids []int, fields []string, dst interface{}) error {
+	kw := map[string]interface{}{}
+	if len(fields) > 0 {
+		kw["fields"] = fields
+	}
+	v, err := c.ExecuteKw(ctx, model, "read", []interface{}{ids}, kw)
+	if err != nil {
+		return err
+	}
+	return unmarshalRecords(v, dst)
+}
+
+// unmarshalRecords stores the records v in dst, dropping false values of
+// fields, which stand for empty values of any type.
+func unmarshalRecords(v interface{}, dst interface{}) error {
+	if records, ok := v.(xmlrpc.Array); ok {
+		for _, r := range records {
+			if st, ok := r.(xmlrpc.Struct); ok {
+				for name, f := range st {
+					if f == false {
+						st[name] = nil
+					}
+				}
+			}
+		}
+	}
+	return xmlrpc.Unmarshal(v, dst)
+}
+
+// Create creates a record of model with values, returning its ID.
+func (c *Client) Create(ctx context.Context, model string, values map[string]interface{}) (int, error) {
+	v, err := c.ExecuteKw(ctx, model, "create", []interface{}{values}, nil)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = xmlrpc.Unmarshal(v, &id)
+	return id, err
+}
+
+// Write sets values on the records ids of model.
+func (c *Client) Write(ctx context.Context, model string, ids []int, values map[string]interface{}) error {
+	_, err := c.ExecuteKw(ctx, model, "write", []interface{}{ids, values}, nil)
+	return err
+}
+
+// Unlink deletes the records ids of model.
+func (c *Client) Unlink(ctx context.Context, model string, ids []int) error {
+	_, err := c.ExecuteKw(ctx, model, "unlink", []interface{}{ids}, nil)
+	return err
+}
@@ -0,0 +1,118 @@
+package odoo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	common := xmlrpc.NewServer()
+	common.Register("authenticate", func(args ...interface{}) (interface{}, error) {
+		if args[0] != "db" || args[1] != "admin" || args[2] != "secret" {
+			return false, nil
+		}
+		return 2, nil
+	})
+	object := xmlrpc.NewServer()
+	object.Register("execute_kw", func(args ...interface{}) (interface{}, error) {
+		if args[1] != 2 || args[2] != "secret" {
+			return nil, &xmlrpc.Fault{Code: 3, String: "Access Denied"}
+		}
+		model, method := args[3], args[4]
+		params, kw := args[5].(xmlrpc.Array), args[6].(xmlrpc.Struct)
+		switch {
+		case model == "res.partner" && method == "search_read":
+			want := xmlrpc.Array{"|", xmlrpc.Array{"is_company", "=", true}, xmlrpc.Array{"name", "ilike", "acme"}}
+			if !reflect.DeepEqual(params[0], want) {
+				t.Errorf("unexpected domain %v", params[0])
+			}
+			if !reflect.DeepEqual(kw, xmlrpc.Struct{"fields": xmlrpc.Array{"name", "email"}, "limit": 5}) {
+				t.Errorf("unexpected kwargs %v", kw)
+			}
+			return xmlrpc.Array{
+				xmlrpc.Struct{"id": 1, "name": "Acme", "email": "info@acme.example"},
+				xmlrpc.Struct{"id": 7, "name": "Bob", "email": false},
+			}, nil
+		case model == "res.partner" && method == "create":
+			return 8, nil
+		case model == "res.partner" && method == "write":
+			return true, nil
+		}
+		return nil, &xmlrpc.Fault{Code: 1, String: "unexpected call"}
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/xmlrpc/2/common", common)
+	mux.Handle("/xmlrpc/2/object", object)
+	return httptest.NewServer(mux)
+}
+
+func TestClient(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	ctx := context.Background()
+
+	if _, err := New(ts.URL, "db", "admin", "wrong").Authenticate(ctx); !errors.Is(err, ErrAuth) {
+		t.Fatalf("want ErrAuth but got %v", err)
+	}
+	c := New(ts.URL+"/", "db", "admin", "secret")
+	uid, err := c.Authenticate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid != 2 {
+		t.Fatalf("want uid 2 but got %d", uid)
+	}
+
+	type partner struct {
+		ID    int    `xmlrpc:"id"`
+		Name  string `xmlrpc:"name"`
+		Email string `xmlrpc:"email"`
+	}
+	var partners []partner
+	domain := Or(Where("is_company", "=", true), Where("name", "ilike", "acme"))
+	if err := c.SearchRead(ctx, "res.partner", domain, []string{"name", "email"}, &Options{Limit: 5}, &partners); err != nil {
+		t.Fatal(err)
+	}
+	want := []partner{{1, "Acme", "info@acme.example"}, {7, "Bob", ""}}
+	if !reflect.DeepEqual(partners, want) {
+		t.Fatalf("want %v but got %v", want, partners)
+	}
+
+	id, err := c.Create(ctx, "res.partner", map[string]interface{}{"name": "Carol"})
+	if err != nil || id != 8 {
+		t.Fatalf("want 8 but got %v, %v", id, err)
+	}
+	if err := c.Write(ctx, "res.partner", []int{8}, map[string]interface{}{"name": "Dave"}); err != nil {
+		t.Fatal(err)
+	}
+	var f *xmlrpc.Fault
+	if err := c.Unlink(ctx, "res.partner", []int{8}); !errors.As(err, &f) {
+		t.Fatalf("want fault but got %v", err)
+	}
+}
+
+func TestDomain(t *testing.T) {
+	a, b, c := Where("a", "=", 1), Where("b", "=", 2), Where("c", "=", 3)
+	tests := []struct {
+		got, want Domain
+	}{
+		{And(), nil},
+		{Or(a), a},
+		{Or(a, b, c), Domain{"|", "|", a[0], b[0], c[0]}},
+		{Or(append(a, b...), c), Domain{"|", "&", a[0], b[0], c[0]}},
+		{And(a, Or(b, c)), Domain{"&", a[0], "|", b[0], c[0]}},
+		{Not(append(a, b...)), Domain{"!", "&", a[0], b[0]}},
+		{Or(Not(a), b), Domain{"|", "!", a[0], b[0]}},
+	}
+	for i, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%d: want %v but got %v", i, tt.want, tt.got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package opennebula is a client for the XML-RPC API of OpenNebula, whose
+// methods are in the one namespace.
+package opennebula
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Error codes of failed calls.
+const (
+	Authentication = 0x0100
+	Authorization  = 0x0200
+	NoExists       = 0x0400
+	Action         = 0x0800
+	XMLRPCAPI      = 0x1000
+	Internal       = 0x2000
+	Allocate       = 0x4000
+	Locked         = 0x8000
+)
+
+// Error is a call which failed.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("opennebula: %s (0x%04x)", e.Message, e.Code)
+}
+
+// Client calls the methods of an OpenNebula.
+type Client struct {
+	c       *xmlrpc.Client
+	session string
+}
+
+// New returns a Client of the OpenNebula at url, e.g.
+// http://frontend:2633/RPC2, calling as username with password, or a
+// login token in place of the password.
+func New(url, username, password string, opts ...xmlrpc.Option) *Client {
+	return &Client{
+		c:       xmlrpc.NewClient(url, opts...),
+		session: username + ":" + password,
+	}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Call calls the method name with the session and args. OpenNebula
+// responds with an array of whether the call succeeded, its result or
+// error message, and its error code; Call returns the result, or the
+// message and code as *Error.
+func (c *Client) Call(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	v, err := c.c.CallContext(ctx, name, append([]interface{}{c.session}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := v.(xmlrpc.Array)
+	if !ok || len(r) < 2 {
+		return nil, fmt.Errorf("opennebula: unexpected response %v", v)
+	}
+	if ok, _ := r[0].(bool); !ok {
+		e := &Error{}
+		e.Message, _ = r[1].(string)
+		if len(r) > 2 {
+			e.Code, _ = r[2].(int)
+		}
+		return nil, e
+	}
+	return r[1], nil
+}
+
+// callXML calls the method name, whose result is an XML document, and
+// decodes the document into dst.
+func (c *Client) callXML(ctx context.Context, dst interface{}, name string, args ...interface{}) error {
+	v, err := c.Call(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("opennebula: %s: want XML but got %v", name, v)
+	}
+	return xml.Unmarshal([]byte(s), dst)
+}
+
+// VM is a virtual machine, as described in the VM element of the XML
+// documents of OpenNebula.
+type VM struct {
+	ID       int    `xml:"ID"`
+	UID      int    `xml:"UID"`
+	GID      int    `xml:"GID"`
+	UName    string `xml:"UNAME"`
+	GName    string `xml:"GNAME"`
+	Name     string `xml:"NAME"`
+	State    int    `xml:"STATE"`
+	LCMState int    `xml:"LCM_STATE"`
+	STime    int64  `xml:"STIME"` // Unix time
+	ETime    int64  `xml:"ETIME"`
+	DeployID string `xml:"DEPLOY_ID"`
+}
+
+// States of VMs.
+const (
+	StateInit       = 0
+	StatePending    = 1
+	StateHold       = 2
+	StateActive     = 3
+	StateStopped    = 4
+	StateSuspended  = 5
+	StateDone       = 6
+	StatePoweroff   = 8
+	StateUndeployed = 9
+)
+
+// VMInfo returns the VM id.
+func (c *Client) VMInfo(ctx context.Context, id int) (*VM, error) {
+	var vm VM
+	if err := c.callXML(ctx, &vm, "one.vm.info", id); err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
+
+// Filters of VM pools, or IDs of users and groups.
+const (
+	FilterPrimaryGroup = -4
+	FilterMine         = -3
+	FilterAll          = -2
+	FilterMineAndGroup = -1
+)
+
+// StateAny selects VMs of any state but Done.
+const StateAny = -1
+
+// VMPoolInfo returns the VMs selected by filter which are in state, of the
+// IDs start to end, or all of them if both are -1.
+func (c *Client) VMPoolInfo(ctx context.Context, filter, start, end, state int) ([]VM, error) {
+	var pool struct {
+		VMs []VM `xml:"VM"`
+	}
+	if err := c.callXML(ctx, &pool, "one.vmpool.info", filter, start, end, state); err != nil {
+		return nil, err
+	}
+	return pool.VMs, nil
+}
+
+// VMAction performs action, such as "terminate", "poweroff" or "resume",
+// on the VM id.
+func (c *Client) VMAction(ctx context.Context, action string, id int) error {
+	_, err := c.Call(ctx, "one.vm.action", action, id)
+	return err
+}
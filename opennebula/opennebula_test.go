@@ -0,0 +1,49 @@
+package opennebula
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+const poolXML = `<VM_POOL><VM><ID>7</ID><UID>0</UID><GID>0</GID><UNAME>oneadmin</UNAME><GNAME>oneadmin</GNAME><NAME>web</NAME><STATE>3</STATE><LCM_STATE>3</LCM_STATE><STIME>1700000000</STIME><ETIME>0</ETIME><DEPLOY_ID>one-7</DEPLOY_ID><TEMPLATE><CPU>1</CPU></TEMPLATE></VM><VM><ID>8</ID><NAME>db</NAME><STATE>8</STATE></VM></VM_POOL>`
+
+func TestVMs(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"one.vmpool.info": xmlrpctest.Value(xmlrpc.Array{true, poolXML, 0}),
+		"one.vm.info":     xmlrpctest.Value(xmlrpc.Array{false, "[one.vm.info] Error getting virtual machine [9].", NoExists, 9}),
+		"one.vm.action":   xmlrpctest.Value(xmlrpc.Array{true, 7, 0}),
+	})
+	defer s.Close()
+	c := New(s.URL, "oneadmin", "secret")
+	ctx := context.Background()
+
+	vms, err := c.VMPoolInfo(ctx, FilterAll, -1, -1, StateAny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "one.vmpool.info", "oneadmin:secret", FilterAll, -1, -1, StateAny)
+	if len(vms) != 2 {
+		t.Fatalf("want 2 VMs but got %d", len(vms))
+	}
+	if vm := vms[0]; vm.ID != 7 || vm.Name != "web" || vm.State != StateActive || vm.STime != 1700000000 || vm.DeployID != "one-7" {
+		t.Fatalf("unexpected VM %+v", vm)
+	}
+	if vms[1].State != StatePoweroff {
+		t.Fatalf("unexpected VM %+v", vms[1])
+	}
+
+	_, err = c.VMInfo(ctx, 9)
+	var e *Error
+	if !errors.As(err, &e) || e.Code != NoExists {
+		t.Fatalf("want NoExists error but got %v", err)
+	}
+
+	if err := c.VMAction(ctx, "poweroff", 7); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "one.vm.action", "oneadmin:secret", "poweroff", 7)
+}
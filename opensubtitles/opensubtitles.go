@@ -0,0 +1,205 @@
+// Package opensubtitles is a client for the legacy XML-RPC API of
+// OpenSubtitles.org.
+package opensubtitles
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// DefaultURL is the endpoint of the API.
+const DefaultURL = "https://api.opensubtitles.org/xml-rpc"
+
+// StatusError is returned for responses whose status isn't 200 OK, e.g.
+// "401 Unauthorized" or "414 Unknown User Agent".
+type StatusError struct {
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return "opensubtitles: " + e.Status
+}
+
+// Client calls the methods of the API. It must log in before searching or
+// downloading subtitles.
+type Client struct {
+	c         *xmlrpc.Client
+	userAgent string
+	token     string
+}
+
+// New returns a Client of the API at url, usually DefaultURL, identifying
+// as userAgent, which must be registered with OpenSubtitles.
+func New(url, userAgent string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...), userAgent: userAgent}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Token returns the session token, empty if not logged in.
+func (c *Client) Token() string {
+	return c.token
+}
+
+// call calls the method name, checking the status of the result and
+// storing it in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	st, ok := v.(xmlrpc.Struct)
+	if !ok {
+		return fmt.Errorf("opensubtitles: %s returned %T, not a struct", name, v)
+	}
+	if status, _ := st["status"].(string); !strings.HasPrefix(status, "200") {
+		return &StatusError{Status: status}
+	}
+	if r == nil {
+		return nil
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// LogIn starts a session, anonymous if username and password are empty.
+// language is the ISO 639 code of the language of messages, e.g. "en".
+func (c *Client) LogIn(ctx context.Context, username, password, language string) error {
+	if c.userAgent == "" {
+		return errors.New("opensubtitles: a user agent is required")
+	}
+	var r struct {
+		Token string `xmlrpc:"token"`
+	}
+	if err := c.call(ctx, &r, "LogIn", username, password, language, c.userAgent); err != nil {
+		return err
+	}
+	c.token = r.Token
+	return nil
+}
+
+// LogOut ends the session.
+func (c *Client) LogOut(ctx context.Context) error {
+	err := c.call(ctx, nil, "LogOut", c.token)
+	c.token = ""
+	return err
+}
+
+// Query selects subtitles to search for, by the hash and size of a movie
+// file, by IMDb ID or by text.
+type Query struct {
+	Languages string `xmlrpc:"sublanguageid,omitempty"` // comma separated, e.g. "eng,fre"
+	MovieHash string `xmlrpc:"moviehash,omitempty"`
+	MovieSize int64  `xmlrpc:"moviebytesize,omitempty"`
+	IMDbID    string `xmlrpc:"imdbid,omitempty"`
+	Query     string `xmlrpc:"query,omitempty"`
+	Season    int    `xmlrpc:"season,omitempty"`
+	Episode   int    `xmlrpc:"episode,omitempty"`
+	Tag       string `xmlrpc:"tag,omitempty"`
+}
+
+// Subtitle is a subtitle file found by SearchSubtitles. Like the API,
+// it holds numbers as strings.
+type Subtitle struct {
+	ID           string `xmlrpc:"IDSubtitleFile"`
+	FileName     string `xmlrpc:"SubFileName"`
+	Language     string `xmlrpc:"SubLanguageID"`
+	LanguageName string `xmlrpc:"LanguageName"`
+	Format       string `xmlrpc:"SubFormat"`
+	Encoding     string `xmlrpc:"SubEncoding"`
+	Rating       string `xmlrpc:"SubRating"`
+	Downloads    string `xmlrpc:"SubDownloadsCnt"`
+	MovieName    string `xmlrpc:"MovieName"`
+	MovieYear    string `xmlrpc:"MovieYear"`
+	IMDbID       string `xmlrpc:"IDMovieImdb"`
+	MovieHash    string `xmlrpc:"MovieHash"`
+	DownloadLink string `xmlrpc:"SubDownloadLink"`
+	ZipLink      string `xmlrpc:"ZipDownloadLink"`
+}
+
+// SearchSubtitles returns up to limit subtitles matching any of queries.
+func (c *Client) SearchSubtitles(ctx context.Context, queries []Query, limit int) ([]Subtitle, error) {
+	var r struct {
+		Data interface{} `xmlrpc:"data"`
+	}
+	if err := c.call(ctx, &r, "SearchSubtitles", c.token, queries, xmlrpc.Struct{"limit": limit}); err != nil {
+		return nil, err
+	}
+	// data is false rather than an empty array if nothing was found.
+	a, ok := r.Data.(xmlrpc.Array)
+	if !ok {
+		return nil, nil
+	}
+	var subs []Subtitle
+	err := xmlrpc.Unmarshal(a, &subs)
+	return subs, err
+}
+
+// DownloadSubtitles returns the contents of the subtitle files ids by ID.
+// The API sends them gzipped and base64 encoded; they are returned
+// decoded.
+func (c *Client) DownloadSubtitles(ctx context.Context, ids ...string) (map[string][]byte, error) {
+	var r struct {
+		Data []struct {
+			ID   string `xmlrpc:"idsubtitlefile"`
+			Data string `xmlrpc:"data"`
+		} `xmlrpc:"data"`
+	}
+	if err := c.call(ctx, &r, "DownloadSubtitles", c.token, ids); err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte, len(r.Data))
+	for _, d := range r.Data {
+		zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(d.Data)))
+		if err != nil {
+			return nil, fmt.Errorf("opensubtitles: subtitle %s: %v", d.ID, err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("opensubtitles: subtitle %s: %v", d.ID, err)
+		}
+		files[d.ID] = b
+	}
+	return files, nil
+}
+
+// hashChunk is the size of the chunks at either end of a file MovieHash
+// sums up.
+const hashChunk = 64 << 10
+
+// MovieHash returns the OpenSubtitles hash of the movie file r and its
+// size, as used in a Query.
+func MovieHash(r io.ReadSeeker) (string, int64, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", 0, err
+	}
+	if size < hashChunk {
+		return "", 0, errors.New("opensubtitles: file too small to hash")
+	}
+	hash := uint64(size)
+	buf := make([]byte, hashChunk)
+	for _, off := range []int64{0, size - hashChunk} {
+		if _, err := r.Seek(off, io.SeekStart); err != nil {
+			return "", 0, err
+		}
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", 0, err
+		}
+		for i := 0; i < hashChunk; i += 8 {
+			hash += binary.LittleEndian.Uint64(buf[i:])
+		}
+	}
+	return fmt.Sprintf("%016x", hash), size, nil
+}
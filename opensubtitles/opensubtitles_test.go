@@ -0,0 +1,102 @@
+package opensubtitles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestSearchAndDownload(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("1\n00:00:01,000 --> 00:00:02,000\nHello\n"))
+	zw.Close()
+
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"LogIn": xmlrpctest.Value(xmlrpc.Struct{"token": "tok", "status": "200 OK", "seconds": 0.1}),
+		"SearchSubtitles": xmlrpctest.Value(xmlrpc.Struct{"status": "200 OK", "data": xmlrpc.Array{
+			xmlrpc.Struct{"IDSubtitleFile": "42", "SubFileName": "movie.srt", "SubLanguageID": "eng", "SubRating": "8.0"},
+		}}),
+		"DownloadSubtitles": xmlrpctest.Value(xmlrpc.Struct{"status": "200 OK", "data": xmlrpc.Array{
+			xmlrpc.Struct{"idsubtitlefile": "42", "data": base64.StdEncoding.EncodeToString(gz.Bytes())},
+		}}),
+	})
+	defer s.Close()
+	c := New(s.URL, "MyApp v1")
+	ctx := context.Background()
+
+	if err := c.LogIn(ctx, "", "", "en"); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "LogIn", "", "", "en", "MyApp v1")
+	if c.Token() != "tok" {
+		t.Fatalf("unexpected token %q", c.Token())
+	}
+
+	subs, err := c.SearchSubtitles(ctx, []Query{{Languages: "eng", Query: "movie"}}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].ID != "42" || subs[0].FileName != "movie.srt" {
+		t.Fatalf("unexpected subtitles: %+v", subs)
+	}
+	s.AssertCalled(t, "SearchSubtitles", "tok", xmlrpc.Array{xmlrpc.Struct{"sublanguageid": "eng", "query": "movie"}}, xmlrpc.Struct{"limit": 10})
+
+	files, err := c.DownloadSubtitles(ctx, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(files["42"]) != "1\n00:00:01,000 --> 00:00:02,000\nHello\n" {
+		t.Fatalf("unexpected subtitle %q", files["42"])
+	}
+}
+
+func TestSearchNothingFound(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"SearchSubtitles": xmlrpctest.Value(xmlrpc.Struct{"status": "200 OK", "data": false}),
+	})
+	defer s.Close()
+
+	subs, err := New(s.URL, "MyApp v1").SearchSubtitles(context.Background(), []Query{{Query: "nothing"}}, 10)
+	if err != nil || subs != nil {
+		t.Fatalf("want no subtitles but got %v, %v", subs, err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"LogIn": xmlrpctest.Value(xmlrpc.Struct{"status": "414 Unknown User Agent"}),
+	})
+	defer s.Close()
+
+	err := New(s.URL, "Unknown").LogIn(context.Background(), "", "", "en")
+	var se *StatusError
+	if !errors.As(err, &se) || se.Status != "414 Unknown User Agent" {
+		t.Fatalf("want StatusError but got %v", err)
+	}
+	if err := New(s.URL, "").LogIn(context.Background(), "", "", "en"); err == nil {
+		t.Fatal("want error without user agent")
+	}
+}
+
+func TestMovieHash(t *testing.T) {
+	data := make([]byte, 3*hashChunk)
+	data[0] = 1
+	data[len(data)-8] = 2
+	hash, size, err := MovieHash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(data)) || hash != "0000000000030003" {
+		t.Fatalf("unexpected hash %s of size %d", hash, size)
+	}
+	if _, _, err := MovieHash(bytes.NewReader(data[:100])); err == nil {
+		t.Fatal("want error for small file")
+	}
+}
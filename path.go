@@ -0,0 +1,200 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// step is an element of a path: a struct member name or an array index.
+type step struct {
+	name  string
+	index int  // -1 for member names
+	all   bool // [*] or *, only allowed by Select
+}
+
+// parsePath splits a path such as posts[2].title into its steps.
+func parsePath(path string) ([]step, error) {
+	var steps []step
+	for i := 0; i < len(path); {
+		if path[i] == '[' {
+			j := strings.IndexByte(path[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("xmlrpc: invalid path %q: missing ]", path)
+			}
+			if path[i+1:i+j] == "*" {
+				steps = append(steps, step{all: true})
+				i += j + 1
+				continue
+			}
+			n, err := strconv.Atoi(path[i+1 : i+j])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("xmlrpc: invalid path %q: bad index %q", path, path[i+1:i+j])
+			}
+			steps = append(steps, step{index: n})
+			i += j + 1
+			continue
+		}
+		if len(steps) > 0 {
+			if path[i] != '.' {
+				return nil, fmt.Errorf("xmlrpc: invalid path %q: want . or [ at %d", path, i)
+			}
+			i++
+		}
+		j := strings.IndexAny(path[i:], ".[")
+		if j < 0 {
+			j = len(path) - i
+		}
+		if j == 0 {
+			return nil, fmt.Errorf("xmlrpc: invalid path %q: empty name at %d", path, i)
+		}
+		name := path[i : i+j]
+		steps = append(steps, step{name: name, index: -1, all: name == "*"})
+		i += j
+	}
+	return steps, nil
+}
+
+// lookupPath returns the value at path in v.
+func lookupPath(v interface{}, path string) (interface{}, bool) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, false
+	}
+	for _, s := range steps {
+		if s.all {
+			return nil, false
+		}
+		if s.index < 0 {
+			st, ok := v.(Struct)
+			if !ok {
+				return nil, false
+			}
+			if v, ok = st[s.name]; !ok {
+				return nil, false
+			}
+		} else {
+			a, ok := v.(Array)
+			if !ok || s.index >= len(a) {
+				return nil, false
+			}
+			v = a[s.index]
+		}
+	}
+	return v, true
+}
+
+// Get returns the value at path, a dotted list of member names in which
+// array elements are selected by index, e.g. "post.categories[0]".
+func (s Struct) Get(path string) (interface{}, bool) {
+	return lookupPath(s, path)
+}
+
+// GetString returns the string at path.
+func (s Struct) GetString(path string) (string, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.(string)
+	return r, ok
+}
+
+// GetInt returns the int at path.
+func (s Struct) GetInt(path string) (int, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.(int)
+	return r, ok
+}
+
+// GetFloat returns the double at path. An int is converted.
+func (s Struct) GetFloat(path string) (float64, bool) {
+	v, _ := s.Get(path)
+	return toFloat(v)
+}
+
+// GetBool returns the boolean at path.
+func (s Struct) GetBool(path string) (bool, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.(bool)
+	return r, ok
+}
+
+// GetTime returns the dateTime at path.
+func (s Struct) GetTime(path string) (time.Time, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.(time.Time)
+	return r, ok
+}
+
+// GetBytes returns the base64 value at path.
+func (s Struct) GetBytes(path string) ([]byte, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.([]byte)
+	return r, ok
+}
+
+// GetStruct returns the struct at path.
+func (s Struct) GetStruct(path string) (Struct, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.(Struct)
+	return r, ok
+}
+
+// GetArray returns the array at path.
+func (s Struct) GetArray(path string) (Array, bool) {
+	v, _ := s.Get(path)
+	r, ok := v.(Array)
+	return r, ok
+}
+
+// Get returns the value at path, which starts with an index, e.g.
+// "[0].title". See Struct.Get.
+func (a Array) Get(path string) (interface{}, bool) {
+	return lookupPath(a, path)
+}
+
+// At returns the element i.
+func (a Array) At(i int) (interface{}, bool) {
+	if i < 0 || i >= len(a) {
+		return nil, false
+	}
+	return a[i], true
+}
+
+// StructAt returns the element i if it is a struct.
+func (a Array) StructAt(i int) (Struct, bool) {
+	v, _ := a.At(i)
+	r, ok := v.(Struct)
+	return r, ok
+}
+
+// ArrayAt returns the element i if it is an array.
+func (a Array) ArrayAt(i int) (Array, bool) {
+	v, _ := a.At(i)
+	r, ok := v.(Array)
+	return r, ok
+}
+
+// StringAt returns the element i if it is a string.
+func (a Array) StringAt(i int) (string, bool) {
+	v, _ := a.At(i)
+	r, ok := v.(string)
+	return r, ok
+}
+
+// IntAt returns the element i if it is an int.
+func (a Array) IntAt(i int) (int, bool) {
+	v, _ := a.At(i)
+	r, ok := v.(int)
+	return r, ok
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int, int32, int64:
+		n, _ := toInt64(v)
+		return float64(n), true
+	}
+	return 0, false
+}
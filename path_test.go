@@ -0,0 +1,81 @@
+package xmlrpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStructGet(t *testing.T) {
+	tm := time.Date(1998, 7, 17, 14, 8, 55, 0, time.UTC)
+	s := Struct{
+		"post": Struct{
+			"title":      "hello",
+			"id":         42,
+			"score":      1.5,
+			"draft":      true,
+			"date":       tm,
+			"categories": Array{"go", Struct{"name": "xml"}},
+		},
+	}
+	if v, ok := s.GetString("post.title"); !ok || v != "hello" {
+		t.Errorf("post.title: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetInt("post.id"); !ok || v != 42 {
+		t.Errorf("post.id: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetFloat("post.id"); !ok || v != 42 {
+		t.Errorf("post.id as float: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetFloat("post.score"); !ok || v != 1.5 {
+		t.Errorf("post.score: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetBool("post.draft"); !ok || !v {
+		t.Errorf("post.draft: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetTime("post.date"); !ok || !v.Equal(tm) {
+		t.Errorf("post.date: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetString("post.categories[0]"); !ok || v != "go" {
+		t.Errorf("post.categories[0]: got %v, %v", v, ok)
+	}
+	if v, ok := s.GetString("post.categories[1].name"); !ok || v != "xml" {
+		t.Errorf("post.categories[1].name: got %v, %v", v, ok)
+	}
+	if a, ok := s.GetArray("post.categories"); !ok || len(a) != 2 {
+		t.Errorf("post.categories: got %v, %v", a, ok)
+	}
+
+	for _, path := range []string{"post.missing", "post.title.x", "post.categories[2]", "post.id[0]", "post..title", "post.", ".post", "post.categories[0]x", "post.categories[x]", "post.categories[0"} {
+		if v, ok := s.Get(path); ok {
+			t.Errorf("%s: want not found but got %v", path, v)
+		}
+	}
+	if _, ok := s.GetInt("post.title"); ok {
+		t.Error("post.title: want type mismatch")
+	}
+}
+
+func TestArrayAt(t *testing.T) {
+	a := Array{Struct{"title": "a"}, Array{1}, "s", 3}
+	if st, ok := a.StructAt(0); !ok || st["title"] != "a" {
+		t.Errorf("StructAt(0): got %v, %v", st, ok)
+	}
+	if v, ok := a.ArrayAt(1); !ok || len(v) != 1 {
+		t.Errorf("ArrayAt(1): got %v, %v", v, ok)
+	}
+	if v, ok := a.StringAt(2); !ok || v != "s" {
+		t.Errorf("StringAt(2): got %v, %v", v, ok)
+	}
+	if v, ok := a.IntAt(3); !ok || v != 3 {
+		t.Errorf("IntAt(3): got %v, %v", v, ok)
+	}
+	if _, ok := a.StructAt(1); ok {
+		t.Error("StructAt(1): want type mismatch")
+	}
+	if _, ok := a.At(4); ok {
+		t.Error("At(4): want out of range")
+	}
+	if v, ok := a.Get("[0].title"); !ok || v != "a" {
+		t.Errorf("[0].title: got %v, %v", v, ok)
+	}
+}
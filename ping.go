@@ -0,0 +1,62 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Probe checks whether the server of a client is reachable, for Ping.
+type Probe func(ctx context.Context, c *Client) error
+
+// ProbeHEAD sends a HEAD request to the endpoint. Any response but a
+// server error counts as reachable, as most servers only allow POST.
+func ProbeHEAD(ctx context.Context, c *Client) error {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", c.url, nil)
+	if err != nil {
+		return err
+	}
+	r, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	if r.StatusCode >= 500 {
+		return &StatusError{StatusCode: r.StatusCode}
+	}
+	return nil
+}
+
+// ProbeMethod returns a Probe which calls the method name with args. A
+// fault counts as reachable, as the server answered.
+func ProbeMethod(name string, args ...interface{}) Probe {
+	return func(ctx context.Context, c *Client) error {
+		body, stream, err := c.encodeRequest(name, args)
+		if err == nil {
+			_, err = c.do(ctx, c.url, name, body, stream, (*decoder).response)
+		}
+		var f *Fault
+		if errors.As(err, &f) {
+			return nil
+		}
+		return err
+	}
+}
+
+// ProbeListMethods calls system.listMethods, which most servers implement
+// cheaply.
+var ProbeListMethods = ProbeMethod("system.listMethods")
+
+// Ping checks whether the server is reachable with probe, or
+// ProbeListMethods if nil, making a single attempt regardless of the retry
+// policy. It returns the latency of the probe and why the server is
+// unreachable, if it is.
+func (c *Client) Ping(ctx context.Context, probe Probe) (time.Duration, error) {
+	if probe == nil {
+		probe = ProbeListMethods
+	}
+	start := time.Now()
+	err := probe(ctx, c)
+	return time.Since(start), err
+}
@@ -0,0 +1,45 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	s := NewServer()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+	ctx := context.Background()
+
+	for _, probe := range []Probe{nil, ProbeHEAD, ProbeMethod("missing")} {
+		d, err := c.Ping(ctx, probe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d <= 0 {
+			t.Fatalf("want latency but got %v", d)
+		}
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	c = NewClient(down.URL, WithRetry(RetryPolicy{MaxAttempts: 3}))
+	for _, probe := range []Probe{nil, ProbeHEAD} {
+		_, err := c.Ping(ctx, probe)
+		var se *StatusError
+		if !errors.As(err, &se) || se.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("want status 503 but got %v", err)
+		}
+	}
+
+	down.Close()
+	if _, err := c.Ping(ctx, ProbeHEAD); err == nil {
+		t.Fatal("want error for closed server")
+	}
+}
@@ -0,0 +1,60 @@
+package pingback
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// ErrNotSupported is returned by Discover and SendPingback for targets
+// which don't advertise a pingback server.
+var ErrNotSupported = errors.New("pingback: target does not support pingbacks")
+
+// linkPattern is the regular expression for link elements given by the
+// specification.
+var linkPattern = regexp.MustCompile(`<link rel="pingback" href="([^"]+)" ?/?>`)
+
+// Discover returns the URL of the pingback server of the page at target,
+// from its X-Pingback header or its pingback link element.
+func Discover(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if server := res.Header.Get("X-Pingback"); server != "" {
+		return server, nil
+	}
+	if res.StatusCode/100 != 2 {
+		return "", errors.New("pingback: target returned " + res.Status)
+	}
+	page, err := io.ReadAll(io.LimitReader(res.Body, maxPage))
+	if err != nil {
+		return "", err
+	}
+	if m := linkPattern.FindSubmatch(page); m != nil {
+		return html.UnescapeString(string(m[1])), nil
+	}
+	return "", ErrNotSupported
+}
+
+// SendPingback notifies the pingback server of target, found by Discover,
+// that the page at source links to it. Faults of the server are returned
+// as *xmlrpc.Fault with one of the fault codes of this package.
+func SendPingback(ctx context.Context, source, target string, opts ...xmlrpc.Option) error {
+	server, err := Discover(ctx, target)
+	if err != nil {
+		return err
+	}
+	_, err = xmlrpc.NewClient(server, opts...).CallContext(ctx, "pingback.ping", source, target)
+	return err
+}
@@ -0,0 +1,56 @@
+package pingback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestSendPingback(t *testing.T) {
+	var got []string
+	s := xmlrpc.NewServer()
+	s.RegisterFunc("pingback.ping", func(source, target string) (string, error) {
+		got = append(got, source, target)
+		return "ok", nil
+	})
+	rpc := httptest.NewServer(s)
+	defer rpc.Close()
+
+	pages := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/header":
+			w.Header().Set("X-Pingback", rpc.URL)
+		case "/link":
+			w.Write([]byte(`<html><head><link rel="pingback" href="` + rpc.URL + `/?a=1&amp;b=2" /></head></html>`))
+		case "/none":
+			w.Write([]byte(`<html></html>`))
+		}
+	}))
+	defer pages.Close()
+
+	ctx := context.Background()
+	server, err := Discover(ctx, pages.URL+"/link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := rpc.URL + "/?a=1&b=2"; server != want {
+		t.Fatalf("want %q but got %q", want, server)
+	}
+
+	for _, path := range []string{"/header", "/link"} {
+		got = nil
+		if err := SendPingback(ctx, "http://example.com/src", pages.URL+path); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if len(got) != 2 || got[0] != "http://example.com/src" || got[1] != pages.URL+path {
+			t.Fatalf("%s: unexpected ping %v", path, got)
+		}
+	}
+	if err := SendPingback(ctx, "http://example.com/src", pages.URL+"/none"); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("want ErrNotSupported but got %v", err)
+	}
+}
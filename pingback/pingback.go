@@ -0,0 +1,123 @@
+// Package pingback implements Pingback 1.0, the XML-RPC protocol blogs use
+// to notify each other of links, as specified at
+// http://www.hixie.ch/specs/pingback/pingback.
+package pingback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Fault codes defined by the specification.
+const (
+	Generic            = 0x0000
+	SourceNotFound     = 0x0010 // the source URI does not exist
+	NoLinkToTarget     = 0x0011 // the source doesn't link to the target
+	TargetNotFound     = 0x0020 // the target URI does not exist
+	TargetNotSupported = 0x0021 // the target can't be pinged back
+	AlreadyRegistered  = 0x0030
+	AccessDenied       = 0x0031
+	UpstreamError      = 0x0032 // the server couldn't reach the source
+)
+
+// maxPage is the size up to which source pages are searched for links.
+const maxPage = 1 << 20
+
+// Receiver implements the pingback.ping method of a site accepting
+// pingbacks. Its callbacks may return an *xmlrpc.Fault with one of the
+// fault codes of this package; other errors are reported with code Generic.
+type Receiver struct {
+	// ValidateTarget reports whether target is a resource of the site
+	// accepting pingbacks. It is required.
+	ValidateTarget func(ctx context.Context, target string) error
+
+	// ValidateSource, if set, is called with the page at source once it
+	// is known to link to target, e.g. to filter spam.
+	ValidateSource func(ctx context.Context, source, target string, page []byte) error
+
+	// Record records the pingback. It is required.
+	Record func(ctx context.Context, source, target string) error
+
+	// Client is used to fetch source pages. If nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+}
+
+// Register registers the pingback.ping method with r.
+func (rc *Receiver) Register(r *xmlrpc.Registry) {
+	r.RegisterFunc("pingback.ping", rc.ping)
+}
+
+func (rc *Receiver) ping(ctx context.Context, source, target string) (string, error) {
+	if err := rc.ValidateTarget(ctx, target); err != nil {
+		return "", toFault(err)
+	}
+	page, err := rc.fetch(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	if !linksTo(page, target) {
+		return "", &xmlrpc.Fault{Code: NoLinkToTarget, String: "source does not link to target"}
+	}
+	if rc.ValidateSource != nil {
+		if err := rc.ValidateSource(ctx, source, target, page); err != nil {
+			return "", toFault(err)
+		}
+	}
+	if err := rc.Record(ctx, source, target); err != nil {
+		return "", toFault(err)
+	}
+	return fmt.Sprintf("Pingback from %s to %s registered.", source, target), nil
+}
+
+func (rc *Receiver) fetch(ctx context.Context, source string) ([]byte, error) {
+	client := rc.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", source, nil)
+	if err != nil {
+		return nil, &xmlrpc.Fault{Code: SourceNotFound, String: err.Error()}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, &xmlrpc.Fault{Code: UpstreamError, String: err.Error()}
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return nil, &xmlrpc.Fault{Code: SourceNotFound, String: "source returned " + res.Status}
+	}
+	page, err := io.ReadAll(io.LimitReader(res.Body, maxPage))
+	if err != nil {
+		return nil, &xmlrpc.Fault{Code: UpstreamError, String: err.Error()}
+	}
+	return page, nil
+}
+
+var hrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// linksTo reports whether the HTML page has a link to target.
+func linksTo(page []byte, target string) bool {
+	for _, m := range hrefPattern.FindAllSubmatch(page, -1) {
+		href := string(m[1]) + string(m[2]) + string(m[3])
+		if html.UnescapeString(href) == target {
+			return true
+		}
+	}
+	return false
+}
+
+func toFault(err error) error {
+	var f *xmlrpc.Fault
+	if errors.As(err, &f) {
+		return f
+	}
+	return &xmlrpc.Fault{Code: Generic, String: err.Error()}
+}
@@ -0,0 +1,75 @@
+package pingback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestReceiver(t *testing.T) {
+	pages := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/linking":
+			w.Write([]byte(`<p>See <a class=x href="http://example.com/post?a=1&amp;b=2">this</a>.</p>`))
+		case "/other":
+			w.Write([]byte(`<p><a href='http://example.com/'>home</a></p>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer pages.Close()
+
+	const target = "http://example.com/post?a=1&b=2"
+	var recorded []string
+	rc := &Receiver{
+		ValidateTarget: func(ctx context.Context, t string) error {
+			if t != target {
+				return &xmlrpc.Fault{Code: TargetNotFound, String: "no such post"}
+			}
+			return nil
+		},
+		Record: func(ctx context.Context, source, target string) error {
+			for _, s := range recorded {
+				if s == source {
+					return &xmlrpc.Fault{Code: AlreadyRegistered, String: "already registered"}
+				}
+			}
+			recorded = append(recorded, source)
+			return nil
+		},
+	}
+	s := xmlrpc.NewServer()
+	rc.Register(&s.Registry)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := xmlrpc.NewClient(ts.URL)
+
+	tests := []struct {
+		source, target string
+		code           int
+	}{
+		{pages.URL + "/linking", target, -1},
+		{pages.URL + "/linking", target, AlreadyRegistered},
+		{pages.URL + "/other", target, NoLinkToTarget},
+		{pages.URL + "/missing", target, SourceNotFound},
+		{pages.URL + "/linking", "http://example.com/nope", TargetNotFound},
+	}
+	for _, tt := range tests {
+		_, err := c.Call("pingback.ping", tt.source, tt.target)
+		var f *xmlrpc.Fault
+		if tt.code < 0 {
+			if err != nil {
+				t.Fatalf("%s: %v", tt.source, err)
+			}
+		} else if !errors.As(err, &f) || f.Code != tt.code {
+			t.Errorf("%s -> %s: want fault %d but got %v", tt.source, tt.target, tt.code, err)
+		}
+	}
+	if len(recorded) != 1 {
+		t.Fatalf("want 1 pingback recorded but got %v", recorded)
+	}
+}
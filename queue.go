@@ -0,0 +1,257 @@
+package xmlrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// QueueEntry is a call waiting in a Queue.
+type QueueEntry struct {
+	ID       uint64
+	Method   string
+	Body     []byte // the request
+	Attempts int
+	Next     time.Time // when to try again
+}
+
+// QueueStore persists the entries of a Queue. It must be safe for
+// concurrent use.
+type QueueStore interface {
+	// Load returns the saved entries.
+	Load() ([]QueueEntry, error)
+
+	// Save replaces the saved entries with entries.
+	Save(entries []QueueEntry) error
+}
+
+// FileQueueStore is a QueueStore keeping the entries as JSON in the file
+// Path, which is replaced atomically on saving.
+type FileQueueStore struct {
+	Path string
+}
+
+// Load implements QueueStore. A missing file holds no entries.
+func (s *FileQueueStore) Load() ([]QueueEntry, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []QueueEntry
+	err = json.Unmarshal(b, &entries)
+	return entries, err
+}
+
+// Save implements QueueStore.
+func (s *FileQueueStore) Save(entries []QueueEntry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.Path)
+}
+
+// Queue delivers fire-and-forget calls, such as pingbacks, through a
+// Client, keeping those which fail for lack of connectivity in a store
+// and trying them again with backoff, even after a restart. Calls answered
+// with a fault are delivered; retrying them wouldn't help.
+type Queue struct {
+	// Backoff is the delay before the first retry of a call. It doubles
+	// with each further retry, up to MaxBackoff. They default to a second
+	// and an hour.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// MaxAttempts, if positive, drops calls after as many attempts.
+	MaxAttempts int
+
+	// OnDrop, if set, is called with the calls which are dropped, because
+	// they were answered with a fault or made too many attempts, and the
+	// error of their last attempt.
+	OnDrop func(e QueueEntry, err error)
+
+	c       *Client
+	store   QueueStore
+	mu      sync.Mutex
+	entries []QueueEntry
+	lastID  uint64
+	wake    chan struct{}
+}
+
+// NewQueue returns a Queue of calls through c, loading the calls left in
+// store.
+func NewQueue(c *Client, store QueueStore) (*Queue, error) {
+	entries, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	q := &Queue{c: c, store: store, entries: entries, wake: make(chan struct{}, 1)}
+	for _, e := range entries {
+		if e.ID > q.lastID {
+			q.lastID = e.ID
+		}
+	}
+	return q, nil
+}
+
+// Call calls the method name with args, queuing the call if it fails
+// with an error other than a fault. It returns nil once the call was
+// delivered or queued, and the fault it was answered with if any.
+func (q *Queue) Call(ctx context.Context, name string, args ...interface{}) error {
+	body, stream, err := q.c.encodeRequest(name, args)
+	if err != nil {
+		return err
+	}
+	if stream != nil {
+		return errors.New("xmlrpc: calls with Base64Reader arguments can't be queued")
+	}
+	_, err = q.c.do(ctx, q.c.url, name, body, nil, (*decoder).response)
+	var f *Fault
+	if err == nil || errors.As(err, &f) {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastID++
+	q.entries = append(q.entries, QueueEntry{
+		ID:       q.lastID,
+		Method:   name,
+		Body:     body,
+		Attempts: 1,
+		Next:     time.Now().Add(q.backoff(1)),
+	})
+	if err := q.store.Save(q.entries); err != nil {
+		q.entries = q.entries[:len(q.entries)-1]
+		return err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Len returns the number of queued calls.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Flush tries the queued calls which are due, or all of them if all is
+// set, e.g. once connectivity returns. It returns the error of saving the
+// queue, if any.
+func (q *Queue) Flush(ctx context.Context, all bool) error {
+	q.mu.Lock()
+	var due []QueueEntry
+	now := time.Now()
+	for _, e := range q.entries {
+		if all || !e.Next.After(now) {
+			due = append(due, e)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, e := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		_, err := q.c.do(ctx, q.c.url, e.Method, e.Body, nil, (*decoder).response)
+		if ctx.Err() != nil {
+			break
+		}
+		var f *Fault
+		delivered := err == nil || errors.As(err, &f)
+		e.Attempts++
+		drop := delivered || q.MaxAttempts > 0 && e.Attempts >= q.MaxAttempts
+		if err := q.update(e, drop); err != nil {
+			return err
+		}
+		if drop && err != nil && q.OnDrop != nil {
+			q.OnDrop(e, err)
+		}
+	}
+	return nil
+}
+
+// update replaces the entry of e after an attempt, or removes it if drop
+// is set, and saves the queue.
+func (q *Queue) update(e QueueEntry, drop bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.entries {
+		if q.entries[i].ID != e.ID {
+			continue
+		}
+		if drop {
+			q.entries = append(q.entries[:i], q.entries[i+1:]...)
+		} else {
+			e.Next = time.Now().Add(q.backoff(e.Attempts))
+			q.entries[i] = e
+		}
+		return q.store.Save(q.entries)
+	}
+	return nil
+}
+
+// Run tries the queued calls as they become due until ctx is done.
+func (q *Queue) Run(ctx context.Context) error {
+	for {
+		if err := q.Flush(ctx, false); err != nil {
+			return err
+		}
+		d := q.MaxBackoff
+		if d <= 0 {
+			d = time.Hour
+		}
+		q.mu.Lock()
+		for _, e := range q.entries {
+			if until := time.Until(e.Next); until < d {
+				d = until
+			}
+		}
+		q.mu.Unlock()
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-q.wake:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
+
+// backoff returns the delay after the failed attempt n, counted from 1.
+func (q *Queue) backoff(n int) time.Duration {
+	p := RetryPolicy{Backoff: q.Backoff, MaxBackoff: q.MaxBackoff}
+	if p.Backoff <= 0 {
+		p.Backoff = time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = time.Hour
+	}
+	return p.backoff(n)
+}
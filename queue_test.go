@@ -0,0 +1,128 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	var up int32
+	var got []interface{}
+	s := NewServer()
+	s.Register("ping", func(args ...interface{}) (interface{}, error) {
+		got = append(got, args[0])
+		return true, nil
+	})
+	s.Register("reject", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 0x30, String: "already registered"}
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&up) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	store := &FileQueueStore{Path: filepath.Join(t.TempDir(), "queue.json")}
+	ctx := context.Background()
+
+	q, err := NewQueue(NewClient(ts.URL), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arg := range []string{"a", "b"} {
+		if err := q.Call(ctx, "ping", arg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Call(ctx, "reject", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 3 {
+		t.Fatalf("want 3 queued calls but got %d", q.Len())
+	}
+
+	// The queue survives a restart.
+	var dropped []QueueEntry
+	q, err = NewQueue(NewClient(ts.URL), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.OnDrop = func(e QueueEntry, err error) {
+		dropped = append(dropped, e)
+	}
+	if q.Len() != 3 {
+		t.Fatalf("want 3 loaded calls but got %d", q.Len())
+	}
+	if err := q.Flush(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 3 || len(got) != 0 {
+		t.Fatalf("want calls not due kept but got %d queued, %v delivered", q.Len(), got)
+	}
+
+	atomic.StoreInt32(&up, 1)
+	if err := q.Flush(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("want empty queue but got %d", q.Len())
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("want a and b delivered in order but got %v", got)
+	}
+	if len(dropped) != 1 || dropped[0].Method != "reject" {
+		t.Fatalf("want rejected call dropped but got %v", dropped)
+	}
+	if entries, _ := store.Load(); len(entries) != 0 {
+		t.Fatalf("want empty store but got %v", entries)
+	}
+	if err := q.Call(ctx, "reject", "d"); err == nil {
+		t.Fatal("want fault of delivered call")
+	}
+}
+
+func TestQueueRun(t *testing.T) {
+	var up, calls int32
+	s := NewServer()
+	s.Register("ping", func(args ...interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return true, nil
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&up) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	q, err := NewQueue(NewClient(ts.URL), &FileQueueStore{Path: filepath.Join(t.TempDir(), "queue.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Backoff = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	q.Call(ctx, "ping")
+	time.Sleep(30 * time.Millisecond)
+	atomic.StoreInt32(&up, 1)
+	for i := 0; q.Len() > 0; i++ {
+		if i > 200 {
+			t.Fatal("want queued call delivered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("want 1 delivered call but got %d", calls)
+	}
+}
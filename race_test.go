@@ -0,0 +1,94 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests of this file are meant to be run with -race.
+
+func raceServer(t *testing.T) *httptest.Server {
+	s := NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return Array(args), nil
+	})
+	s.Register("system.getCapabilities", func(args ...interface{}) (interface{}, error) {
+		return Struct{"i8": Struct{"specUrl": "", "specVersion": 1}}, nil
+	})
+	s.Register("system.multicall", func(args ...interface{}) (interface{}, error) {
+		calls, _ := args[0].(Array)
+		r := Array{}
+		for range calls {
+			r = append(r, Array{"ok"})
+		}
+		return r, nil
+	})
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// parallel runs fn n times concurrently.
+func parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRaceCalls(t *testing.T) {
+	ts := raceServer(t)
+	c := NewClient(ts.URL,
+		WithCache(NewMemoryCache(), time.Minute, "echo"),
+		WithCoalescing(),
+		WithMaxConcurrentCalls(4, QueueWhenLimited),
+		WithRetry(RetryPolicy{MaxAttempts: 2, Transport: true}),
+		WithCallInfo(func(*CallInfo) {}),
+		WithHeader("X-Test", "race"),
+	)
+	parallel(32, func(i int) {
+		var n []int
+		if _, err := c.CallContext(context.Background(), "echo", i%4, CallHeader("X-Call", "1"), CallResult(&n)); err != nil {
+			t.Error(err)
+		} else if len(n) != 1 || n[0] != i%4 {
+			t.Errorf("want [%d] but got %v", i%4, n)
+		}
+		if _, err := c.MultiCall(MethodCall{Name: "echo", Params: []interface{}{i}}); err != nil {
+			t.Error(err)
+		}
+		if _, err := c.Capabilities(); err != nil {
+			t.Error(err)
+		}
+		if _, err := c.Call("echo", int64(1)<<40); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestRaceReconfigure(t *testing.T) {
+	ts := raceServer(t)
+	c := NewClient(ts.URL)
+	parallel(32, func(i int) {
+		switch i % 3 {
+		case 0:
+			c.SetHTTPClient(&http.Client{Transport: http.DefaultTransport, Timeout: time.Duration(i+1) * time.Second})
+		case 1:
+			d := c.With(WithHeader("X-Tenant", "t"), WithTimeout(time.Minute))
+			if _, err := d.Call("echo", i); err != nil {
+				t.Error(err)
+			}
+		}
+		if _, err := c.Call("echo", i); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package xmlrpc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of methods. A Server embeds one, and registries can be
+// mounted on it under a prefix, so that the methods of a large server can
+// be registered by different packages.
+type Registry struct {
+	mu      sync.RWMutex
+	methods map[string]handler
+	sigs    map[string][]signature
+	mounts  []mount
+}
+
+type mount struct {
+	prefix string
+	r      *Registry
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register registers h as the implementation of the method name, replacing
+// any previous registration.
+func (r *Registry) Register(name string, h HandlerFunc) {
+	r.register(name, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		return h(args...)
+	})
+}
+
+func (r *Registry) register(name string, h handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.methods == nil {
+		r.methods = map[string]handler{}
+		r.sigs = map[string][]signature{}
+	}
+	r.methods[name] = h
+	delete(r.sigs, name)
+}
+
+// Mount makes the methods of sub available under prefix, e.g. "wp." for
+// wp.getPosts. Methods registered with sub later are available as well.
+// Methods registered with r directly take precedence over mounted ones.
+func (r *Registry) Mount(prefix string, sub *Registry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mounts = append(r.mounts, mount{prefix, sub})
+}
+
+// Merge registers the methods of other, including mounted ones, with r,
+// replacing methods of the same name.
+func (r *Registry) Merge(other *Registry) {
+	for _, name := range other.Methods() {
+		if h, ok := other.lookup(name); ok {
+			r.register(name, h)
+		}
+	}
+}
+
+// Methods returns the names of all methods of r, including mounted ones,
+// in sorted order.
+func (r *Registry) Methods() []string {
+	seen := map[string]bool{}
+	r.collect("", seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) collect(prefix string, seen map[string]bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name := range r.methods {
+		seen[prefix+name] = true
+	}
+	for _, m := range r.mounts {
+		m.r.collect(prefix+m.prefix, seen)
+	}
+}
+
+// lookup returns the handler of the method name, validating the params
+// of calls if the method has signatures.
+func (r *Registry) lookup(name string) (handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if h, ok := r.methods[name]; ok {
+		if sigs := r.sigs[name]; len(sigs) > 0 {
+			h = checkSignatures(name, h, sigs)
+		}
+		return h, true
+	}
+	for _, m := range r.mounts {
+		if strings.HasPrefix(name, m.prefix) {
+			if h, ok := m.r.lookup(name[len(m.prefix):]); ok {
+				return h, true
+			}
+		}
+	}
+	return nil, false
+}
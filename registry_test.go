@@ -0,0 +1,65 @@
+package xmlrpc
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRegistryMount(t *testing.T) {
+	wp := NewRegistry()
+	wp.Register("getPosts", func(args ...interface{}) (interface{}, error) {
+		return "wp posts", nil
+	})
+	blogger := NewRegistry()
+	blogger.Register("getUsersBlogs", func(args ...interface{}) (interface{}, error) {
+		return "blogs", nil
+	})
+	extra := NewRegistry()
+	extra.Register("ping", func(args ...interface{}) (interface{}, error) {
+		return "pong", nil
+	})
+
+	s := NewServer()
+	s.Mount("wp.", wp)
+	s.Mount("blogger.", blogger)
+	s.Merge(extra)
+	// Registered after mounting.
+	wp.Register("getPost", func(args ...interface{}) (interface{}, error) {
+		return "wp post", nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	for method, want := range map[string]string{
+		"wp.getPosts":           "wp posts",
+		"wp.getPost":            "wp post",
+		"blogger.getUsersBlogs": "blogs",
+		"ping":                  "pong",
+	} {
+		v, err := c.Call(method)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if v != want {
+			t.Fatalf("%s: want %q but got %v", method, want, v)
+		}
+	}
+	if _, err := c.Call("getPosts"); err == nil {
+		t.Fatal("want unprefixed method not to be found")
+	}
+
+	want := []string{"blogger.getUsersBlogs", "ping", "wp.getPost", "wp.getPosts"}
+	if got := s.Methods(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+	v, err := c.Call("system.listMethods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := Array{"blogger.getUsersBlogs", "ping", "wp.getPost", "wp.getPosts", "system.getCapabilities", "system.listMethods"}
+	if !reflect.DeepEqual(v, all) {
+		t.Fatalf("want %v but got %v", all, v)
+	}
+}
@@ -0,0 +1,110 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy controls how failed calls are retried. Conditions at the HTTP
+// level, transport errors and HTTP status codes, are configured apart from
+// faults, whose codes mean different things on different servers. Calls
+// with Base64Reader arguments are never retried as their body can't be
+// sent again; other retries send the same bytes as the first attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. It doubles with each
+	// further retry, up to MaxBackoff if that is positive.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Transport retries calls failing with a transport error, such as a
+	// refused connection.
+	Transport bool
+
+	// HTTPStatus lists the HTTP status codes on which calls are retried,
+	// e.g. 502, 503 and 504.
+	HTTPStatus []int
+
+	// FaultCodes lists the codes of transient faults on which calls are
+	// retried, such as the busy codes of Supervisord or Trac.
+	FaultCodes []int
+
+	// Idempotent reports whether calls of the method name may be retried,
+	// as they have no further effect when repeated. Nil means all methods
+	// may be. Calls with a context made by WithIdempotent take the flag of
+	// the context instead.
+	Idempotent func(name string) bool
+}
+
+// WithRetry makes the client retry failed calls according to p.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent returns a copy of ctx which marks the calls made with it
+// as idempotent or not, overriding RetryPolicy.Idempotent. Calls which
+// aren't idempotent are never retried.
+func WithIdempotent(ctx context.Context, idempotent bool) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, idempotent)
+}
+
+// StatusError is returned by calls answered with an HTTP status other than
+// 2xx. For compatibility its message is "Bad Request" whatever the status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return "Bad Request"
+}
+
+// retryable reports whether a call failing with err should be retried.
+func (p *RetryPolicy) retryable(err error) bool {
+	var f *Fault
+	if errors.As(err, &f) {
+		return containsInt(p.FaultCodes, f.Code)
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return containsInt(p.HTTPStatus, se.StatusCode)
+	}
+	var ue *url.Error
+	return p.Transport && errors.As(err, &ue)
+}
+
+// idempotent reports whether the call of name with ctx may be retried.
+func (p *RetryPolicy) idempotent(ctx context.Context, name string) bool {
+	if ok, set := ctx.Value(idempotentKey{}).(bool); set {
+		return ok
+	}
+	return p.Idempotent == nil || p.Idempotent(name)
+}
+
+// backoff returns the delay after the failed attempt n, counted from 1.
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < n && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+func containsInt(a []int, n int) bool {
+	for _, e := range a {
+		if e == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryFault(t *testing.T) {
+	calls := 0
+	s := NewServer()
+	s.Register("busy", func(args ...interface{}) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return nil, &Fault{Code: 75, String: "server busy"}
+		}
+		return "ok", nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		calls++
+		return nil, &Fault{Code: 4, String: "failed"}
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, FaultCodes: []int{75}}))
+	v, err := c.Call("busy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "ok" || calls != 3 {
+		t.Fatalf("want ok after 3 calls but got %v after %d", v, calls)
+	}
+
+	calls = 0
+	if _, err := c.Call("fail"); err == nil {
+		t.Fatal("want fault")
+	}
+	if calls != 1 {
+		t.Fatalf("want no retry of other faults but got %d calls", calls)
+	}
+}
+
+func TestRetryHTTPStatus(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<methodResponse><params><param><value>ok</value></param></params></methodResponse>`))
+	}))
+	defer ts.Close()
+
+	_, err := NewClient(ts.URL).Call("x")
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("want StatusError 503 but got %v", err)
+	}
+
+	calls = 0
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 2, HTTPStatus: []int{503}}))
+	if _, err := c.Call("x"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("want 2 calls but got %d", calls)
+	}
+
+	calls = 0
+	c = NewClient(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 2, FaultCodes: []int{503}}))
+	if _, err := c.Call("x"); err == nil || calls != 1 {
+		t.Fatalf("want no retry of HTTP status with fault codes, got %d calls, %v", calls, err)
+	}
+}
+
+func TestRetryBase64Reader(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 3, HTTPStatus: []int{503}}))
+	if _, err := c.Call("upload", Base64Reader{strings.NewReader("data")}); err == nil {
+		t.Fatal("want error")
+	}
+	if calls != 1 {
+		t.Fatalf("want no retry of streamed calls but got %d calls", calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		n := i + 1
+		if got := p.backoff(n); got != want {
+			t.Errorf("attempt %d: want %v but got %v", n, want, got)
+		}
+	}
+}
+
+func TestRetryIdenticalBody(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.ContentLength != int64(len(b)) {
+			t.Errorf("want Content-Length %d but got %d", len(b), r.ContentLength)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 3, HTTPStatus: []int{503}}))
+	arg := Struct{}
+	for i := 0; i < 20; i++ {
+		arg[strconv.Itoa(i)] = i
+	}
+	c.Call("get", arg)
+	if len(bodies) != 3 {
+		t.Fatalf("want 3 attempts but got %d", len(bodies))
+	}
+	for _, b := range bodies[1:] {
+		if b != bodies[0] {
+			t.Fatalf("want identical bodies but got\n%s\n%s", bodies[0], b)
+		}
+	}
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		HTTPStatus:  []int{503},
+		Idempotent:  func(name string) bool { return strings.HasPrefix(name, "get") },
+	}))
+	ctx := context.Background()
+	tests := []struct {
+		ctx   context.Context
+		name  string
+		calls int
+	}{
+		{ctx, "getPost", 3},
+		{ctx, "newPost", 1},
+		{WithIdempotent(ctx, true), "newPost", 3},
+		{WithIdempotent(ctx, false), "getPost", 1},
+	}
+	for _, tt := range tests {
+		calls = 0
+		c.CallContext(tt.ctx, tt.name)
+		if calls != tt.calls {
+			t.Errorf("%s: want %d attempts but got %d", tt.name, tt.calls, calls)
+		}
+	}
+}
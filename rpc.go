@@ -0,0 +1,111 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/rpc"
+	"reflect"
+	"sync"
+)
+
+type serverCodec struct {
+	dec  *decoder
+	enc  encoder
+	w    *bufio.Writer
+	c    io.Closer
+	mu   sync.Mutex
+	next chan struct{}
+
+	params []interface{}
+	seq    uint64
+}
+
+// NewServerCodec returns a new rpc.ServerCodec using XML-RPC on conn, so
+// that services registered with net/rpc can be called by XML-RPC clients
+// over a plain connection. The method name is used as the service method,
+// e.g. "Arith.Multiply". XML-RPC has no request IDs, so calls on a
+// connection are handled one at a time.
+//
+// A call with a single param is unmarshaled into the args of the method;
+// a call with several params into a struct, field by field, or into a
+// slice. Errors are reported as faults with code ApplicationError.
+func NewServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	c := &serverCodec{
+		dec:  newDecoder(conn, decodeOptions{}),
+		w:    bufio.NewWriter(conn),
+		c:    conn,
+		next: make(chan struct{}, 1),
+	}
+	c.next <- struct{}{}
+	return c
+}
+
+func (c *serverCodec) ReadRequestHeader(r *rpc.Request) error {
+	// Wait for the response to the previous call.
+	<-c.next
+	c.dec.started = false
+	name, params, err := c.dec.call()
+	if err != nil {
+		if de, ok := err.(*DecodeError); ok && de.Err == io.ErrUnexpectedEOF && !c.dec.started {
+			// The connection was closed between calls.
+			return io.EOF
+		}
+		return err
+	}
+	c.seq++
+	r.ServiceMethod = name
+	r.Seq = c.seq
+	c.params = params
+	return nil
+}
+
+func (c *serverCodec) ReadRequestBody(body interface{}) error {
+	params := c.params
+	c.params = nil
+	if body == nil {
+		return nil
+	}
+	switch len(params) {
+	case 0:
+		return nil
+	case 1:
+		return Unmarshal(params[0], body)
+	}
+	rv := reflect.ValueOf(body)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		rv = rv.Elem()
+		if rv.NumField() < len(params) {
+			return errors.New("xmlrpc: too many params")
+		}
+		for i, p := range params {
+			if err := unmarshal(p, rv.Field(i), "."+rv.Type().Field(i).Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return Unmarshal(Array(params), body)
+}
+
+func (c *serverCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.mu.Lock()
+	defer func() {
+		c.mu.Unlock()
+		c.next <- struct{}{}
+	}()
+	var err error
+	if r.Error != "" {
+		err = c.enc.writeFault(c.w, &Fault{Code: ApplicationError, String: r.Error})
+	} else {
+		err = c.enc.writeResponse(c.w, reflect.Indirect(reflect.ValueOf(body)).Interface())
+	}
+	if err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+func (c *serverCodec) Close() error {
+	return c.c.Close()
+}
@@ -0,0 +1,80 @@
+package xmlrpc
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+)
+
+type Arith int
+
+type ArithArgs struct {
+	A, B int
+}
+
+func (t *Arith) Multiply(args *ArithArgs, reply *int) error {
+	*reply = args.A * args.B
+	return nil
+}
+
+func (t *Arith) Divide(args *ArithArgs, reply *int) error {
+	if args.B == 0 {
+		return errors.New("divide by zero")
+	}
+	*reply = args.A / args.B
+	return nil
+}
+
+func (t *Arith) Sum(args *[]int, reply *int) error {
+	for _, i := range *args {
+		*reply += i
+	}
+	return nil
+}
+
+func TestServerCodec(t *testing.T) {
+	srv := rpc.NewServer()
+	if err := srv.Register(new(Arith)); err != nil {
+		t.Fatal(err)
+	}
+	cli, conn := net.Pipe()
+	go srv.ServeCodec(NewServerCodec(conn))
+	defer cli.Close()
+
+	call := func(method string, args ...interface{}) (interface{}, error) {
+		go EncodeMethodCall(cli, method, args...)
+		return newDecoder(cli, decodeOptions{}).response()
+	}
+
+	v, err := call("Arith.Multiply", 6, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 {
+		t.Fatalf("want 42 but got %v", v)
+	}
+	v, err = call("Arith.Multiply", Struct{"A": 3, "B": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 12 {
+		t.Fatalf("want 12 but got %v", v)
+	}
+	v, err = call("Arith.Sum", 1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 6 {
+		t.Fatalf("want 6 but got %v", v)
+	}
+	_, err = call("Arith.Divide", 1, 0)
+	var f *Fault
+	if !errors.As(err, &f) || f.String != "divide by zero" {
+		t.Fatalf("want fault but got %v", err)
+	}
+	_, err = call("Arith.Missing", 1)
+	if !errors.As(err, &f) {
+		t.Fatalf("want fault but got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+// Package rtorrent is a client for the XML-RPC interface of rTorrent,
+// reached over SCGI or through an HTTP gateway such as the /RPC2 location
+// of ruTorrent setups.
+package rtorrent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the commands of an rTorrent.
+type Client struct {
+	c *xmlrpc.Client
+}
+
+// New returns a Client of the rTorrent listening for SCGI on address, as
+// set with network.scgi.open_port, or on the unix socket at address if
+// network is "unix", as set with network.scgi.open_local.
+func New(network, address string, opts ...xmlrpc.Option) *Client {
+	c := xmlrpc.NewClient("http://localhost/RPC2", opts...)
+	c.HttpClient.Transport = &xmlrpc.SCGITransport{Network: network, Address: address}
+	return &Client{c: c}
+}
+
+// NewHTTP returns a Client of the rTorrent behind the HTTP gateway url.
+func NewHTTP(url string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// Call calls the command name on target, such as the hash of a torrent,
+// with args. Commands without a target, such as system.client_version,
+// take "".
+func (c *Client) Call(ctx context.Context, name, target string, args ...interface{}) (interface{}, error) {
+	return c.c.CallContext(ctx, name, append([]interface{}{target}, args...)...)
+}
+
+// FileTarget returns the target of the file i of the torrent hash, for
+// f.* commands.
+func FileTarget(hash string, i int) string {
+	return fmt.Sprintf("%s:f%d", hash, i)
+}
+
+// TrackerTarget returns the target of the tracker i of the torrent hash,
+// for t.* commands.
+func TrackerTarget(hash string, i int) string {
+	return fmt.Sprintf("%s:t%d", hash, i)
+}
+
+// Multicall calls the multicall command name, such as d.multicall2, with
+// args, followed by the commands given by the rtorrent tags of the fields
+// of the struct type of the elements of dst, a pointer to a slice. The
+// results are stored in dst, one element per item. Integer results are
+// stored in bool fields as true if they aren't zero.
+//
+//	var torrents []struct {
+//		Hash string `rtorrent:"d.hash="`
+//		Name string `rtorrent:"d.name="`
+//	}
+//	err := c.Multicall(ctx, "d.multicall2", &torrents, "", "main")
+func (c *Client) Multicall(ctx context.Context, name string, dst interface{}, args ...interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice || rv.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rtorrent: Multicall needs a pointer to a slice of structs, not %T", dst)
+	}
+	et := rv.Elem().Type().Elem()
+	var fields []int
+	for i := 0; i < et.NumField(); i++ {
+		if cmd := et.Field(i).Tag.Get("rtorrent"); cmd != "" {
+			fields = append(fields, i)
+			args = append(args, cmd)
+		}
+	}
+	v, err := c.c.CallContext(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	rows, ok := v.(xmlrpc.Array)
+	if !ok {
+		return fmt.Errorf("rtorrent: %s returned %T, not an array", name, v)
+	}
+	s := reflect.MakeSlice(rv.Elem().Type(), len(rows), len(rows))
+	for i, row := range rows {
+		cols, ok := row.(xmlrpc.Array)
+		if !ok || len(cols) != len(fields) {
+			return fmt.Errorf("rtorrent: %s returned an unexpected row %d", name, i)
+		}
+		for j, f := range fields {
+			if err := assign(s.Index(i).Field(f), cols[j]); err != nil {
+				return fmt.Errorf("rtorrent: %s: %v", et.Field(f).Tag.Get("rtorrent"), err)
+			}
+		}
+	}
+	rv.Elem().Set(s)
+	return nil
+}
+
+// assign stores v in dst, converting the integers rTorrent uses for flags
+// to bool.
+func assign(dst reflect.Value, v interface{}) error {
+	if dst.Kind() == reflect.Bool {
+		switch n := v.(type) {
+		case int:
+			dst.SetBool(n != 0)
+			return nil
+		case int64:
+			dst.SetBool(n != 0)
+			return nil
+		}
+	}
+	return xmlrpc.Unmarshal(v, dst.Addr().Interface())
+}
+
+// Torrent describes a torrent.
+type Torrent struct {
+	Hash           string `rtorrent:"d.hash="`
+	Name           string `rtorrent:"d.name="`
+	Size           int64  `rtorrent:"d.size_bytes="`
+	Completed      int64  `rtorrent:"d.completed_bytes="`
+	UpRate         int64  `rtorrent:"d.up.rate="`
+	DownRate       int64  `rtorrent:"d.down.rate="`
+	UpTotal        int64  `rtorrent:"d.up.total="`
+	Ratio          int    `rtorrent:"d.ratio="` // in thousandths
+	Active         bool   `rtorrent:"d.is_active="`
+	Open           bool   `rtorrent:"d.is_open="`
+	Complete       bool   `rtorrent:"d.complete="`
+	Directory      string `rtorrent:"d.directory="`
+	Label          string `rtorrent:"d.custom1="` // as set by ruTorrent
+	Message        string `rtorrent:"d.message="`
+	CreationDate   int64  `rtorrent:"d.creation_date="`
+	PeersConnected int    `rtorrent:"d.peers_connected="`
+}
+
+// Torrents returns the torrents of the view, such as "main" or "seeding".
+func (c *Client) Torrents(ctx context.Context, view string) ([]Torrent, error) {
+	var torrents []Torrent
+	err := c.Multicall(ctx, "d.multicall2", &torrents, "", view)
+	return torrents, err
+}
+
+// File describes a file of a torrent.
+type File struct {
+	Path            string `rtorrent:"f.path="`
+	Size            int64  `rtorrent:"f.size_bytes="`
+	CompletedChunks int64  `rtorrent:"f.completed_chunks="`
+	SizeChunks      int64  `rtorrent:"f.size_chunks="`
+	Priority        int    `rtorrent:"f.priority="`
+}
+
+// Files returns the files of the torrent hash.
+func (c *Client) Files(ctx context.Context, hash string) ([]File, error) {
+	var files []File
+	err := c.Multicall(ctx, "f.multicall", &files, hash, "")
+	return files, err
+}
+
+// Tracker describes a tracker of a torrent.
+type Tracker struct {
+	URL      string `rtorrent:"t.url="`
+	Type     int    `rtorrent:"t.type="` // 1 HTTP, 2 UDP, 3 DHT
+	Enabled  bool   `rtorrent:"t.is_enabled="`
+	Seeders  int    `rtorrent:"t.scrape_complete="`
+	Leechers int    `rtorrent:"t.scrape_incomplete="`
+}
+
+// Trackers returns the trackers of the torrent hash.
+func (c *Client) Trackers(ctx context.Context, hash string) ([]Tracker, error) {
+	var trackers []Tracker
+	err := c.Multicall(ctx, "t.multicall", &trackers, hash, "")
+	return trackers, err
+}
+
+// Start starts the torrent hash.
+func (c *Client) Start(ctx context.Context, hash string) error {
+	_, err := c.Call(ctx, "d.start", hash)
+	return err
+}
+
+// Stop stops the torrent hash.
+func (c *Client) Stop(ctx context.Context, hash string) error {
+	_, err := c.Call(ctx, "d.stop", hash)
+	return err
+}
+
+// Erase removes the torrent hash, leaving its data.
+func (c *Client) Erase(ctx context.Context, hash string) error {
+	_, err := c.Call(ctx, "d.erase", hash)
+	return err
+}
+
+// LoadURL adds and starts the torrent at url, which may be a magnet link,
+// running the commands, such as "d.directory.set=/data", on it.
+func (c *Client) LoadURL(ctx context.Context, url string, commands ...string) error {
+	args := []interface{}{url}
+	for _, cmd := range commands {
+		args = append(args, cmd)
+	}
+	_, err := c.Call(ctx, "load.start", "", args...)
+	return err
+}
+
+// LoadRaw adds and starts the torrent file data like LoadURL.
+func (c *Client) LoadRaw(ctx context.Context, data []byte, commands ...string) error {
+	args := []interface{}{data}
+	for _, cmd := range commands {
+		args = append(args, cmd)
+	}
+	_, err := c.Call(ctx, "load.raw_start", "", args...)
+	return err
+}
@@ -0,0 +1,88 @@
+package rtorrent
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func newTestClient(t *testing.T, s *xmlrpc.Server) *Client {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go s.ServeSCGI(l)
+	return New("tcp", l.Addr().String())
+}
+
+func TestTorrents(t *testing.T) {
+	var got []interface{}
+	s := xmlrpc.NewServer()
+	s.Register("d.multicall2", func(args ...interface{}) (interface{}, error) {
+		got = args
+		row := xmlrpc.Array{"ABCD", "debian.iso", 1 << 40}
+		for range args[5:] {
+			row = append(row, 0)
+		}
+		row[8] = 1  // d.is_active=
+		row[10] = 1 // d.complete=
+		row[11] = "/data"
+		row[12] = "linux"
+		row[13] = ""
+		return xmlrpc.Array{row}, nil
+	})
+	c := newTestClient(t, s)
+
+	torrents, err := c.Torrents(context.Background(), "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Torrent{Hash: "ABCD", Name: "debian.iso", Size: 1 << 40, Active: true, Complete: true, Directory: "/data", Label: "linux"}
+	if len(torrents) != 1 || torrents[0] != want {
+		t.Fatalf("want %+v but got %+v", want, torrents)
+	}
+	if got[0] != "" || got[1] != "main" || got[2] != "d.hash=" || got[3] != "d.name=" {
+		t.Fatalf("unexpected args %v", got)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	s := xmlrpc.NewServer()
+	s.Register("f.multicall", func(args ...interface{}) (interface{}, error) {
+		if args[0] != "ABCD" || args[1] != "" {
+			t.Errorf("unexpected target %v", args[:2])
+		}
+		return xmlrpc.Array{
+			xmlrpc.Array{"a.txt", 10, 1, 1, 1},
+			xmlrpc.Array{"b.txt", 20, 0, 1, 0},
+		}, nil
+	})
+	s.Register("f.priority.set", func(args ...interface{}) (interface{}, error) {
+		return 0, nil
+	})
+	c := newTestClient(t, s)
+
+	files, err := c.Files(context.Background(), "ABCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []File{{"a.txt", 10, 1, 1, 1}, {"b.txt", 20, 0, 1, 0}}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("want %+v but got %+v", want, files)
+	}
+	if _, err := c.Call(context.Background(), "f.priority.set", FileTarget("ABCD", 1), 2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMulticallInvalid(t *testing.T) {
+	c := NewHTTP("http://localhost/RPC2")
+	var v []string
+	if err := c.Multicall(context.Background(), "d.multicall2", &v, ""); err == nil {
+		t.Fatal("want error for slice of non-structs")
+	}
+}
@@ -0,0 +1,50 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ScatterResult is the outcome of the call to one endpoint of a
+// ScatterCall: either the returned value or the error it failed with.
+type ScatterResult struct {
+	Endpoint string
+	Value    interface{}
+	Err      error
+}
+
+// ScatterResults holds the results of a ScatterCall in the order of the
+// endpoints.
+type ScatterResults []ScatterResult
+
+// Err returns the error of the first failed call, prefixed with its
+// endpoint, or nil if all succeeded.
+func (r ScatterResults) Err() error {
+	for _, res := range r {
+		if res.Err != nil {
+			return fmt.Errorf("%s: %w", res.Endpoint, res.Err)
+		}
+	}
+	return nil
+}
+
+// ScatterCall makes the call of method with args to each of endpoints at
+// the same time, with the settings of c, e.g. to query every node of a
+// fleet. Endpoints are resolved against the URL of c as with WithPath, so
+// they may be URLs or paths. It returns once all calls returned; a failing
+// call doesn't fail the others.
+func (c *Client) ScatterCall(ctx context.Context, endpoints []string, method string, args ...interface{}) ScatterResults {
+	results := make(ScatterResults, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		results[i].Endpoint = endpoint
+		wg.Add(1)
+		go func(res *ScatterResult) {
+			defer wg.Done()
+			res.Value, res.Err = c.With(WithPath(res.Endpoint)).CallContext(ctx, method, args...)
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
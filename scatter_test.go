@@ -0,0 +1,51 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScatterCall(t *testing.T) {
+	var urls []string
+	for _, state := range []string{"RUNNING", "STOPPED"} {
+		s := NewServer()
+		s.Register("getState", func(args ...interface{}) (interface{}, error) {
+			return state, nil
+		})
+		ts := httptest.NewServer(s)
+		defer ts.Close()
+		urls = append(urls, ts.URL+"/RPC2")
+	}
+	empty := httptest.NewServer(NewServer())
+	defer empty.Close()
+	down := httptest.NewServer(NewServer())
+	down.Close()
+
+	c := NewClient(urls[0])
+	results := c.ScatterCall(context.Background(), []string{urls[1], "/RPC2", down.URL, empty.URL}, "getState")
+	if len(results) != 4 {
+		t.Fatalf("want 4 results but got %d", len(results))
+	}
+	if r := results[0]; r.Endpoint != urls[1] || r.Value != "STOPPED" || r.Err != nil {
+		t.Fatalf("unexpected result %+v", r)
+	}
+	if r := results[1]; r.Value != "RUNNING" || r.Err != nil {
+		t.Fatalf("want path resolved against the URL of the client but got %+v", r)
+	}
+	if r := results[2]; r.Err == nil {
+		t.Fatalf("want error of unreachable endpoint but got %+v", r)
+	}
+	var f *Fault
+	if r := results[3]; !errors.As(r.Err, &f) || f.Code != MethodNotFound {
+		t.Fatalf("want fault but got %+v", r)
+	}
+	if err := results.Err(); err == nil || !strings.HasPrefix(err.Error(), down.URL+": ") {
+		t.Fatalf("want error of the first failed endpoint but got %v", err)
+	}
+	if err := results[:2].Err(); err != nil {
+		t.Fatal(err)
+	}
+}
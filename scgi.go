@@ -0,0 +1,237 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"strconv"
+	"strings"
+)
+
+// ServeSCGI accepts SCGI connections on l and handles their calls, so that
+// s can be put behind web servers such as nginx with scgi_pass, as is done
+// for rTorrent. It returns when l fails to accept a connection.
+func (s *Server) ServeSCGI(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveSCGI(conn)
+	}
+}
+
+func (s *Server) serveSCGI(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	env, err := readSCGIHeaders(br)
+	if err != nil {
+		fmt.Fprintf(conn, "Status: 400 Bad Request\r\nContent-Type: text/plain\r\n\r\n%v\n", err)
+		return
+	}
+	r, err := cgi.RequestFromMap(env)
+	if err != nil {
+		fmt.Fprintf(conn, "Status: 400 Bad Request\r\nContent-Type: text/plain\r\n\r\n%v\n", err)
+		return
+	}
+	r.Body = io.NopCloser(io.LimitReader(br, r.ContentLength))
+	if r.RemoteAddr == "" {
+		r.RemoteAddr = conn.RemoteAddr().String()
+	}
+	w := &scgiResponse{header: http.Header{}}
+	s.ServeHTTP(w, r)
+	if w.aborted {
+		// Closing the connection without a response tells the web
+		// server the request failed.
+		return
+	}
+	w.flush(conn)
+}
+
+// readSCGIHeaders reads the netstring of headers starting a SCGI request.
+func readSCGIHeaders(br *bufio.Reader) (map[string]string, error) {
+	size, err := br.ReadString(':')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(size, ":"))
+	if err != nil || n < 0 || n > 1<<20 {
+		return nil, errors.New("invalid SCGI header length")
+	}
+	buf := make([]byte, n+1)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, err
+	}
+	if buf[n] != ',' {
+		return nil, errors.New("invalid SCGI header netstring")
+	}
+	fields := strings.Split(string(buf[:n]), "\x00")
+	if len(fields)%2 != 1 || fields[len(fields)-1] != "" {
+		return nil, errors.New("invalid SCGI headers")
+	}
+	env := map[string]string{}
+	for i := 0; i+1 < len(fields); i += 2 {
+		env[fields[i]] = fields[i+1]
+	}
+	if len(fields) < 3 || fields[0] != "CONTENT_LENGTH" {
+		return nil, errors.New("SCGI headers must start with CONTENT_LENGTH")
+	}
+	if env["SCGI"] != "1" {
+		return nil, errors.New("missing SCGI header")
+	}
+	if env["SERVER_PROTOCOL"] == "" {
+		env["SERVER_PROTOCOL"] = "HTTP/1.0"
+	}
+	return env, nil
+}
+
+// scgiResponse buffers the response to a SCGI request, which is written
+// in the form of a CGI response.
+type scgiResponse struct {
+	header  http.Header
+	status  int
+	body    bytes.Buffer
+	aborted bool
+}
+
+func (w *scgiResponse) abort() {
+	w.aborted = true
+}
+
+func (w *scgiResponse) Header() http.Header {
+	return w.header
+}
+
+func (w *scgiResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *scgiResponse) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+func (w *scgiResponse) flush(conn io.Writer) error {
+	w.WriteHeader(http.StatusOK)
+	bw := bufio.NewWriter(conn)
+	fmt.Fprintf(bw, "Status: %d %s\r\n", w.status, http.StatusText(w.status))
+	w.header.Set("Content-Length", strconv.Itoa(w.body.Len()))
+	w.header.Write(bw)
+	bw.WriteString("\r\n")
+	bw.Write(w.body.Bytes())
+	return bw.Flush()
+}
+
+// SCGITransport is an http.RoundTripper sending requests to a SCGI server
+// such as rTorrent, for use as the Transport of the http.Client of a
+// Client. The host of request URLs is ignored.
+type SCGITransport struct {
+	Network string // "tcp" or "unix"
+	Address string
+
+	// DialContext is used to connect to the server if set.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *SCGITransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// SCGI needs the length of the body up front.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dial := t.DialContext
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	conn, err := dial(req.Context(), t.Network, t.Address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := req.Context().Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(req.Context(), func() {
+		conn.Close()
+	})
+
+	var headers bytes.Buffer
+	header := func(name, value string) {
+		headers.WriteString(name + "\x00" + value + "\x00")
+	}
+	header("CONTENT_LENGTH", strconv.Itoa(len(body)))
+	header("SCGI", "1")
+	header("REQUEST_METHOD", req.Method)
+	header("REQUEST_URI", req.URL.RequestURI())
+	header("SERVER_PROTOCOL", "HTTP/1.1")
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		header("CONTENT_TYPE", ct)
+	}
+	for name, values := range req.Header {
+		if name != "Content-Type" && name != "Content-Length" {
+			header("HTTP_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_")), strings.Join(values, ", "))
+		}
+	}
+	bw := bufio.NewWriter(conn)
+	fmt.Fprintf(bw, "%d:", headers.Len())
+	bw.Write(headers.Bytes())
+	bw.WriteByte(',')
+	bw.Write(body)
+	if err := bw.Flush(); err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+
+	// The response is a CGI response, which has a Status header rather
+	// than a status line.
+	res, err := http.ReadResponse(bufio.NewReader(io.MultiReader(strings.NewReader("HTTP/1.0 200 OK\r\n"), conn)), req)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+	if status := res.Header.Get("Status"); status != "" {
+		code, text, _ := strings.Cut(status, " ")
+		if res.StatusCode, err = strconv.Atoi(code); err != nil {
+			stop()
+			conn.Close()
+			return nil, fmt.Errorf("xmlrpc: invalid SCGI status %q", status)
+		}
+		res.Status = status
+		if text == "" {
+			res.Status = code + " " + http.StatusText(res.StatusCode)
+		}
+		res.Header.Del("Status")
+	}
+	res.Body = &scgiBody{ReadCloser: res.Body, conn: conn, stop: stop}
+	return res, nil
+}
+
+// scgiBody closes the connection of a SCGI response along with its body.
+type scgiBody struct {
+	io.ReadCloser
+	conn net.Conn
+	stop func() bool
+}
+
+func (b *scgiBody) Close() error {
+	b.stop()
+	b.ReadCloser.Close()
+	return b.conn.Close()
+}
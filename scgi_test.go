@@ -0,0 +1,118 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func scgiRequest(body string) string {
+	headers := fmt.Sprintf("CONTENT_LENGTH\x00%d\x00SCGI\x001\x00REQUEST_METHOD\x00POST\x00REQUEST_URI\x00/RPC2\x00CONTENT_TYPE\x00text/xml\x00", len(body))
+	return fmt.Sprintf("%d:%s,%s", len(headers), headers, body)
+}
+
+func TestServeSCGI(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	s := NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	go s.ServeSCGI(l)
+
+	tests := []struct {
+		req    string
+		status int
+	}{
+		{scgiRequest(`<methodCall><methodName>echo</methodName><params><param><value>hello</value></param></params></methodCall>`), http.StatusOK},
+		{"3:abc,", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(conn, tt.req)
+		// SCGI responses are CGI responses, which read like HTTP
+		// responses without the status line.
+		res, err := http.ReadResponse(bufio.NewReader(io.MultiReader(strings.NewReader("HTTP/1.0 200 OK\r\n"), conn)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Header.Get("Status"); !strings.HasPrefix(got, fmt.Sprint(tt.status)) {
+			t.Fatalf("want status %d but got %q", tt.status, got)
+		}
+		if tt.status == http.StatusOK {
+			v, err := newDecoder(res.Body, decodeOptions{}).response()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != "hello" {
+				t.Fatalf("want %q but got %v", "hello", v)
+			}
+		}
+		conn.Close()
+	}
+}
+
+func TestServeSCGIAbortedStream(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	s := NewServer()
+	s.Register("rows", func(args ...interface{}) (interface{}, error) {
+		return ArrayStream(func(yield func(interface{}, error) bool) {
+			if yield(1, nil) {
+				yield(nil, errors.New("db gone"))
+			}
+		}), nil
+	})
+	go s.ServeSCGI(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, scgiRequest(`<methodCall><methodName>rows</methodName></methodCall>`))
+	b, err := io.ReadAll(conn)
+	if err != nil || len(b) != 0 {
+		t.Fatalf("want connection closed without a response but got %q, %v", b, err)
+	}
+}
+
+func TestSCGITransport(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	s := NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	go s.ServeSCGI(l)
+
+	c := NewClient("http://localhost/RPC2")
+	c.HttpClient.Transport = &SCGITransport{Network: "tcp", Address: l.Addr().String()}
+	v, err := c.Call("echo", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hello" {
+		t.Fatalf("want %q but got %v", "hello", v)
+	}
+	if _, err := c.Call("missing"); err == nil {
+		t.Fatal("want fault for missing method")
+	}
+}
@@ -0,0 +1,58 @@
+package xmlrpc
+
+import "sort"
+
+// Select returns the values in v matching path, which is written like the
+// paths of Struct.Get but may use [*] for all elements of an array and * for
+// all members of a struct, e.g. "folders[*].label". Members are visited in
+// the order of their names. As a convenience, a path may start with params
+// to mirror the structure of a response, in which case v holds the params
+// as returned by CallMulti: "params[0].folders[*].label".
+func Select(v interface{}, path string) ([]interface{}, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) > 0 && steps[0].name == "params" {
+		if _, ok := v.(Array); ok {
+			steps = steps[1:]
+		}
+	}
+	matches := []interface{}{v}
+	for _, s := range steps {
+		var next []interface{}
+		for _, m := range matches {
+			switch {
+			case s.all && s.index < 0:
+				st, ok := m.(Struct)
+				if !ok {
+					continue
+				}
+				names := make([]string, 0, len(st))
+				for name := range st {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					next = append(next, st[name])
+				}
+			case s.all:
+				if a, ok := m.(Array); ok {
+					next = append(next, a...)
+				}
+			case s.index < 0:
+				if st, ok := m.(Struct); ok {
+					if e, ok := st[s.name]; ok {
+						next = append(next, e)
+					}
+				}
+			default:
+				if a, ok := m.(Array); ok && s.index < len(a) {
+					next = append(next, a[s.index])
+				}
+			}
+		}
+		matches = next
+	}
+	return matches, nil
+}
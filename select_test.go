@@ -0,0 +1,57 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	payload := `<methodResponse><params><param><value><struct>
+<member><name>folders</name><value><array><data>
+  <value><struct>
+    <member><name>label</name><value>inbox</value></member>
+    <member><name>count</name><value><int>3</int></value></member>
+  </struct></value>
+  <value><struct>
+    <member><name>label</name><value>sent</value></member>
+  </struct></value>
+  <value><struct>
+    <member><name>count</name><value><int>0</int></value></member>
+  </struct></value>
+</data></array></value></member>
+</struct></value></param></params></methodResponse>`
+	params, err := newDecoder(strings.NewReader(payload), decodeOptions{}).params(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		v    interface{}
+		path string
+		want []interface{}
+	}{
+		{params, "params[0].folders[*].label", []interface{}{"inbox", "sent"}},
+		{params[0], "folders[*].label", []interface{}{"inbox", "sent"}},
+		{params[0], "folders[0].*", []interface{}{3, "inbox"}},
+		{params[0], "folders[*].count", []interface{}{3, 0}},
+		{params[0], "folders[1].label", []interface{}{"sent"}},
+		{params[0], "folders[5].label", nil},
+		{params[0], "missing[*]", nil},
+	}
+	for _, tt := range tests {
+		got, err := Select(tt.v, tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if len(got) != 0 || len(tt.want) != 0 {
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: want %v but got %v", tt.path, tt.want, got)
+			}
+		}
+	}
+
+	if _, err := Select(params, "folders[x]"); err == nil {
+		t.Fatal("want error for invalid path")
+	}
+}
@@ -0,0 +1,261 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HandlerFunc implements a method registered with a Server. Returning a
+// *Fault makes the server respond with that fault; other errors are
+// reported as a fault with code ApplicationError.
+type HandlerFunc func(args ...interface{}) (interface{}, error)
+
+// handler is the form all registered methods are stored in.
+type handler func(ctx context.Context, args []interface{}) (interface{}, error)
+
+// Server is an http.Handler which dispatches calls to the methods of its
+// Registry.
+type Server struct {
+	Registry
+
+	mu      sync.RWMutex
+	enc     encoder
+	dec     decodeOptions
+	maxBody int64
+	gzipMin int
+
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+
+	sem         chan struct{}
+	sems        map[string]chan struct{}
+	limitPolicy LimitPolicy
+
+	fallback func(ctx context.Context, method string, args []interface{}) (interface{}, error)
+
+	before, after func(*ServerCall)
+	metrics       *Metrics
+	faultRenderer FaultRenderer
+
+	closing bool
+	calls   sync.WaitGroup
+	halt    context.Context
+	haltAll context.CancelFunc
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithMaxBodySize limits the size of request bodies to n bytes. Larger
+// requests are answered with status 413 and a fault with code SystemError.
+func WithMaxBodySize(n int64) ServerOption {
+	return func(s *Server) {
+		s.maxBody = n
+	}
+}
+
+// WithRequestDecodeLimits bounds the values of requests by l, in addition
+// to the size limit of WithMaxBodySize.
+func WithRequestDecodeLimits(l DecodeLimits) ServerOption {
+	return func(s *Server) {
+		s.dec.limits = l
+	}
+}
+
+// WithResponseUint64Policy sets how unsigned integers greater than
+// math.MaxInt64 are sent in responses. By default such results are
+// answered with a fault with code InternalError.
+func WithResponseUint64Policy(p Uint64Policy) ServerOption {
+	return func(s *Server) {
+		s.enc.uint64 = p
+	}
+}
+
+// WithResponseInvalidChars sets how characters XML doesn't allow are sent
+// in responses. See WithInvalidChars.
+func WithResponseInvalidChars(p InvalidCharPolicy) ServerOption {
+	return func(s *Server) {
+		s.enc.invalidChars = p
+	}
+}
+
+// NewServer create new Server
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SetFallback sets a function which is called for methods which aren't
+// registered, e.g. to forward them to another server. It takes precedence
+// over the built-in system methods.
+func (s *Server) SetFallback(fn func(ctx context.Context, method string, args []interface{}) (interface{}, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = fn
+}
+
+// lookup returns the handler of the method name, falling back to the
+// fallback function, in which case fallback is true, and the built-in
+// system methods.
+func (s *Server) lookup(name string) (h handler, fallback, ok bool) {
+	if h, ok := s.Registry.lookup(name); ok {
+		return h, false, true
+	}
+	s.mu.RLock()
+	fn := s.fallback
+	s.mu.RUnlock()
+	if fn != nil {
+		return func(ctx context.Context, args []interface{}) (interface{}, error) {
+			return fn(ctx, name, args)
+		}, true, true
+	}
+	switch name {
+	case "system.getCapabilities":
+		return s.getCapabilities, false, true
+	case "system.listMethods":
+		return s.listMethods, false, true
+	}
+	return nil, false, false
+}
+
+// listMethods implements system.listMethods, unless registered otherwise.
+func (s *Server) listMethods(ctx context.Context, args []interface{}) (interface{}, error) {
+	names := Array{}
+	seen := map[string]bool{}
+	for _, name := range s.Methods() {
+		names = append(names, name)
+		seen[name] = true
+	}
+	for _, name := range []string{"system.getCapabilities", "system.listMethods"} {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// getCapabilities implements system.getCapabilities. Unless registered
+// otherwise, it advertises the interoperable fault codes and the nil
+// extension, which the server always supports, and introspection and
+// system.multicall if system.methodSignature and system.multicall are
+// registered.
+func (s *Server) getCapabilities(ctx context.Context, args []interface{}) (interface{}, error) {
+	caps := Struct{
+		"xmlrpc":         Struct{"specUrl": "http://www.xmlrpc.com/spec", "specVersion": 1},
+		"nil":            Struct{"specUrl": "http://ontosys.com/xml-rpc/extensions.php", "specVersion": 1},
+		"faults_interop": Struct{"specUrl": "http://xmlrpc-epi.sourceforge.net/specs/rfc.fault_codes.php", "specVersion": 20010516},
+	}
+	if _, ok := s.Registry.lookup("system.methodSignature"); ok {
+		caps["introspection"] = Struct{"specUrl": "http://xmlrpc-c.sourceforge.net/introspection.html", "specVersion": 1}
+	}
+	if _, ok := s.Registry.lookup("system.multicall"); ok {
+		caps["system.multicall"] = Struct{"specUrl": "http://www.xmlrpc.com/discuss/msgReader$1208", "specVersion": 1}
+	}
+	return caps, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if s.maxBody > 0 {
+		if r.ContentLength > s.maxBody {
+			s.tooLarge(w, r)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+	}
+	var d *decoder
+	if charset := contentCharset(r.Header.Get("Content-Type")); charset != "" {
+		// The charset of the transport takes precedence over the
+		// encoding declared by the payload, as of RFC 7303.
+		var err error
+		if d, err = newDecoderCharset(r.Body, charset, s.dec); err != nil {
+			s.writeFault(w, r, &Fault{Code: UnsupportedEncoding, String: err.Error()})
+			return
+		}
+	} else {
+		d = newDecoder(r.Body, s.dec)
+	}
+	name, args, err := d.call()
+	if err != nil {
+		var me *http.MaxBytesError
+		if errors.As(err, &me) {
+			s.tooLarge(w, r)
+			return
+		}
+		s.writeFault(w, r, requestFault(d, err))
+		return
+	}
+
+	v, err := s.dispatch(peerContext(r), name, args)
+	if err != nil {
+		f, ok := err.(*Fault)
+		if !ok {
+			f = &Fault{Code: ApplicationError, String: err.Error()}
+		}
+		s.writeFault(w, r, f)
+		return
+	}
+	if hasReader(v) {
+		if err := s.streamResponse(w, r, v); err != nil {
+			abortResponse(w)
+		}
+		return
+	}
+	var buf bytes.Buffer
+	if err := s.enc.writeResponse(&buf, v); err != nil {
+		s.writeFault(w, r, &Fault{Code: InternalError, String: err.Error()})
+		return
+	}
+	s.writeResponse(w, r, buf.Bytes())
+}
+
+// invoke calls the handler h of the method name, applying the limits of
+// the server.
+func (s *Server) invoke(ctx context.Context, name string, h handler, args []interface{}) (interface{}, error) {
+	s.mu.RLock()
+	d, ok := s.timeouts[name]
+	s.mu.RUnlock()
+	if !ok {
+		d = s.timeout
+	}
+	release, err := s.acquire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if d > 0 {
+		return runWithTimeout(ctx, name, h, args, d, release)
+	}
+	defer release()
+	return h(ctx, args)
+}
+
+func (s *Server) tooLarge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	s.enc.writeFault(w, s.renderFault(r, &Fault{Code: SystemError, String: "request body too large"}))
+}
+
+func (s *Server) writeFault(w http.ResponseWriter, r *http.Request, f *Fault) {
+	w.Header().Set("Content-Type", "text/xml")
+	s.enc.writeFault(w, s.renderFault(r, f))
+}
+
+// renderFault returns f with the string of the FaultRenderer, if any.
+func (s *Server) renderFault(r *http.Request, f *Fault) *Fault {
+	if s.faultRenderer == nil {
+		return f
+	}
+	return &Fault{Code: f.Code, String: s.faultRenderer(peerContext(r), f)}
+}
@@ -0,0 +1,226 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServerFault(t *testing.T) {
+	s := NewServer()
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 4, String: "Too many parameters."}
+	})
+	s.Register("error", func(args ...interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	tests := []struct {
+		method string
+		want   Fault
+	}{
+		{"fail", Fault{Code: 4, String: "Too many parameters."}},
+		{"error", Fault{Code: ApplicationError, String: "boom"}},
+		{"missing", Fault{Code: MethodNotFound, String: "method not found: missing"}},
+	}
+	for _, tt := range tests {
+		_, err := client.Call(tt.method)
+		var f *Fault
+		if !errors.As(err, &f) {
+			t.Fatalf("%s: want *Fault but got %T: %v", tt.method, err, err)
+		}
+		if *f != tt.want {
+			t.Fatalf("%s: want %v but got %v", tt.method, tt.want, *f)
+		}
+	}
+}
+
+func TestServerFaultRenderer(t *testing.T) {
+	messages := map[string]map[int]string{
+		"de": {4: "Zu viele Parameter.", MethodNotFound: "Methode nicht gefunden"},
+	}
+	var orig *Fault
+	s := NewServer(WithFaultRenderer(func(ctx context.Context, f *Fault) string {
+		p, _ := PeerFromContext(ctx)
+		if m, ok := messages[p.Header.Get("Accept-Language")][f.Code]; ok {
+			return m
+		}
+		return f.String
+	}), WithHooks(nil, func(c *ServerCall) {
+		orig, _ = c.Err.(*Fault)
+	}))
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 4, String: "Too many parameters."}
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	tests := []struct {
+		method, lang string
+		want         Fault
+	}{
+		{"fail", "de", Fault{Code: 4, String: "Zu viele Parameter."}},
+		{"fail", "en", Fault{Code: 4, String: "Too many parameters."}},
+		{"missing", "de", Fault{Code: MethodNotFound, String: "Methode nicht gefunden"}},
+	}
+	for _, tt := range tests {
+		client := NewClient(ts.URL, WithHeader("Accept-Language", tt.lang))
+		_, err := client.Call(tt.method)
+		var f *Fault
+		if !errors.As(err, &f) || *f != tt.want {
+			t.Fatalf("%s in %s: want %v but got %v", tt.method, tt.lang, tt.want, err)
+		}
+	}
+	if orig == nil || orig.String != "method not found: missing" {
+		t.Fatalf("want hooks to see the original fault but got %v", orig)
+	}
+}
+
+func TestServerCallWithoutParams(t *testing.T) {
+	s := NewServer()
+	s.Register("ping", func(args ...interface{}) (interface{}, error) {
+		return len(args), nil
+	})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`<?xml version="1.0"?><methodCall><methodName>ping</methodName></methodCall>`)))
+	v, err := newDecoder(rec.Body, decodeOptions{}).response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 0 {
+		t.Fatalf("want 0 but got %v", v)
+	}
+}
+
+func TestServerRequestFault(t *testing.T) {
+	s := NewServer()
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`<methodCall><params/></methodCall>`, InvalidRequest},
+		{`<methodCall><methodName>x</methodName><params>`, ParseError},
+		{`<methodCall><methodName>x</methodName></methodCal>`, ParseError},
+		{`<?xml version="1.0" encoding="koi8-r"?><methodCall><methodName>x</methodName></methodCall>`, UnsupportedEncoding},
+		{"<methodCall><methodName>x\xff</methodName></methodCall>", InvalidCharacter},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(tt.body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: want status 200 but got %d", tt.body, rec.Code)
+		}
+		_, err := newDecoder(rec.Body, decodeOptions{}).response()
+		var f *Fault
+		if !errors.As(err, &f) {
+			t.Fatalf("%s: want *Fault but got %v", tt.body, err)
+		}
+		if f.Code != tt.code {
+			t.Errorf("%s: want fault code %d but got %d: %s", tt.body, tt.code, f.Code, f.String)
+		}
+	}
+}
+
+func TestServerCapabilities(t *testing.T) {
+	s := NewServer()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	caps, err := NewClient(ts.URL).Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.Nil || caps.Introspection || caps.MultiCall {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+
+	s.Register("system.methodSignature", func(args ...interface{}) (interface{}, error) {
+		return "undef", nil
+	})
+	caps, err = NewClient(ts.URL).Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.Introspection {
+		t.Fatalf("want introspection but got %+v", caps)
+	}
+	if _, ok := caps.All["xmlrpc"]; !ok {
+		t.Fatalf("want xmlrpc capability but got %+v", caps.All)
+	}
+}
+
+func TestServerMaxBodySize(t *testing.T) {
+	s := NewServer(WithMaxBodySize(200))
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	call := func(arg string, chunked bool) *httptest.ResponseRecorder {
+		body := `<methodCall><methodName>echo</methodName><params><param><value>` + arg + `</value></param></params></methodCall>`
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		if chunked {
+			r.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, r)
+		return rec
+	}
+
+	if rec := call("small", false); rec.Code != http.StatusOK {
+		t.Fatalf("want 200 but got %d", rec.Code)
+	}
+	for _, chunked := range []bool{false, true} {
+		rec := call(strings.Repeat("x", 200), chunked)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("chunked %v: want 413 but got %d", chunked, rec.Code)
+		}
+		_, err := newDecoder(rec.Body, decodeOptions{}).response()
+		var f *Fault
+		if !errors.As(err, &f) || f.Code != SystemError {
+			t.Fatalf("chunked %v: want SystemError fault but got %v", chunked, err)
+		}
+	}
+}
+
+func TestServerFallback(t *testing.T) {
+	upstream := NewServer()
+	upstream.Register("remote", func(args ...interface{}) (interface{}, error) {
+		return Array(args), nil
+	})
+	uts := httptest.NewServer(upstream)
+	defer uts.Close()
+
+	s := NewServer()
+	s.Register("local", func(args ...interface{}) (interface{}, error) {
+		return "local", nil
+	})
+	uc := NewClient(uts.URL)
+	s.SetFallback(func(ctx context.Context, method string, args []interface{}) (interface{}, error) {
+		return uc.Call(method, args...)
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	if v, err := c.Call("local"); err != nil || v != "local" {
+		t.Fatalf("want local but got %v, %v", v, err)
+	}
+	v, err := c.Call("remote", 1, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, Array{1, "a"}) {
+		t.Fatalf("want [1 a] but got %v", v)
+	}
+	_, err = c.Call("missing")
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != MethodNotFound {
+		t.Fatalf("want MethodNotFound fault from upstream but got %v", err)
+	}
+}
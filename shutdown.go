@@ -0,0 +1,71 @@
+package xmlrpc
+
+import (
+	"context"
+)
+
+// Shutdown makes the server answer new calls with a fault with code
+// SystemError and waits for the running calls to finish. If ctx is done
+// first, the contexts of the running handlers are canceled and their calls
+// are answered with a fault rather than with whatever the handlers return,
+// and Shutdown returns the error of ctx.
+//
+// Use it along with http.Server.Shutdown, which doesn't wait for handlers
+// to return, e.g. by calling it first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	s.initHalt()
+	haltAll := s.haltAll
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.calls.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		haltAll()
+		return ctx.Err()
+	}
+}
+
+// initHalt initializes the context canceled on forced shutdown. s.mu must
+// be held.
+func (s *Server) initHalt() {
+	if s.halt == nil {
+		s.halt, s.haltAll = context.WithCancel(context.Background())
+	}
+}
+
+// track registers a call with the server, returning its context, which is
+// canceled on forced shutdown, and a function to call once it is done.
+func (s *Server) track(ctx context.Context) (context.Context, func(), error) {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return nil, nil, &Fault{Code: SystemError, String: "server is shutting down"}
+	}
+	s.initHalt()
+	halt := s.halt
+	s.calls.Add(1)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(halt, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		s.calls.Done()
+	}, nil
+}
+
+// halted reports whether the server has been shut down forcibly.
+func (s *Server) halted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.halt != nil && s.halt.Err() != nil
+}
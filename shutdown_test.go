@@ -0,0 +1,84 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerShutdown(t *testing.T) {
+	s := NewServer()
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	s.Register("slow", func(args ...interface{}) (interface{}, error) {
+		close(started)
+		<-finish
+		return "done", nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	res := make(chan error, 1)
+	go func() {
+		_, err := c.Call("slow")
+		res <- err
+	}()
+	<-started
+
+	shut := make(chan error, 1)
+	go func() {
+		shut <- s.Shutdown(context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	_, err := c.Call("slow")
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != SystemError {
+		t.Fatalf("want SystemError fault but got %v", err)
+	}
+	select {
+	case err := <-shut:
+		t.Fatalf("want Shutdown to wait but it returned %v", err)
+	default:
+	}
+
+	close(finish)
+	if err := <-res; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-shut; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerShutdownDeadline(t *testing.T) {
+	s := NewServer()
+	started := make(chan struct{})
+	s.RegisterContext("wait", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return "partial", nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	res := make(chan error, 1)
+	go func() {
+		_, err := NewClient(ts.URL).Call("wait")
+		res <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("want DeadlineExceeded but got %v", err)
+	}
+	var f *Fault
+	if err := <-res; !errors.As(err, &f) || f.Code != SystemError {
+		t.Fatalf("want SystemError fault but got %v", err)
+	}
+}
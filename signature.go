@@ -0,0 +1,230 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// sigTypes are the types a signature may use.
+var sigTypes = map[string]bool{
+	"int": true, "i4": true, "i8": true, "double": true, "boolean": true, "string": true,
+	"dateTime.iso8601": true, "base64": true, "struct": true, "array": true, "nil": true,
+}
+
+// signature is a signature of a method such as "int,int->int".
+type signature struct {
+	params []string
+	result string
+}
+
+func (s signature) String() string {
+	return strings.Join(s.params, ",") + "->" + s.result
+}
+
+// parseSignature parses a signature of the form "param,param->result".
+func parseSignature(s string) (signature, error) {
+	params, result, ok := strings.Cut(strings.ReplaceAll(s, " ", ""), "->")
+	if !ok {
+		return signature{}, fmt.Errorf("signature %q: missing ->", s)
+	}
+	sig := signature{result: result}
+	if params != "" {
+		sig.params = strings.Split(params, ",")
+	}
+	for _, t := range append(sig.params, result) {
+		if !sigTypes[t] {
+			return signature{}, fmt.Errorf("signature %q: unknown type %q", s, t)
+		}
+	}
+	return sig, nil
+}
+
+// SetSignature declares the signatures of the method name, such as
+// "int,int->int" or "->string" for a method without params. Calls with
+// params matching none of them are answered with a fault with code
+// InvalidParams without calling the handler. An int matches double as
+// well, and nil matches any type. Registering the method again removes the
+// signatures.
+func (r *Registry) SetSignature(name string, sigs ...string) error {
+	parsed := make([]signature, len(sigs))
+	for i, s := range sigs {
+		sig, err := parseSignature(s)
+		if err != nil {
+			return fmt.Errorf("xmlrpc: SetSignature %s: %v", name, err)
+		}
+		parsed[i] = sig
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sigs == nil {
+		r.sigs = map[string][]signature{}
+	}
+	r.sigs[name] = parsed
+	return nil
+}
+
+// Signatures returns the signatures of the method name, declared with
+// SetSignature or derived by RegisterFunc.
+func (r *Registry) Signatures(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if sigs, ok := r.sigs[name]; ok {
+		s := make([]string, len(sigs))
+		for i, sig := range sigs {
+			s[i] = sig.String()
+		}
+		return s
+	}
+	for _, m := range r.mounts {
+		if strings.HasPrefix(name, m.prefix) {
+			if s := m.r.Signatures(name[len(m.prefix):]); s != nil {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// checkSignatures wraps h to validate the params of calls against sigs.
+func checkSignatures(name string, h handler, sigs []signature) handler {
+	return func(ctx context.Context, args []interface{}) (interface{}, error) {
+		if len(sigs) == 1 {
+			if err := sigs[0].check(args); err != nil {
+				return nil, &Fault{Code: InvalidParams, String: err.Error()}
+			}
+			return h(ctx, args)
+		}
+		for _, sig := range sigs {
+			if sig.check(args) == nil {
+				return h(ctx, args)
+			}
+		}
+		types := make([]string, len(args))
+		for i, arg := range args {
+			types[i] = valueType(arg)
+		}
+		want := make([]string, len(sigs))
+		for i, sig := range sigs {
+			want[i] = sig.String()
+		}
+		return nil, &Fault{Code: InvalidParams, String: fmt.Sprintf("params (%s) match no signature of %s: %s", strings.Join(types, ","), name, strings.Join(want, "; "))}
+	}
+}
+
+// check returns an error if args don't match the params of s.
+func (s signature) check(args []interface{}) error {
+	if len(args) != len(s.params) {
+		return fmt.Errorf("want %d params but got %d", len(s.params), len(args))
+	}
+	for i, arg := range args {
+		if !matchType(s.params[i], arg) {
+			return fmt.Errorf("params[%d]: want %s but got %s", i, s.params[i], valueType(arg))
+		}
+	}
+	return nil
+}
+
+func matchType(typ string, v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch typ {
+	case "int", "i4":
+		_, ok := v.(int)
+		return ok
+	case "i8":
+		switch v.(type) {
+		case int, int64:
+			return true
+		}
+		return false
+	case "double":
+		switch v.(type) {
+		case int, float64:
+			return true
+		}
+		return false
+	}
+	return valueType(v) == typ
+}
+
+// valueType returns the XML-RPC type of the decoded value v.
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case int:
+		return "int"
+	case int64:
+		return "i8"
+	case float64:
+		return "double"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case time.Time:
+		return "dateTime.iso8601"
+	case []byte:
+		return "base64"
+	case Struct:
+		return "struct"
+	case Array:
+		return "array"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// goSigType returns the XML-RPC type values of t are unmarshaled from.
+func goSigType(t reflect.Type) (string, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "dateTime.iso8601", true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "base64", true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int", true
+	case reflect.Float32, reflect.Float64:
+		return "double", true
+	case reflect.Bool:
+		return "boolean", true
+	case reflect.String:
+		return "string", true
+	case reflect.Slice, reflect.Array:
+		return "array", true
+	case reflect.Map, reflect.Struct:
+		return "struct", true
+	}
+	return "", false
+}
+
+// funcSignature derives the signature of a function accepted by
+// RegisterFunc from the types of its args in and of its reply out, which
+// is nil if it only returns an error.
+func funcSignature(in []reflect.Type, out reflect.Type) (signature, bool) {
+	sig := signature{result: "nil"}
+	for _, t := range in {
+		typ, ok := goSigType(t)
+		if !ok {
+			return signature{}, false
+		}
+		sig.params = append(sig.params, typ)
+	}
+	if out != nil {
+		typ, ok := goSigType(out)
+		if !ok {
+			return signature{}, false
+		}
+		sig.result = typ
+	}
+	return sig, true
+}
@@ -0,0 +1,99 @@
+package xmlrpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSetSignature(t *testing.T) {
+	s := NewServer()
+	called := 0
+	s.Register("add", func(args ...interface{}) (interface{}, error) {
+		called++
+		return nil, nil
+	})
+	if err := s.SetSignature("add", "int,int->int", "double, double -> double"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSignature("add", "int,integer->int"); err == nil {
+		t.Fatal("want error for unknown type")
+	}
+	if err := s.SetSignature("add", "int"); err == nil {
+		t.Fatal("want error for missing result")
+	}
+
+	tests := []struct {
+		args []interface{}
+		ok   bool
+	}{
+		{[]interface{}{1, 2}, true},
+		{[]interface{}{1.5, 2}, true},
+		{[]interface{}{nil, 2}, true},
+		{[]interface{}{1}, false},
+		{[]interface{}{"1", 2}, false},
+	}
+	for _, tt := range tests {
+		called = 0
+		h, _, _ := s.lookup("add")
+		_, err := h(context.Background(), tt.args)
+		if tt.ok != (err == nil) || tt.ok != (called == 1) {
+			t.Errorf("%v: unexpected result %v", tt.args, err)
+		}
+		if f, ok := err.(*Fault); err != nil && (!ok || f.Code != InvalidParams) {
+			t.Errorf("%v: want InvalidParams fault but got %v", tt.args, err)
+		}
+	}
+	if got, want := s.Signatures("add"), []string{"int,int->int", "double,double->double"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+
+	s.Register("add", func(args ...interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if sigs := s.Signatures("add"); sigs != nil {
+		t.Fatalf("want signatures removed but got %v", sigs)
+	}
+}
+
+func TestSignatureFault(t *testing.T) {
+	s := NewServer()
+	s.Register("greet", func(args ...interface{}) (interface{}, error) {
+		return "hi", nil
+	})
+	s.SetSignature("greet", "string->string")
+	h, _, _ := s.lookup("greet")
+	_, err := h(context.Background(), []interface{}{1})
+	if f, ok := err.(*Fault); !ok || f.String != "params[0]: want string but got int" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_, err = h(context.Background(), nil)
+	if f, ok := err.(*Fault); !ok || f.String != "want 1 params but got 0" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestRegisterFuncSignature(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterFunc("add", func(a, b int) (int, error) { return a + b, nil })
+	r.RegisterFunc("reset", func(ctx context.Context) error { return nil })
+	r.RegisterFunc("any", func(v interface{}) (interface{}, error) { return v, nil })
+
+	s := NewServer()
+	s.Mount("math.", r)
+	if got, want := s.Signatures("math.add"), []string{"int,int->int"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+	if got, want := s.Signatures("math.reset"), []string{"->nil"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+	if sigs := s.Signatures("math.any"); sigs != nil {
+		t.Fatalf("want no signatures but got %v", sigs)
+	}
+
+	h, _, _ := s.lookup("math.add")
+	_, err := h(context.Background(), []interface{}{1, true})
+	if f, ok := err.(*Fault); !ok || f.String != "params[1]: want int but got boolean" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package spacewalk is a client for the XML-RPC API of Spacewalk and its
+// successors Uyuni and SUSE Manager, served at /rpc/api.
+package spacewalk
+
+import (
+	"context"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a Spacewalk with the session key of its
+// login.
+type Client struct {
+	c   *xmlrpc.Client
+	key string
+}
+
+// New returns a Client of the Spacewalk at url, e.g.
+// https://spacewalk.example.com/rpc/api.
+func New(url string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// SessionKey returns the session key of the login, or "" if not logged
+// in.
+func (c *Client) SessionKey() string {
+	return c.key
+}
+
+// Call calls the method name with the session key and args, for methods
+// this package doesn't wrap, and stores the result in r.
+func (c *Client) Call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, name, append([]interface{}{c.key}, args...)...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Login logs in as username. The session key it returns is sent with
+// later calls until Logout. It expires after d, or after the default of
+// the server if d is zero.
+func (c *Client) Login(ctx context.Context, username, password string, d time.Duration) error {
+	args := []interface{}{username, password}
+	if d > 0 {
+		args = append(args, int(d/time.Second))
+	}
+	v, err := c.c.CallContext(ctx, "auth.login", args...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, &c.key)
+}
+
+// Logout logs out.
+func (c *Client) Logout(ctx context.Context) error {
+	var r int
+	err := c.Call(ctx, &r, "auth.logout")
+	c.key = ""
+	return err
+}
+
+// System is a registered system.
+type System struct {
+	ID          int       `xmlrpc:"id"`
+	Name        string    `xmlrpc:"name"`
+	LastCheckin time.Time `xmlrpc:"last_checkin"`
+	LastBoot    time.Time `xmlrpc:"last_boot"`
+}
+
+// ListSystems returns the systems visible to the user.
+func (c *Client) ListSystems(ctx context.Context) ([]System, error) {
+	var r []System
+	if err := c.Call(ctx, &r, "system.listSystems"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Channel is a software channel.
+type Channel struct {
+	Label       string `xmlrpc:"label"`
+	Name        string `xmlrpc:"name"`
+	ParentLabel string `xmlrpc:"parent_label"`
+	Arch        string `xmlrpc:"arch"`
+	EndOfLife   string `xmlrpc:"end_of_life"`
+}
+
+// ListSoftwareChannels returns the software channels visible to the
+// user.
+func (c *Client) ListSoftwareChannels(ctx context.Context) ([]Channel, error) {
+	var r []Channel
+	if err := c.Call(ctx, &r, "channel.listSoftwareChannels"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListSubscribedSystems returns the systems subscribed to the channel
+// label.
+func (c *Client) ListSubscribedSystems(ctx context.Context, label string) ([]System, error) {
+	var r []System
+	if err := c.Call(ctx, &r, "channel.software.listSubscribedSystems", label); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Package is a package of a channel.
+type Package struct {
+	ID      int    `xmlrpc:"id"`
+	Name    string `xmlrpc:"name"`
+	Version string `xmlrpc:"version"`
+	Release string `xmlrpc:"release"`
+	Epoch   string `xmlrpc:"epoch"`
+	Arch    string `xmlrpc:"arch_label"`
+}
+
+// ListAllPackages returns the packages of the channel label.
+func (c *Client) ListAllPackages(ctx context.Context, label string) ([]Package, error) {
+	var r []Package
+	if err := c.Call(ctx, &r, "channel.software.listAllPackages", label); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetBaseChannel subscribes the system id to the base channel label.
+func (c *Client) SetBaseChannel(ctx context.Context, id int, label string) error {
+	var r int
+	return c.Call(ctx, &r, "system.setBaseChannel", id, label)
+}
+
+// SetChildChannels subscribes the system id to the child channels labels,
+// unsubscribing it from others.
+func (c *Client) SetChildChannels(ctx context.Context, id int, labels []string) error {
+	var r int
+	return c.Call(ctx, &r, "system.setChildChannels", id, labels)
+}
@@ -0,0 +1,59 @@
+package spacewalk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestClient(t *testing.T) {
+	checkin := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"auth.login":  xmlrpctest.Value("key"),
+		"auth.logout": xmlrpctest.Value(1),
+		"system.listSystems": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"id": 1000010000, "name": "web01", "last_checkin": checkin},
+		}),
+		"channel.listSoftwareChannels": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"label": "sles15-updates", "name": "SLES15 Updates", "parent_label": "sles15-pool", "arch": "x86_64"},
+		}),
+		"system.setChildChannels": xmlrpctest.Value(1),
+	})
+	defer s.Close()
+	c := New(s.URL)
+	ctx := context.Background()
+
+	if err := c.Login(ctx, "admin", "secret", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "auth.login", "admin", "secret", 3600)
+	systems, err := c.ListSystems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "system.listSystems", "key")
+	if len(systems) != 1 || systems[0].ID != 1000010000 || !systems[0].LastCheckin.Equal(checkin) {
+		t.Fatalf("unexpected systems %+v", systems)
+	}
+	channels, err := c.ListSoftwareChannels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 1 || channels[0].ParentLabel != "sles15-pool" {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+	if err := c.SetChildChannels(ctx, 1000010000, []string{"sles15-updates"}); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "system.setChildChannels", "key", 1000010000, xmlrpc.Array{"sles15-updates"})
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.AssertCalled(t, "auth.logout", "key")
+	if c.SessionKey() != "" {
+		t.Fatal("want no session key after logout")
+	}
+}
@@ -0,0 +1,67 @@
+package xmlrpc
+
+import (
+	"compress/gzip"
+	"iter"
+	"net/http"
+)
+
+// ArrayStream is a value which is encoded as an array of the values it
+// yields, as they are yielded, so huge arrays need not be held in memory.
+// Yielding a non-nil error aborts encoding. A Server streams responses
+// holding an ArrayStream or a Base64Reader to the client instead of
+// buffering them; as the status is sent by then, an error aborts the
+// connection. The call counts as finished, for hooks, metrics and limits,
+// once the handler returned.
+//
+// A Client streams requests with ArrayStream arguments like those with
+// Base64Reader arguments.
+//
+//	return xmlrpc.ArrayStream(func(yield func(interface{}, error) bool) {
+//		for rows.Next() {
+//			...
+//			if !yield(row, nil) {
+//				return
+//			}
+//		}
+//		if err := rows.Err(); err != nil {
+//			yield(nil, err)
+//		}
+//	}), nil
+type ArrayStream iter.Seq2[interface{}, error]
+
+// streamResponse writes the methodResponse carrying v, which holds a
+// stream, to w as it is encoded. Responses are compressed if enabled,
+// regardless of their size, and accepted by the client of r. On error the
+// response is incomplete and must be aborted.
+func (s *Server) streamResponse(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	w.Header().Set("Content-Type", "text/xml")
+	if s.gzipMin > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	if s.gzipMin <= 0 || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		return s.enc.writeResponse(w, v)
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	gw := gzip.NewWriter(w)
+	if err := s.enc.writeResponse(gw, v); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// aborter is implemented by the ResponseWriters of front ends other than
+// net/http, which abort incomplete responses in their own way.
+type aborter interface {
+	abort()
+}
+
+// abortResponse aborts the incomplete response written to w.
+func abortResponse(w http.ResponseWriter) {
+	if a, ok := w.(aborter); ok {
+		a.abort()
+		return
+	}
+	// net/http recovers this panic, closing the connection.
+	panic(http.ErrAbortHandler)
+}
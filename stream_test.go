@@ -0,0 +1,107 @@
+package xmlrpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func count(n int, fail error) ArrayStream {
+	return func(yield func(interface{}, error) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+		if fail != nil {
+			yield(nil, fail)
+		}
+	}
+}
+
+func TestArrayStream(t *testing.T) {
+	s := NewServer(WithCompression(1 << 20))
+	s.Register("count", func(args ...interface{}) (interface{}, error) {
+		return Struct{"values": count(args[0].(int), nil)}, nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return count(3, errors.New("broken")), nil
+	})
+	s.Register("sum", func(args ...interface{}) (interface{}, error) {
+		n := 0
+		for _, v := range args[0].(Array) {
+			n += v.(int)
+		}
+		return n, nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	client := NewClient(ts.URL)
+
+	v, err := client.Call("count", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(v, Struct{"values": Array{0, 1, 2}}) {
+		t.Fatalf("unexpected result %v", v)
+	}
+	if _, err := client.Call("fail"); err == nil {
+		t.Fatal("want error of broken stream")
+	}
+	if v, err := client.Call("sum", count(100, nil)); err != nil || v != 4950 {
+		t.Fatalf("want 4950 but got %v, %v", v, err)
+	}
+	if _, err := client.Call("sum", count(3, errors.New("broken"))); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("want error of broken stream but got %v", err)
+	}
+
+	// Streams are compressed whatever their size.
+	req, _ := http.NewRequest("POST", ts.URL, strings.NewReader(`<methodCall><methodName>count</methodName><params><param><value><int>1</int></value></param></params></methodCall>`))
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("want gzip encoding but got %q", res.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestArrayStreamIncremental(t *testing.T) {
+	big := strings.Repeat("x", 64<<10)
+	next := make(chan struct{})
+	s := NewServer()
+	s.Register("rows", func(args ...interface{}) (interface{}, error) {
+		return ArrayStream(func(yield func(interface{}, error) bool) {
+			if !yield(big, nil) {
+				return
+			}
+			<-next
+			yield("last", nil)
+		}), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	res, err := http.Post(ts.URL, "text/xml", strings.NewReader(`<methodCall><methodName>rows</methodName></methodCall>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	// The first element arrives before the second is produced.
+	if _, err := io.ReadFull(res.Body, make([]byte, len(big)/2)); err != nil {
+		t.Fatal(err)
+	}
+	close(next)
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "<value><string>last</string></value></data></array></value></param></params></methodResponse>") {
+		t.Fatalf("unexpected end of response %q", b)
+	}
+}
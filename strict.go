@@ -0,0 +1,158 @@
+package xmlrpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation describes a place where a payload deviates from the XML-RPC
+// specification.
+type Violation struct {
+	Path    string // location in the payload, e.g. params[0].value.int
+	Offset  int64  // input offset of the decoder after the offending element
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return fmt.Sprintf("%s (offset %d)", v.Message, v.Offset)
+	}
+	return fmt.Sprintf("%s at %s (offset %d)", v.Message, v.Path, v.Offset)
+}
+
+// SpecError is returned by clients created with WithStrict when a payload
+// decodes but violates the XML-RPC specification. If the payload is a fault,
+// Fault holds it and errors.As still finds it through Unwrap.
+type SpecError struct {
+	Violations []Violation
+	Fault      *Fault
+}
+
+func (e *SpecError) Error() string {
+	s := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		s[i] = v.String()
+	}
+	return "xmlrpc: payload violates the specification: " + strings.Join(s, "; ")
+}
+
+func (e *SpecError) Unwrap() error {
+	if e.Fault == nil {
+		return nil
+	}
+	return e.Fault
+}
+
+// violate records a violation of the specification in strict mode.
+func (d *decoder) violate(format string, args ...interface{}) {
+	if !d.strict {
+		return
+	}
+	d.violations = append(d.violations, Violation{
+		Path:    d.pathString(),
+		Offset:  d.r.InputOffset(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// specError returns the violations recorded so far as an error.
+func (d *decoder) specError() error {
+	if len(d.violations) == 0 {
+		return nil
+	}
+	return &SpecError{Violations: d.violations}
+}
+
+var specDouble = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)$`)
+
+// checkScalar records violations of the specification by the scalar s of
+// type typ, which has already been parsed successfully.
+func (d *decoder) checkScalar(typ, s string) {
+	if !d.strict {
+		return
+	}
+	switch typ {
+	case "i1", "i2", "i8":
+		d.violate("non-standard type <%s>", typ)
+	case "int", "i4":
+		if i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil && (i < math.MinInt32 || i > math.MaxInt32) {
+			d.violate("integer %d overflows 32 bits", i)
+		}
+	case "boolean":
+		if s != "0" && s != "1" {
+			d.violate("boolean must be 0 or 1, not %q", s)
+		}
+	case "double":
+		if !specDouble.MatchString(strings.TrimSpace(s)) {
+			d.violate("double %q is not in decimal point notation", s)
+		}
+	case "dateTime.iso8601":
+		if _, err := time.Parse("20060102T15:04:05", s); err != nil {
+			d.violate("dateTime %q is not in the form 19980717T14:08:55", s)
+		}
+	}
+}
+
+var specMethodName = regexp.MustCompile(`^[A-Za-z0-9_.:/]+$`)
+
+// checkMethodName records a violation if name has characters the
+// specification doesn't allow in method names.
+func (d *decoder) checkMethodName(name string) {
+	if d.strict && !specMethodName.MatchString(name) {
+		d.violate("method name %q has characters other than A-Z, a-z, 0-9, _, ., : and /", name)
+	}
+}
+
+// validateOptions are the decode options of ValidateRequest and
+// ValidateResponse.
+var validateOptions = decodeOptions{strict: true, strictScalars: true, trailing: TrailingError}
+
+// ValidateRequest checks the methodCall read from r against the XML-RPC
+// specification and returns the violations found, or nil if it conforms,
+// for use as a conformance checker of other implementations. If the
+// payload can't be decoded, the last violation describes why.
+func ValidateRequest(r io.Reader) []Violation {
+	d := newDecoder(r, validateOptions)
+	_, _, err := d.call()
+	return d.validation(err)
+}
+
+// ValidateResponse is like ValidateRequest for a methodResponse, which may
+// be a fault.
+func ValidateResponse(r io.Reader) []Violation {
+	d := newDecoder(r, validateOptions)
+	_, err := d.response()
+	var f *Fault
+	var se *SpecError
+	if err == nil || errors.As(err, &f) || errors.As(err, &se) {
+		err = d.expectEnd("methodResponse")
+	}
+	return d.validation(err)
+}
+
+// validation returns the violations of a payload decoded with
+// validateOptions which failed with err, if not nil.
+func (d *decoder) validation(err error) []Violation {
+	var se *SpecError
+	if errors.As(err, &se) {
+		err = nil
+	}
+	if err == nil {
+		err = d.checkTrailing()
+	}
+	if err != nil {
+		v := Violation{Message: err.Error()}
+		var de *DecodeError
+		if errors.As(err, &de) {
+			v = Violation{Path: de.Path, Offset: de.Offset, Message: de.message()}
+		}
+		d.violations = append(d.violations, v)
+	}
+	return d.violations
+}
@@ -0,0 +1,143 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	payload := `<methodResponse><params><param><value><array><data>
+<value><i4>2147483647</i4></value>
+<value><int>2147483648</int></value>
+<value><double>1.5</double></value>
+<value><double>1e10</double></value>
+<value><boolean>true</boolean></value>
+<value><dateTime.iso8601>2019-03-04T10:00:00</dateTime.iso8601></value>
+<value><i8>1</i8></value>
+<value><nil/></value>
+</data></array></value></param><param><value>x</value></param></params></methodResponse>`
+
+	if _, err := newDecoder(strings.NewReader(payload), decodeOptions{}).response(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := newDecoder(strings.NewReader(payload), decodeOptions{strict: true}).response()
+	var se *SpecError
+	if !errors.As(err, &se) {
+		t.Fatalf("want *SpecError but got %T: %v", err, err)
+	}
+	want := []string{
+		"params[0].value.array.data[1].value",
+		"params[0].value.array.data[3].value",
+		"params[0].value.array.data[4].value",
+		"params[0].value.array.data[5].value",
+		"params[0].value.array.data[6].value",
+		"params[0].value.array.data[7].value",
+		"",
+	}
+	if len(se.Violations) != len(want) {
+		t.Fatalf("want %d violations but got %v", len(want), se)
+	}
+	for i, v := range se.Violations {
+		if v.Path != want[i] {
+			t.Fatalf("want violation at %q but got %v", want[i], v)
+		}
+	}
+}
+
+func TestStrictFault(t *testing.T) {
+	payload := `<methodResponse><fault><value><struct>
+<member><name>faultCode</name><value><string>4</string></value></member>
+<member><name>faultString</name><value><string>Too many parameters.</string></value></member>
+<member><name>extra</name><value><int>1</int></value></member>
+</struct></value></fault></methodResponse>`
+
+	_, err := newDecoder(strings.NewReader(payload), decodeOptions{strict: true}).response()
+	var se *SpecError
+	if !errors.As(err, &se) {
+		t.Fatalf("want *SpecError but got %T: %v", err, err)
+	}
+	if len(se.Violations) != 2 {
+		t.Fatalf("want 2 violations but got %v", se)
+	}
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != 4 || f.String != "Too many parameters." {
+		t.Fatalf("want fault 4 but got %v", err)
+	}
+
+	_, err = newDecoder(strings.NewReader(payload), decodeOptions{}).response()
+	if _, ok := err.(*Fault); !ok {
+		t.Fatalf("want *Fault but got %T: %v", err, err)
+	}
+}
+
+func TestStrictFaultClient(t *testing.T) {
+	fault := `<methodResponse><fault><value><struct>
+<member><name>faultCode</name><value><string>4</string></value></member>
+<member><name>faultString</name><value><string>Too many parameters.</string></value></member>
+</struct></value></fault></methodResponse>`
+	trailing := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fault + trailing))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithStrict(), WithTrailingContent(TrailingError, nil))
+	res, err := c.Do(context.Background(), &MethodCall{Name: "f"})
+	if err != nil || res.Fault == nil || res.Fault.Code != 4 {
+		t.Fatalf("want fault 4 in response but got %+v, %v", res, err)
+	}
+
+	trailing = "junk"
+	var se *SpecError
+	if _, err := c.Call("f"); err == nil || errors.As(err, &se) {
+		t.Fatalf("want trailing content rejected but got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		payload string
+		request bool
+		want    []string // messages of the violations
+	}{
+		{`<?xml version="1.0"?><methodCall><methodName>examples.getStateName</methodName><params><param><value><i4>41</i4></value></param></params></methodCall>`, true, nil},
+		{`<methodCall><methodName>get state</methodName><params><param><value><boolean>true</boolean></value></param></params></methodCall>`, true, []string{
+			`method name "get state" has characters other than A-Z, a-z, 0-9, _, ., : and /`,
+			`boolean must be 0 or 1, not "true"`,
+		}},
+		{`<methodCall><methodName>f</methodName><params><param><value><int>1</value></param></params></methodCall>`, true, []string{
+			"invalid <int>: XML syntax error on line 1: element <int> closed by </value>",
+		}},
+		{`<methodResponse><params><param><value><string>South Dakota</string></value></param></params></methodResponse>`, false, nil},
+		{`<methodResponse><params><param><value>a</value></param><param><value><string>b<i/></string></value></param></params></methodResponse>`, false, []string{
+			"invalid <string>: element <i> in scalar value",
+		}},
+		{`<methodResponse><params><param><value>a</value></param><param><value>b</value></param></params></methodResponse>junk`, false, []string{
+			"response has 2 params instead of one",
+			`trailing content "junk"`,
+		}},
+		{`<methodResponse><fault><value><struct><member><name>faultCode</name><value><string>4</string></value></member></struct></value></fault></methodResponse>`, false, []string{
+			"faultCode is a string",
+			"faultString is missing or not a string",
+			"fault has 1 members instead of faultCode and faultString",
+		}},
+	} {
+		validate := ValidateResponse
+		if tt.request {
+			validate = ValidateRequest
+		}
+		vs := validate(strings.NewReader(tt.payload))
+		var got []string
+		for _, v := range vs {
+			got = append(got, v.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Fatalf("want violations %q of %s but got %q", tt.want, tt.payload, got)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package stubgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Interface is a Go interface to generate an XML-RPC implementation for.
+type Interface struct {
+	Package string
+	Name    string
+	Imports []string // import specs used by the method signatures
+	Methods []IfaceMethod
+}
+
+// IfaceMethod is a method of an Interface. Every method must return an
+// error as its last result and at most one other value before it.
+type IfaceMethod struct {
+	GoName   string
+	Name     string   // XML-RPC method name
+	Params   []string // parameter names
+	Types    []string // parameter types
+	Variadic bool     // last parameter is ...interface{}
+	Result   string   // result type, empty if only an error is returned
+}
+
+// ParseInterface parses the Go source file filename, or src if not nil, and
+// returns the interface called name. The XML-RPC method name of a method is
+// given by an `xmlrpc:name` line in its doc comment, and otherwise derived
+// from the Go name by lowering its first letter.
+func ParseInterface(filename string, src interface{}, name string) (*Interface, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var it *ast.InterfaceType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == name {
+			it, _ = ts.Type.(*ast.InterfaceType)
+		}
+		return it == nil
+	})
+	if it == nil {
+		return nil, fmt.Errorf("stubgen: interface %s not found in %s", name, filename)
+	}
+
+	iface := &Interface{Package: f.Name.Name, Name: name}
+	expr := func(e ast.Expr) string {
+		var b bytes.Buffer
+		printer.Fprint(&b, fset, e)
+		return b.String()
+	}
+	used := map[string]bool{}
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("stubgen: %s: embedded interfaces are not supported", fset.Position(field.Pos()))
+		}
+		m := IfaceMethod{GoName: field.Names[0].Name}
+		m.Name = methodName(field.Doc, m.GoName)
+		pos := fset.Position(field.Pos())
+
+		for _, p := range ft.Params.List {
+			typ := expr(p.Type)
+			if _, ok := p.Type.(*ast.Ellipsis); ok {
+				if typ != "...interface{}" {
+					return nil, fmt.Errorf("stubgen: %s: %s: only ...interface{} can be variadic", pos, m.GoName)
+				}
+				m.Variadic = true
+			}
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{nil}
+			}
+			for _, n := range names {
+				pn := fmt.Sprintf("arg%d", len(m.Params))
+				if n != nil && n.Name != "_" && !reserved[n.Name] {
+					pn = n.Name
+				}
+				m.Params = append(m.Params, pn)
+				m.Types = append(m.Types, typ)
+			}
+			collectPackages(p.Type, used)
+		}
+
+		var results []ast.Expr
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				for n := 0; n < len(r.Names) || n == 0 && len(r.Names) == 0; n++ {
+					results = append(results, r.Type)
+				}
+			}
+		}
+		if len(results) == 0 || len(results) > 2 || expr(results[len(results)-1]) != "error" {
+			return nil, fmt.Errorf("stubgen: %s: %s must return an error and at most one value", pos, m.GoName)
+		}
+		if len(results) == 2 {
+			m.Result = expr(results[0])
+			collectPackages(results[0], used)
+		}
+		iface.Methods = append(iface.Methods, m)
+	}
+
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if used[name] && !(name == "xmlrpc" && path == xmlrpcPath) {
+			iface.Imports = append(iface.Imports, expr(spec.Path))
+			if spec.Name != nil {
+				iface.Imports[len(iface.Imports)-1] = spec.Name.Name + " " + expr(spec.Path)
+			}
+		}
+	}
+	return iface, nil
+}
+
+const xmlrpcPath = "github.com/mattn/go-xmlrpc"
+
+// reserved holds the identifiers used by the generated methods, which
+// parameters are renamed from.
+var reserved = map[string]bool{"c": true, "r": true, "v": true, "err": true, "args": true, "xmlrpc": true}
+
+// methodName returns the name given by an xmlrpc: line in doc, or goName
+// with its first letter lowered.
+func methodName(doc *ast.CommentGroup, goName string) string {
+	if doc != nil {
+		for _, line := range strings.Split(doc.Text(), "\n") {
+			if strings.HasPrefix(line, "xmlrpc:") {
+				return strings.TrimSpace(line[len("xmlrpc:"):])
+			}
+		}
+	}
+	r, n := utf8.DecodeRuneInString(goName)
+	return string(unicode.ToLower(r)) + goName[n:]
+}
+
+// collectPackages adds the package names referenced by the type e to used.
+func collectPackages(e ast.Expr, used map[string]bool) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+var ifaceTmpl = template.Must(template.New("").Parse(`// Code generated by xmlrpc-stubgen{{with .Source}} from {{.}}{{end}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+
+	"{{.XMLRPCPath}}"
+)
+
+// {{.Type}} implements {{.Name}} by calling an XML-RPC server.
+type {{.Type}} struct {
+	client *xmlrpc.Client
+}
+
+var _ {{.Name}} = (*{{.Type}})(nil)
+
+// New{{.Type}} returns a {{.Type}} calling the server at url.
+func New{{.Type}}(url string, opts ...xmlrpc.Option) *{{.Type}} {
+	return &{{.Type}}{client: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client.
+func (c *{{.Type}}) XMLRPCClient() *xmlrpc.Client {
+	return c.client
+}
+
+// validate calls the Validate method of the arguments which have one.
+func (c *{{.Type}}) validate(args ...interface{}) error {
+	for _, arg := range args {
+		if v, ok := arg.(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+{{range .Methods}}
+// {{.GoName}} calls {{.Name}}.
+func (c *{{$.Type}}) {{.GoName}}({{.Signature}}) {{if .Result}}(r {{.Result}}, err error){{else}}error{{end}} {
+	args := {{.Args}}
+	if err := c.validate(args...); err != nil {
+		return {{if .Result}}r, {{end}}err
+	}
+	{{if .Result}}v{{else}}_{{end}}, err := c.client.Call({{printf "%q" .Name}}, args...)
+{{- if .Result}}
+	if err != nil {
+		return r, err
+	}
+	err = xmlrpc.Unmarshal(v, &r)
+	return r, err
+{{- else}}
+	return err
+{{- end}}
+}
+{{end}}`))
+
+// Signature returns the parameter list of m.
+func (m IfaceMethod) Signature() string {
+	s := make([]string, len(m.Params))
+	for i := range m.Params {
+		s[i] = m.Params[i] + " " + m.Types[i]
+	}
+	return strings.Join(s, ", ")
+}
+
+// Args returns the expression of the argument slice of m.
+func (m IfaceMethod) Args() string {
+	params := m.Params
+	if m.Variadic {
+		params = params[:len(params)-1]
+	}
+	s := "[]interface{}{" + strings.Join(params, ", ") + "}"
+	if m.Variadic {
+		s = "append(" + s + ", " + m.Params[len(m.Params)-1] + "...)"
+	}
+	return s
+}
+
+// GenerateInterface writes the source of an implementation of iface to w.
+// Config.Package defaults to the package of the interface and Config.Type
+// to the interface name followed by Client. Arguments with a Validate()
+// error method are validated before the call is made.
+func GenerateInterface(w io.Writer, cfg Config, iface *Interface) error {
+	if iface == nil {
+		return errors.New("stubgen: nil interface")
+	}
+	data := struct {
+		Source, Type, XMLRPCPath string
+		*Interface
+	}{cfg.Source, cfg.Type, xmlrpcPath, iface}
+	if cfg.Package != "" {
+		copied := *iface
+		copied.Package = cfg.Package
+		data.Interface = &copied
+	}
+	if data.Type == "" {
+		data.Type = iface.Name + "Client"
+	}
+	var buf bytes.Buffer
+	if err := ifaceTmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("stubgen: generated invalid code: %v", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,95 @@
+package stubgen
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const blogSrc = `package blog
+
+import (
+	"time"
+	xr "github.com/mattn/go-xmlrpc"
+	"net/http"
+	"github.com/mattn/go-xmlrpc"
+)
+
+type Post struct {
+	Title string
+	Date  time.Time
+}
+
+type Blog interface {
+	// GetPost returns a post.
+	//
+	// xmlrpc: metaWeblog.getPost
+	GetPost(id string, user, password string) (Post, error)
+	Ping() error
+	Search(xr.Struct, ...interface{}) (p []Post, err error)
+	Raw(args xmlrpc.Array) (xmlrpc.Struct, error)
+}
+
+var _ http.Handler
+`
+
+func TestParseInterface(t *testing.T) {
+	iface, err := ParseInterface("blog.go", blogSrc, "Blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Interface{
+		Package: "blog",
+		Name:    "Blog",
+		Imports: []string{`xr "github.com/mattn/go-xmlrpc"`},
+		Methods: []IfaceMethod{
+			{GoName: "GetPost", Name: "metaWeblog.getPost", Params: []string{"id", "user", "password"}, Types: []string{"string", "string", "string"}, Result: "Post"},
+			{GoName: "Ping", Name: "ping"},
+			{GoName: "Search", Name: "search", Params: []string{"arg0", "arg1"}, Types: []string{"xr.Struct", "...interface{}"}, Variadic: true, Result: "[]Post"},
+			{GoName: "Raw", Name: "raw", Params: []string{"arg0"}, Types: []string{"xmlrpc.Array"}, Result: "xmlrpc.Struct"},
+		},
+	}
+	if !reflect.DeepEqual(iface, want) {
+		t.Fatalf("want %+v but got %+v", want, iface)
+	}
+
+	for _, src := range []string{
+		"package p\ntype I interface{ F() int }",
+		"package p\ntype I interface{ F(...int) error }",
+		"package p\ntype I interface{ io.Reader }",
+	} {
+		if _, err := ParseInterface("p.go", src, "I"); err == nil {
+			t.Errorf("want error for %q", src)
+		}
+	}
+	if _, err := ParseInterface("p.go", "package p", "I"); err == nil {
+		t.Error("want error for missing interface")
+	}
+}
+
+func TestGenerateInterface(t *testing.T) {
+	iface, err := ParseInterface("blog.go", blogSrc, "Blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := GenerateInterface(&buf, Config{}, iface); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package blog\n",
+		"\txr \"github.com/mattn/go-xmlrpc\"\n",
+		"var _ Blog = (*BlogClient)(nil)",
+		"func (c *BlogClient) GetPost(id string, user string, password string) (r Post, err error) {\n\targs := []interface{}{id, user, password}",
+		"v, err := c.client.Call(\"metaWeblog.getPost\", args...)",
+		"func (c *BlogClient) Ping() error {",
+		"_, err := c.client.Call(\"ping\", args...)",
+		"args := append([]interface{}{arg0}, arg1...)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, src)
+		}
+	}
+}
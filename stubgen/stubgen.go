@@ -0,0 +1,215 @@
+// Package stubgen generates typed Go clients for XML-RPC servers which
+// support introspection.
+package stubgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Method describes a method of a server.
+type Method struct {
+	Name string
+	Help string
+
+	// Signature holds the XML-RPC type names of the result followed by the
+	// parameters, as returned by system.methodSignature. It is nil if the
+	// signature is unknown.
+	Signature []string
+}
+
+// Introspect returns the methods of the server c talks to, using
+// system.listMethods, system.methodSignature and system.methodHelp. Only
+// the first signature of overloaded methods is kept. Methods whose
+// signature or help can't be retrieved are still returned, without them.
+func Introspect(c *xmlrpc.Client) ([]Method, error) {
+	v, err := c.Call("system.listMethods")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := xmlrpc.Unmarshal(v, &names); err != nil {
+		return nil, err
+	}
+	methods := make([]Method, len(names))
+	for i, name := range names {
+		methods[i].Name = name
+		if v, err := c.Call("system.methodSignature", name); err == nil {
+			var sigs [][]string
+			if xmlrpc.Unmarshal(v, &sigs) == nil && len(sigs) > 0 && len(sigs[0]) > 0 {
+				methods[i].Signature = sigs[0]
+			}
+		}
+		if v, err := c.Call("system.methodHelp", name); err == nil {
+			if s, ok := v.(string); ok {
+				methods[i].Help = strings.TrimSpace(s)
+			}
+		}
+	}
+	return methods, nil
+}
+
+// Config controls the generated code.
+type Config struct {
+	Package string // package name, "client" if empty
+	Type    string // name of the client type, "Client" if empty
+	Source  string // mentioned in the header, such as the server URL
+}
+
+var goTypes = map[string]string{
+	"int":              "int",
+	"i4":               "int",
+	"i8":               "int64",
+	"boolean":          "bool",
+	"string":           "string",
+	"double":           "float64",
+	"dateTime.iso8601": "time.Time",
+	"base64":           "[]byte",
+	"array":            "xmlrpc.Array",
+	"struct":           "xmlrpc.Struct",
+}
+
+// GoType returns the Go type used for the XML-RPC type typ.
+func GoType(typ string) string {
+	if t, ok := goTypes[typ]; ok {
+		return t
+	}
+	return "interface{}"
+}
+
+// GoName returns the exported Go identifier for the method name, such as
+// BloggerGetUsersBlogs for blogger.getUsersBlogs.
+func GoName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "M" + s
+	}
+	return s
+}
+
+type stub struct {
+	GoName string
+	Name   string
+	Doc    []string
+	Params []string // Go types, nil for variadic
+	Result string
+	Known  bool
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by xmlrpc-stubgen{{with .Source}} from {{.}}{{end}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Time}}
+	"time"
+{{end}}
+	"github.com/mattn/go-xmlrpc"
+)
+
+// {{.Type}} is a typed client of the server.
+type {{.Type}} struct {
+	client *xmlrpc.Client
+}
+
+// New{{.Type}} returns a {{.Type}} calling the server at url.
+func New{{.Type}}(url string, opts ...xmlrpc.Option) *{{.Type}} {
+	return &{{.Type}}{client: xmlrpc.NewClient(url, opts...)}
+}
+
+// XMLRPCClient returns the underlying client.
+func (c *{{.Type}}) XMLRPCClient() *xmlrpc.Client {
+	return c.client
+}
+{{range .Stubs}}
+// {{.GoName}} calls {{.Name}}.
+{{- range .Doc}}
+//{{if .}} {{.}}{{end}}{{end}}
+{{- if .Known}}
+func (c *{{$.Type}}) {{.GoName}}({{range $i, $p := .Params}}{{if $i}}, {{end}}arg{{$i}} {{$p}}{{end}}) (r {{.Result}}, err error) {
+	v, err := c.client.Call({{printf "%q" .Name}}{{range $i, $p := .Params}}, arg{{$i}}{{end}})
+{{- else}}
+func (c *{{$.Type}}) {{.GoName}}(args ...interface{}) (r {{.Result}}, err error) {
+	v, err := c.client.Call({{printf "%q" .Name}}, args...)
+{{- end}}
+	if err != nil {
+		return r, err
+	}
+	err = xmlrpc.Unmarshal(v, &r)
+	return r, err
+}
+{{end}}`))
+
+// Generate writes the source of a client for methods to w.
+func Generate(w io.Writer, cfg Config, methods []Method) error {
+	data := struct {
+		Config
+		Time  bool
+		Stubs []stub
+	}{Config: cfg}
+	if data.Package == "" {
+		data.Package = "client"
+	}
+	if data.Type == "" {
+		data.Type = "Client"
+	}
+
+	used := map[string]bool{"XMLRPCClient": true}
+	for _, m := range methods {
+		s := stub{Name: m.Name, GoName: GoName(m.Name), Result: "interface{}"}
+		for n := 2; used[s.GoName]; n++ {
+			s.GoName = fmt.Sprintf("%s%d", GoName(m.Name), n)
+		}
+		used[s.GoName] = true
+		if m.Help != "" {
+			s.Doc = append([]string{""}, strings.Split(m.Help, "\n")...)
+			for i := range s.Doc {
+				s.Doc[i] = strings.TrimRight(s.Doc[i], " \t\r")
+			}
+		}
+		if len(m.Signature) > 0 {
+			s.Known = true
+			s.Result = GoType(m.Signature[0])
+			for _, p := range m.Signature[1:] {
+				s.Params = append(s.Params, GoType(p))
+			}
+			for _, t := range m.Signature {
+				if t == "dateTime.iso8601" {
+					data.Time = true
+				}
+			}
+		}
+		data.Stubs = append(data.Stubs, s)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("stubgen: generated invalid code: %v", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,82 @@
+package stubgen
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"blogger.getUsersBlogs": "BloggerGetUsersBlogs",
+		"system.listMethods":    "SystemListMethods",
+		"wp_get_posts":          "WpGetPosts",
+		"2fa.check":             "M2faCheck",
+	}
+	for in, want := range tests {
+		if got := GoName(in); got != want {
+			t.Errorf("%s: want %s but got %s", in, want, got)
+		}
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	ts := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"system.listMethods": xmlrpctest.Value(xmlrpc.Array{"add", "now"}),
+		"system.methodSignature": func(args ...interface{}) (interface{}, error) {
+			if args[0] == "add" {
+				return xmlrpc.Array{xmlrpc.Array{"int", "int", "int"}}, nil
+			}
+			return "undef", nil
+		},
+		"system.methodHelp": func(args ...interface{}) (interface{}, error) {
+			if args[0] == "add" {
+				return "Adds two numbers.", nil
+			}
+			return nil, &xmlrpc.Fault{Code: 1, String: "no help"}
+		},
+	})
+	defer ts.Close()
+
+	methods, err := Introspect(ts.XMLRPCClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Method{
+		{Name: "add", Help: "Adds two numbers.", Signature: []string{"int", "int", "int"}},
+		{Name: "now"},
+	}
+	if !reflect.DeepEqual(methods, want) {
+		t.Fatalf("want %+v but got %+v", want, methods)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var buf bytes.Buffer
+	err := Generate(&buf, Config{Package: "calc", Type: "Calc"}, []Method{
+		{Name: "add", Help: "Adds two numbers.", Signature: []string{"int", "int", "int"}},
+		{Name: "at", Signature: []string{"string", "dateTime.iso8601"}},
+		{Name: "now"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package calc\n",
+		"\t\"time\"\n",
+		"func NewCalc(url string, opts ...xmlrpc.Option) *Calc {",
+		"// Add calls add.\n//\n// Adds two numbers.\nfunc (c *Calc) Add(arg0 int, arg1 int) (r int, err error) {",
+		"v, err := c.client.Call(\"add\", arg0, arg1)",
+		"func (c *Calc) At(arg0 time.Time) (r string, err error) {",
+		"func (c *Calc) Now(args ...interface{}) (r interface{}, err error) {",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, src)
+		}
+	}
+}
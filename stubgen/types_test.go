@@ -0,0 +1,84 @@
+package stubgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func response(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := (&xmlrpc.MethodResponse{Value: v}).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateTypes(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	samples := [][]byte{
+		response(t, xmlrpc.Array{
+			xmlrpc.Struct{
+				"postid":      1,
+				"title":       "hello",
+				"dateCreated": date,
+				"score":       1,
+				"author":      xmlrpc.Struct{"name": "gopher", "id": 7},
+				"categories":  xmlrpc.Array{"go"},
+				"custom_fields": xmlrpc.Array{
+					xmlrpc.Struct{"key": "mood", "value": "happy"},
+				},
+				"extra": nil,
+			},
+		}),
+		response(t, xmlrpc.Array{
+			xmlrpc.Struct{
+				"postid":      2,
+				"title":       "world",
+				"dateCreated": date,
+				"score":       2.5,
+				"author":      xmlrpc.Struct{"name": "gopher", "id": "7"},
+				"categories":  xmlrpc.Array{},
+				"custom_fields": xmlrpc.Array{
+					xmlrpc.Struct{"key": "mood"},
+				},
+				"options": xmlrpc.Struct{},
+			},
+		}),
+	}
+	var buf bytes.Buffer
+	if err := GenerateTypes(&buf, Config{Package: "blog", Source: "posts.xml"}, "Post", samples...); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"// Code generated by xmlrpc-stubgen from posts.xml. DO NOT EDIT.\n\npackage blog\n",
+		"import (\n\t\"time\"\n\n\t\"github.com/mattn/go-xmlrpc\"\n)\n",
+		"type Post struct {\n",
+		"\tAuthor       PostAuthor         `xmlrpc:\"author\"`\n",
+		"\tCategories   []string           `xmlrpc:\"categories\"`\n",
+		"\tCustomFields []PostCustomFields `xmlrpc:\"custom_fields\"`\n",
+		"\tDateCreated  time.Time          `xmlrpc:\"dateCreated\"`\n",
+		"\tExtra        interface{}        `xmlrpc:\"extra,omitempty\"`\n",
+		"\tOptions      xmlrpc.Struct      `xmlrpc:\"options,omitempty\"`\n",
+		"\tPostid       int                `xmlrpc:\"postid\"`\n",
+		"\tScore        float64            `xmlrpc:\"score\"`\n",
+		"type PostAuthor struct {\n\tId   interface{} `xmlrpc:\"id\"`\n",
+		"type PostCustomFields struct {\n\tKey   string `xmlrpc:\"key\"`\n\tValue string `xmlrpc:\"value,omitempty\"`\n}",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, src)
+		}
+	}
+
+	if err := GenerateTypes(&buf, Config{}, "N", response(t, 42)); err == nil {
+		t.Fatal("want error for scalar results")
+	}
+	if err := GenerateTypes(&buf, Config{}, "N", []byte("<methodResponse>")); err == nil {
+		t.Fatal("want error for broken samples")
+	}
+}
@@ -0,0 +1,77 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestGetAllProcessInfo(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"supervisor.getAllProcessInfo": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"name": "web", "group": "app", "state": 20, "statename": "RUNNING", "pid": 123, "start": 1700000000},
+		}),
+		"supervisor.startProcess": xmlrpctest.Fault(AlreadyStarted, "ALREADY_STARTED: app:web"),
+	})
+	defer s.Close()
+	c := New(s.URL)
+
+	infos, err := c.GetAllProcessInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].State != Running || infos[0].PID != 123 || infos[0].FullName() != "app:web" {
+		t.Fatalf("unexpected infos: %+v", infos)
+	}
+
+	err = c.StartProcess(context.Background(), "app:web", true)
+	var f *xmlrpc.Fault
+	if !errors.As(err, &f) || f.Code != AlreadyStarted {
+		t.Fatalf("want AlreadyStarted fault but got %v", err)
+	}
+	s.AssertCalled(t, "supervisor.startProcess", "app:web", true)
+}
+
+func TestTailStdout(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"supervisor.tailProcessStdoutLog": xmlrpctest.Value(xmlrpc.Array{"log line\n", 1042, false}),
+	})
+	defer s.Close()
+
+	tail, err := New(s.URL).TailStdout(context.Background(), "web", 1033, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tail.Data != "log line\n" || tail.Offset != 1042 || tail.Overflow {
+		t.Fatalf("unexpected tail: %+v", tail)
+	}
+	s.AssertCalled(t, "supervisor.tailProcessStdoutLog", "web", 1033, 100)
+}
+
+func TestNewUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "supervisor.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+	s := xmlrpc.NewServer()
+	s.Register("supervisor.getState", func(args ...interface{}) (interface{}, error) {
+		return xmlrpc.Struct{"statecode": 1, "statename": "RUNNING"}, nil
+	})
+	go http.Serve(l, s)
+
+	st, err := NewUnix(path).GetState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Code != 1 || st.Name != "RUNNING" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
@@ -0,0 +1,98 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDecodeTimeout is wrapped by the errors of calls whose response took
+// longer to read than the time set with WithDecodeTimeout.
+var ErrDecodeTimeout = errors.New("xmlrpc: decode timeout")
+
+// WithDecodeTimeout limits the time the client takes to read and decode
+// the body of a response to d, counted from the arrival of its headers,
+// apart from the timeout of the http.Client. It makes the call fail if a
+// server trickles its response.
+func WithDecodeTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.decodeTimeout = d
+	}
+}
+
+// deadlineReader fails reads once expire has been called, which closes
+// the body to unblock a pending read.
+type deadlineReader struct {
+	r       io.Reader
+	body    io.Closer
+	expired atomic.Bool
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.expired.Load() {
+		return n, ErrDecodeTimeout
+	}
+	return n, err
+}
+
+func (r *deadlineReader) expire() {
+	r.expired.Store(true)
+	r.body.Close()
+}
+
+// WithHandlerTimeout sets the time after which the context of a handler is
+// canceled and the call is answered with a fault with code SystemError,
+// for methods without a timeout of their own. See Server.SetTimeout.
+func WithHandlerTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// SetTimeout sets the timeout of the method name, overriding the one set
+// with WithHandlerTimeout. Zero removes the timeout of the method.
+func (s *Server) SetTimeout(name string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timeouts == nil {
+		s.timeouts = map[string]time.Duration{}
+	}
+	s.timeouts[name] = d
+}
+
+// runWithTimeout runs h, answering with a fault after d. release is called
+// once h has returned.
+func runWithTimeout(ctx context.Context, name string, h handler, args []interface{}, d time.Duration, release func()) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	type result struct {
+		v   interface{}
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		defer release()
+		defer func() {
+			// The handler runs apart from the goroutine of the request,
+			// where net/http would recover from a panic.
+			if r := recover(); r != nil {
+				ch <- result{err: &Fault{Code: InternalError, String: fmt.Sprint("panic: ", r)}}
+			}
+		}()
+		v, err := h(ctx, args)
+		ch <- result{v, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.v, r.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &Fault{Code: SystemError, String: fmt.Sprintf("%s timed out after %v", name, d)}
+		}
+		return nil, ctx.Err()
+	}
+}
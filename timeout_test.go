@@ -0,0 +1,72 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerTimeout(t *testing.T) {
+	canceled := make(chan struct{})
+	s := NewServer(WithHandlerTimeout(20 * time.Millisecond))
+	s.RegisterContext("slow", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	})
+	s.RegisterContext("patient", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "done", ctx.Err()
+	})
+	s.SetTimeout("patient", time.Second)
+	s.Register("panic", func(args ...interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	_, err := c.Call("slow")
+	var f *Fault
+	if !errors.As(err, &f) || f.Code != SystemError {
+		t.Fatalf("want SystemError fault but got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("want context of handler to be canceled")
+	}
+
+	if v, err := c.Call("patient"); err != nil || v != "done" {
+		t.Fatalf("want done but got %v, %v", v, err)
+	}
+
+	_, err = c.Call("panic")
+	if !errors.As(err, &f) || f.Code != InternalError {
+		t.Fatalf("want InternalError fault but got %v", err)
+	}
+}
+
+func TestDecodeTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<methodResponse><params><param><value>`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	start := time.Now()
+	_, err := NewClient(ts.URL, WithDecodeTimeout(50*time.Millisecond)).Call("trickle")
+	if !errors.Is(err, ErrDecodeTimeout) {
+		t.Fatalf("want decode timeout but got %v", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("want call abandoned after the decode timeout but it took %v", d)
+	}
+}
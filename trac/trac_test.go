@@ -0,0 +1,92 @@
+package trac
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func newTestServer() *httptest.Server {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := xmlrpc.NewServer()
+	s.RegisterFunc("ticket.query", func(q string) ([]int, error) {
+		return []int{1, 2}, nil
+	})
+	get := func(id int) (xmlrpc.Array, error) {
+		if id == 3 {
+			return nil, &xmlrpc.Fault{Code: 404, String: "Ticket 3 does not exist."}
+		}
+		return xmlrpc.Array{id, created, created.Add(time.Hour), xmlrpc.Struct{
+			"summary": "Ticket", "status": "new", "custom_field": "x", "time": created,
+		}}, nil
+	}
+	s.RegisterFunc("ticket.get", get)
+	s.Register("system.multicall", func(args ...interface{}) (interface{}, error) {
+		var results xmlrpc.Array
+		for _, call := range args[0].(xmlrpc.Array) {
+			c := call.(xmlrpc.Struct)
+			v, err := get(c["params"].(xmlrpc.Array)[0].(int))
+			if f, ok := err.(*xmlrpc.Fault); ok {
+				results = append(results, xmlrpc.Struct{"faultCode": f.Code, "faultString": f.String})
+			} else {
+				results = append(results, xmlrpc.Array{v})
+			}
+		}
+		return results, nil
+	})
+	s.RegisterFunc("wiki.getPage", func(name string) (string, error) {
+		return "= " + name + " =", nil
+	})
+	return httptest.NewServer(s)
+}
+
+func TestTickets(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	c := New(ts.URL)
+	ctx := context.Background()
+
+	ids, err := c.Query(ctx, "status!=closed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+	ticket, err := c.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != 1 || ticket.Summary != "Ticket" || ticket.Changed.Sub(ticket.Created) != time.Hour || ticket.Attributes["custom_field"] != "x" || ticket.Created.Location() != time.UTC {
+		t.Fatalf("unexpected ticket: %+v", ticket)
+	}
+
+	tickets, err := c.GetMany(ctx, ids...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 || tickets[0].ID != 1 || tickets[1].ID != 2 {
+		t.Fatalf("unexpected tickets: %+v", tickets)
+	}
+	var f *xmlrpc.Fault
+	if _, err := c.GetMany(ctx, 1, 3); !errors.As(err, &f) || f.Code != 404 {
+		t.Fatalf("want fault 404 but got %v", err)
+	}
+}
+
+func TestGetPage(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	page, err := New(ts.URL).GetPage(context.Background(), "WikiStart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != "= WikiStart =" {
+		t.Fatalf("unexpected page %q", page)
+	}
+}
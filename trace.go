@@ -0,0 +1,53 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+)
+
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of ctx carrying id as the trace or
+// correlation ID of the calls made with it. See WithTraceHeader and
+// PropagateTraceID.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceIDFromContext returns the trace ID set with WithTraceID.
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(traceIDKey{}).(string)
+	return id, ok
+}
+
+// WithTraceHeader makes the client send the trace ID extract returns for
+// the context of a call in header, e.g. X-Request-Id. If extract is nil,
+// the ID set with WithTraceID is sent. No header is sent for an empty ID.
+func WithTraceHeader(header string, extract func(ctx context.Context) string) Option {
+	if extract == nil {
+		extract = func(ctx context.Context) string {
+			id, _ := TraceIDFromContext(ctx)
+			return id
+		}
+	}
+	return func(c *Client) {
+		c.traceHeader = header
+		c.traceID = extract
+	}
+}
+
+// PropagateTraceID returns a handler which passes requests on to h with
+// the trace ID of their header added to their context by inject, so that
+// handlers registered with RegisterContext can read it and pass it on to
+// the calls they make. If inject is nil, WithTraceID is used.
+func PropagateTraceID(h http.Handler, header string, inject func(ctx context.Context, id string) context.Context) http.Handler {
+	if inject == nil {
+		inject = WithTraceID
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(header); id != "" {
+			r = r.WithContext(inject(r.Context(), id))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
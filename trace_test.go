@@ -0,0 +1,38 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceID(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("trace", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		id, _ := TraceIDFromContext(ctx)
+		return id, nil
+	})
+	ts := httptest.NewServer(PropagateTraceID(s, "X-Request-Id", nil))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, WithTraceHeader("X-Request-Id", nil))
+	v, err := client.CallContext(WithTraceID(context.Background(), "abc123"), "trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "abc123" {
+		t.Fatalf("want trace ID abc123 but got %v", v)
+	}
+	if v, err = client.Call("trace"); err != nil || v != "" {
+		t.Fatalf("want no trace ID but got %v, %v", v, err)
+	}
+
+	type key struct{}
+	client = NewClient(ts.URL, WithTraceHeader("X-Request-Id", func(ctx context.Context) string {
+		id, _ := ctx.Value(key{}).(string)
+		return id
+	}))
+	if v, err = client.CallContext(context.WithValue(context.Background(), key{}, "def456"), "trace"); err != nil || v != "def456" {
+		t.Fatalf("want trace ID def456 but got %v, %v", v, err)
+	}
+}
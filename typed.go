@@ -0,0 +1,125 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// RegisterFunc registers fn, a function of the form
+//
+//	func([ctx context.Context,] args...) ([reply,] error)
+//
+// as the implementation of the method name. The params of a call are
+// unmarshaled into the args of fn, one by one. If fn takes a single struct
+// argument, a call with a single struct param is unmarshaled into it by
+// member name and a call with other params by position, field by field. The
+// reply is sent back as a struct if it is one, and nil is sent if fn only
+// returns an error. Params which can't be unmarshaled are reported as a
+// fault with code InvalidParams. Unless fn takes a single struct, the
+// signature of the method is derived from the types of fn, see
+// Registry.Signatures.
+func (r *Registry) RegisterFunc(name string, fn interface{}) error {
+	h, sigs, err := typedHandler(fn)
+	if err != nil {
+		return fmt.Errorf("xmlrpc: RegisterFunc %s: %v", name, err)
+	}
+	r.register(name, h)
+	if sigs != nil {
+		r.mu.Lock()
+		r.sigs[name] = sigs
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+func typedHandler(fn interface{}) (handler, []signature, error) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		return nil, nil, fmt.Errorf("%T is not a function", fn)
+	}
+	if ft.IsVariadic() {
+		return nil, nil, errors.New("variadic functions are not supported, use Register")
+	}
+	n := ft.NumOut()
+	if n == 0 || n > 2 || ft.Out(n-1) != errorType {
+		return nil, nil, errors.New("function must return an error and at most one value")
+	}
+	var in []reflect.Type
+	withContext := ft.NumIn() > 0 && ft.In(0) == contextType
+	for i := 0; i < ft.NumIn(); i++ {
+		if i > 0 || !withContext {
+			in = append(in, ft.In(i))
+		}
+	}
+	named := len(in) == 1 && in[0].Kind() == reflect.Struct && in[0] != reflect.TypeOf(time.Time{})
+	var sigs []signature
+	if !named {
+		var out reflect.Type
+		if n == 2 {
+			out = ft.Out(0)
+		}
+		if sig, ok := funcSignature(in, out); ok {
+			sigs = []signature{sig}
+		}
+	}
+
+	return func(ctx context.Context, args []interface{}) (interface{}, error) {
+		var vals []reflect.Value
+		if withContext {
+			vals = append(vals, reflect.ValueOf(ctx))
+		}
+		params := make([]reflect.Value, len(in))
+		for i, t := range in {
+			params[i] = reflect.New(t).Elem()
+		}
+		if err := unmarshalParams(args, params, named); err != nil {
+			return nil, &Fault{Code: InvalidParams, String: err.Error()}
+		}
+		out := fv.Call(append(vals, params...))
+		if err, _ := out[n-1].Interface().(error); err != nil {
+			return nil, err
+		}
+		if n == 1 {
+			return nil, nil
+		}
+		return out[0].Interface(), nil
+	}, sigs, nil
+}
+
+// unmarshalParams stores args in params. If named is set, params holds a
+// single struct which is filled by member name or, unless args is a single
+// struct, by position.
+func unmarshalParams(args []interface{}, params []reflect.Value, named bool) error {
+	if named {
+		if len(args) == 1 {
+			if _, ok := args[0].(Struct); ok {
+				return unmarshal(args[0], params[0], "params[0]")
+			}
+		}
+		st := params[0]
+		var fields []reflect.Value
+		for i := 0; i < st.NumField(); i++ {
+			if _, ok := fieldName(st.Type().Field(i)); ok {
+				fields = append(fields, st.Field(i))
+			}
+		}
+		if len(args) > len(fields) {
+			return fmt.Errorf("want at most %d params but got %d", len(fields), len(args))
+		}
+		params = fields[:len(args)]
+	} else if len(args) != len(params) {
+		return fmt.Errorf("want %d params but got %d", len(params), len(args))
+	}
+	for i, arg := range args {
+		if err := unmarshal(arg, params[i], fmt.Sprintf("params[%d]", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type searchArgs struct {
+	Query string `xmlrpc:"query"`
+	Limit int    `xmlrpc:"limit"`
+}
+
+type searchReply struct {
+	Hits  []string `xmlrpc:"hits"`
+	Total int      `xmlrpc:"total"`
+}
+
+func TestRegisterFunc(t *testing.T) {
+	s := NewServer()
+	err := s.RegisterFunc("search", func(ctx context.Context, args searchArgs) (searchReply, error) {
+		if ctx == nil {
+			return searchReply{}, errors.New("no context")
+		}
+		return searchReply{Hits: []string{args.Query}, Total: args.Limit}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RegisterFunc("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RegisterFunc("ping", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	want := Struct{"hits": Array{"go"}, "total": 5}
+	v, err := c.Call("search", searchArgs{Query: "go", Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("named: want %v but got %v", want, v)
+	}
+	v, err = c.Call("search", "go", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("positional: want %v but got %v", want, v)
+	}
+
+	if v, err := c.Call("add", 1, 2); err != nil || v != 3 {
+		t.Fatalf("want 3 but got %v, %v", v, err)
+	}
+	if v, err := c.Call("ping"); err != nil || v != nil {
+		t.Fatalf("want nil but got %v, %v", v, err)
+	}
+
+	for _, args := range [][]interface{}{{1}, {1, "x"}} {
+		_, err := c.Call("add", args...)
+		var f *Fault
+		if !errors.As(err, &f) || f.Code != InvalidParams {
+			t.Fatalf("%v: want InvalidParams fault but got %v", args, err)
+		}
+	}
+}
+
+func TestRegisterFuncInvalid(t *testing.T) {
+	s := NewServer()
+	for _, fn := range []interface{}{
+		1,
+		func() int { return 0 },
+		func(args ...int) error { return nil },
+		func() (int, int, error) { return 0, 0, nil },
+	} {
+		if err := s.RegisterFunc("x", fn); err == nil {
+			t.Errorf("%T: want error", fn)
+		}
+	}
+}
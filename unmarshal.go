@@ -0,0 +1,242 @@
+package xmlrpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UnmarshalTypeError is returned by Unmarshal when a value can't be stored
+// in a Go value of the destination type.
+type UnmarshalTypeError struct {
+	Value interface{}  // the decoded value
+	Type  reflect.Type // the type it could not be stored in
+	Path  string       // location of the value, e.g. .posts[2].title
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "."
+	}
+	return fmt.Sprintf("xmlrpc: cannot unmarshal %T into %v at %s", e.Value, e.Type, path)
+}
+
+// Unmarshal stores v, a value as returned by Call, in the value pointed to
+// by dst. Arrays are stored in slices and arrays, structs in maps with
+// string keys and in Go structs. Struct members are matched to the exported
+// fields named by their xmlrpc tag or, without a tag, to fields of the same
+// name. A tag of "-" leaves the field alone.
+func Unmarshal(v interface{}, dst interface{}) error {
+	return UnmarshalOptions{}.Unmarshal(v, dst)
+}
+
+// UnmarshalOptions changes how Unmarshal stores values.
+type UnmarshalOptions struct {
+	// CaseInsensitive matches struct members to fields whose name differs
+	// in case only, e.g. postid to a field tagged PostId, if no member
+	// matches exactly, like encoding/json does.
+	CaseInsensitive bool
+}
+
+// Unmarshal is like the function Unmarshal, with the options o.
+func (o UnmarshalOptions) Unmarshal(v interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("xmlrpc: Unmarshal needs a non-nil pointer")
+	}
+	return o.unmarshal(v, rv.Elem(), "")
+}
+
+func unmarshal(v interface{}, dst reflect.Value, path string) error {
+	return UnmarshalOptions{}.unmarshal(v, dst, path)
+}
+
+func (o UnmarshalOptions) unmarshal(v interface{}, dst reflect.Value, path string) error {
+	mismatch := func() error {
+		return &UnmarshalTypeError{Value: v, Type: dst.Type(), Path: path}
+	}
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return o.unmarshal(v, dst.Elem(), path)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(dst.Type()) {
+		dst.Set(rv)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(v)
+		if !ok || dst.OverflowInt(i) {
+			return mismatch()
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := toInt64(v)
+		if !ok || i < 0 || dst.OverflowUint(uint64(i)) {
+			return mismatch()
+		}
+		dst.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		if n, ok := v.(float64); ok {
+			dst.SetFloat(n)
+		} else if i, ok := toInt64(v); ok {
+			dst.SetFloat(float64(i))
+		} else {
+			return mismatch()
+		}
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetBool(b)
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetString(s)
+	case reflect.Slice:
+		if b, ok := v.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(b)
+			return nil
+		}
+		ar, ok := v.(Array)
+		if !ok {
+			return mismatch()
+		}
+		s := reflect.MakeSlice(dst.Type(), len(ar), len(ar))
+		for i, e := range ar {
+			if err := o.unmarshal(e, s.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(s)
+	case reflect.Array:
+		ar, ok := v.(Array)
+		if !ok || len(ar) > dst.Len() {
+			return mismatch()
+		}
+		for i, e := range ar {
+			if err := o.unmarshal(e, dst.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		st, ok := v.(Struct)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		m := reflect.MakeMap(dst.Type())
+		for k, e := range st {
+			ev := reflect.New(dst.Type().Elem()).Elem()
+			if err := o.unmarshal(e, ev, path+"."+k); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), ev)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		st, ok := v.(Struct)
+		if !ok {
+			return mismatch()
+		}
+		t := dst.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := fieldName(f)
+			if !ok {
+				continue
+			}
+			e, ok := st[name]
+			if !ok && o.CaseInsensitive {
+				e, ok = foldMember(st, name)
+			}
+			if !ok {
+				continue
+			}
+			if err := o.unmarshal(e, dst.Field(i), path+"."+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+// foldMember returns the member of st whose name equals name apart from
+// case. If several do, the one whose name sorts first is returned, so that
+// the result doesn't depend on the order of the map.
+func foldMember(st Struct, name string) (interface{}, bool) {
+	var found string
+	for k := range st {
+		if strings.EqualFold(k, name) && (found == "" || k < found) {
+			found = k
+		}
+	}
+	if found == "" {
+		return nil, false
+	}
+	return st[found], true
+}
+
+// omitEmpty reports whether the tag of f has the omitempty option, which
+// leaves the field out of encoded structs if it has its zero value.
+func omitEmpty(f reflect.StructField) bool {
+	tag := f.Tag.Get("xmlrpc")
+	if i := strings.Index(tag, ","); i >= 0 {
+		for _, opt := range strings.Split(tag[i+1:], ",") {
+			if opt == "omitempty" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldName returns the member name of the struct field f, and false if
+// the field is not encoded.
+func fieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	tag := f.Tag.Get("xmlrpc")
+	if tag == "-" {
+		return "", false
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag != "" {
+		return tag, true
+	}
+	return f.Name, true
+}
+
+// toInt64 returns the decoded integer v, whatever its IntType.
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
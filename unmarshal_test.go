@@ -0,0 +1,79 @@
+package xmlrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshal(t *testing.T) {
+	type Post struct {
+		ID      int       `xmlrpc:"postid"`
+		Title   string    `xmlrpc:"title"`
+		Created time.Time `xmlrpc:"dateCreated"`
+		Tags    []string  `xmlrpc:"tags"`
+		Score   float64
+		Sticky  *bool
+		Ignored string `xmlrpc:"-"`
+	}
+	now := time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)
+	v := Array{
+		Struct{"postid": 1, "title": "a", "dateCreated": now, "tags": Array{"x", "y"}, "Score": 2, "Sticky": true, "Ignored": "z"},
+	}
+	var posts []Post
+	if err := Unmarshal(v, &posts); err != nil {
+		t.Fatal(err)
+	}
+	sticky := true
+	want := []Post{{ID: 1, Title: "a", Created: now, Tags: []string{"x", "y"}, Score: 2, Sticky: &sticky}}
+	if !reflect.DeepEqual(posts, want) {
+		t.Fatalf("want %+v but got %+v", want, posts)
+	}
+
+	var m map[string]int
+	if err := Unmarshal(Struct{"a": 1, "b": 2}, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m["a"] != 1 || m["b"] != 2 {
+		t.Fatalf("want map[a:1 b:2] but got %v", m)
+	}
+
+	err := Unmarshal(Array{Struct{"title": 1}}, &posts)
+	var ute *UnmarshalTypeError
+	if !errors.As(err, &ute) || ute.Path != "[0].title" {
+		t.Fatalf("want UnmarshalTypeError at [0].title but got %v", err)
+	}
+	var i8 int8
+	if err := Unmarshal(1000, &i8); err == nil {
+		t.Fatal("want overflow error")
+	}
+}
+
+func TestUnmarshalCaseInsensitive(t *testing.T) {
+	type post struct {
+		PostID string `xmlrpc:"PostId"`
+		Title  string `xmlrpc:"title"`
+	}
+	v := Struct{"postid": "42", "Title": "other", "title": "hello"}
+
+	var p post
+	if err := Unmarshal(v, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.PostID != "" || p.Title != "hello" {
+		t.Fatalf("want exact matches only but got %+v", p)
+	}
+	p = post{}
+	if err := (UnmarshalOptions{CaseInsensitive: true}).Unmarshal(v, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.PostID != "42" || p.Title != "hello" {
+		t.Fatalf("want case-insensitive match preferring exact ones but got %+v", p)
+	}
+
+	var ps []post
+	if err := (UnmarshalOptions{CaseInsensitive: true}).Unmarshal(Array{Struct{"POSTID": "1"}}, &ps); err != nil || ps[0].PostID != "1" {
+		t.Fatalf("want nested structs matched but got %+v, %v", ps, err)
+	}
+}
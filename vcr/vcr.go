@@ -0,0 +1,188 @@
+// Package vcr provides an http.RoundTripper which records XML-RPC calls to a
+// file and replays them, so tests against real servers can run without
+// network access.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to the real server.
+type Mode int
+
+const (
+	// Replay serves responses from the cassette and fails for calls which
+	// were not recorded.
+	Replay Mode = iota
+	// Record sends every call to the server and records it, replacing the
+	// cassette.
+	Record
+	// ReplayOrRecord serves recorded calls from the cassette and records
+	// the others.
+	ReplayOrRecord
+)
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	URL      string      `json:"url"`
+	Request  string      `json:"request"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Response string      `json:"response"`
+	used     bool
+}
+
+// Recorder is an http.RoundTripper which records and replays calls. Use it as
+// the Transport of the http.Client of an xmlrpc.Client.
+type Recorder struct {
+	// Transport is used to send calls to the server. If nil,
+	// http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	path         string
+	mode         Mode
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// ErrNotRecorded is returned in Replay mode for calls missing from the
+// cassette.
+var ErrNotRecorded = errors.New("vcr: call not recorded")
+
+// New returns a Recorder using the cassette file at path. In Replay and
+// ReplayOrRecord mode the cassette is loaded; it may only be missing in
+// ReplayOrRecord mode.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode == Record {
+		return r, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && mode == ReplayOrRecord {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &r.interactions); err != nil {
+		return nil, fmt.Errorf("vcr: %s: %v", path, err)
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	url := req.URL.String()
+
+	if r.mode != Record {
+		if in := r.find(url, body); in != nil {
+			return in.response(req), nil
+		}
+		if r.mode == Replay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, url, methodName(body))
+		}
+	}
+
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	res, err := t.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	rbody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	in := &Interaction{
+		URL:      url,
+		Request:  string(body),
+		Status:   res.StatusCode,
+		Header:   res.Header,
+		Response: string(rbody),
+		used:     true,
+	}
+	if err := r.add(in); err != nil {
+		return nil, err
+	}
+	return in.response(req), nil
+}
+
+// find returns the first unused interaction with the same URL and request
+// body or, failing that, with the same URL and method name. Struct members
+// are encoded in random order, so bodies of equal calls may differ.
+func (r *Recorder) find(url string, body []byte) *Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, in := range r.interactions {
+		if !in.used && in.URL == url && in.Request == string(body) {
+			in.used = true
+			return in
+		}
+	}
+	name := methodName(body)
+	for _, in := range r.interactions {
+		if !in.used && in.URL == url && methodName([]byte(in.Request)) == name {
+			in.used = true
+			return in
+		}
+	}
+	return nil
+}
+
+// add appends in to the cassette and saves it.
+func (r *Recorder) add(in *Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, in)
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+func (in *Interaction) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(in.Response))),
+		ContentLength: int64(len(in.Response)),
+		Request:       req,
+	}
+}
+
+var methodNameRe = regexp.MustCompile(`<methodName>\s*([^<]*?)\s*</methodName>`)
+
+func methodName(body []byte) string {
+	if m := methodNameRe.FindSubmatch(body); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package vcr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestRecordReplay(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`<?xml version="1.0"?><methodResponse><params><param><value><int>42</int></value></param></params></methodResponse>`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := New(path, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := xmlrpc.NewClient(ts.URL)
+	client.HttpClient = &http.Client{Transport: rec}
+	v, err := client.Call("answer", xmlrpc.Struct{"a": 1, "b": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 || calls != 1 {
+		t.Fatalf("want 42 from the server but got %v after %d calls", v, calls)
+	}
+	ts.Close()
+
+	rec, err = New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HttpClient = &http.Client{Transport: rec}
+	v, err = client.Call("answer", xmlrpc.Struct{"a": 1, "b": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 {
+		t.Fatalf("want 42 but got %v", v)
+	}
+	_, err = client.Call("other")
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("want ErrNotRecorded but got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package xmlrpc
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WithPath makes the client call the endpoint at path, which is resolved
+// against its URL like a link, e.g. "/blog2/xmlrpc.php".
+func WithPath(path string) Option {
+	return func(c *Client) {
+		base, err := url.Parse(c.url)
+		if err != nil {
+			c.url = path
+			return
+		}
+		ref, err := url.Parse(path)
+		if err != nil {
+			// Let the calls fail with the error.
+			c.url = path
+			return
+		}
+		c.url = base.ResolveReference(ref).String()
+	}
+}
+
+// WithHeader sets the header key to value in every request of the client,
+// replacing a value set before.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		h := c.header.Clone()
+		if h == nil {
+			h = http.Header{}
+		}
+		h.Set(key, value)
+		c.header = h
+	}
+}
+
+// WithTimeout sets the time limit of the requests of the client, which is
+// 10 seconds by default. Zero means no limit. It sets the timeout of a
+// copy of the http.Client of the client.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := *c.HttpClient
+		hc.Timeout = d
+		c.HttpClient = &hc
+	}
+}
+
+// With returns a new client with the settings of c changed by opts. It
+// shares the transport of c, and so its connections, unless opts replace
+// it, as well as the cache, coalescing and concurrency limit of c, so it
+// is cheap to derive clients e.g. per tenant or per blog. c is unchanged.
+func (c *Client) With(opts ...Option) *Client {
+	c.mu.Lock()
+	hc := *c.HttpClient
+	d := &Client{
+		HttpClient:  &hc,
+		url:         c.url,
+		enc:         c.enc,
+		dec:         c.dec,
+		callInfo:    c.callInfo,
+		reqDump:     c.reqDump,
+		resDump:     c.resDump,
+		retry:       c.retry,
+		cache:       c.cache,
+		hedge:       c.hedge,
+		flights:     c.flights,
+		limit:       c.limit,
+		signer:      c.signer,
+		token:       c.token,
+		traceHeader: c.traceHeader,
+		traceID:     c.traceID,
+		header:      c.header,
+		credentials: c.credentials,
+		caps:        c.caps,
+		methods:     c.methods,
+
+		decodeTimeout: c.decodeTimeout,
+		maxResponse:   c.maxResponse,
+		checkMethods:  c.checkMethods,
+	}
+	c.mu.Unlock()
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.url != c.url {
+		// The capabilities are those of another server.
+		d.caps = nil
+		d.methods = nil
+		d.enc.i8 = false
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWith(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("whoami", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		return p.Header.Get("X-Tenant") + " " + p.Header.Get("X-App"), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c := NewClient(ts.URL+"/a/xmlrpc", WithHeader("X-Tenant", "acme"), WithHeader("X-App", "test"))
+	d := c.With(WithHeader("X-Tenant", "globex"), WithPath("/b/xmlrpc"), WithTimeout(time.Minute))
+	if d.url != ts.URL+"/b/xmlrpc" || c.url != ts.URL+"/a/xmlrpc" {
+		t.Fatalf("unexpected URLs %q and %q", c.url, d.url)
+	}
+	if d.HttpClient.Timeout != time.Minute || c.HttpClient.Timeout != 10*time.Second {
+		t.Fatalf("unexpected timeouts %v and %v", c.HttpClient.Timeout, d.HttpClient.Timeout)
+	}
+	if d.HttpClient.Transport != c.HttpClient.Transport {
+		t.Fatal("want transport shared")
+	}
+	for client, want := range map[*Client]string{c: "acme test", d: "globex test"} {
+		v, err := client.Call("whoami")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != want {
+			t.Fatalf("want %q but got %v", want, v)
+		}
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	hc := &http.Client{Timeout: time.Second}
+	c := NewClient("http://localhost/", WithHTTPClient(hc), WithTimeout(time.Minute))
+	if c.HttpClient == hc || c.HttpClient.Timeout != time.Minute || hc.Timeout != time.Second {
+		t.Fatalf("want copy of http.Client changed but got %+v of %+v", c.HttpClient, hc)
+	}
+}
+
+func TestWithTimeoutShared(t *testing.T) {
+	base := NewClient("http://localhost/")
+	hc := &http.Client{Timeout: time.Second}
+	base.SetHTTPClient(hc)
+	WithTimeout(time.Minute)(base)
+	if hc.Timeout != time.Second || base.HttpClient.Timeout != time.Minute {
+		t.Fatalf("want copy of shared http.Client changed but got %v", hc.Timeout)
+	}
+	c := base.With(WithTimeout(time.Hour))
+	if base.HttpClient.Timeout != time.Minute || c.HttpClient.Timeout != time.Hour {
+		t.Fatalf("want timeout of derived client only changed but got %v", base.HttpClient.Timeout)
+	}
+}
@@ -0,0 +1,246 @@
+// Package wordpress is a client for the wp.* methods of the XML-RPC API of
+// WordPress, served at /xmlrpc.php of a blog.
+package wordpress
+
+import (
+	"context"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Client calls the methods of a WordPress blog as a user.
+type Client struct {
+	c        *xmlrpc.Client
+	BlogID   int // ignored by single site installations
+	Username string
+	Password string
+}
+
+// New returns a Client of the blog whose XML-RPC endpoint is url, e.g.
+// https://example.com/xmlrpc.php.
+func New(url, username, password string, opts ...xmlrpc.Option) *Client {
+	return &Client{c: xmlrpc.NewClient(url, opts...), BlogID: 1, Username: username, Password: password}
+}
+
+// XMLRPCClient returns the underlying client, for methods this package
+// doesn't wrap.
+func (c *Client) XMLRPCClient() *xmlrpc.Client {
+	return c.c
+}
+
+// call calls the method name with the blog ID and credentials followed by
+// args, storing the result in r.
+func (c *Client) call(ctx context.Context, r interface{}, name string, args ...interface{}) error {
+	v, err := c.c.CallContext(ctx, name, append([]interface{}{c.BlogID, c.Username, c.Password}, args...)...)
+	if err != nil {
+		return err
+	}
+	return xmlrpc.Unmarshal(v, r)
+}
+
+// Post is a post or page as returned by WordPress.
+type Post struct {
+	ID            string        `xmlrpc:"post_id"`
+	Title         string        `xmlrpc:"post_title"`
+	Date          time.Time     `xmlrpc:"post_date"`
+	DateGMT       time.Time     `xmlrpc:"post_date_gmt"`
+	Modified      time.Time     `xmlrpc:"post_modified"`
+	ModifiedGMT   time.Time     `xmlrpc:"post_modified_gmt"`
+	Status        string        `xmlrpc:"post_status"`
+	Type          string        `xmlrpc:"post_type"`
+	Format        string        `xmlrpc:"post_format"`
+	Name          string        `xmlrpc:"post_name"`
+	Author        string        `xmlrpc:"post_author"`
+	Password      string        `xmlrpc:"post_password"`
+	Excerpt       string        `xmlrpc:"post_excerpt"`
+	Content       string        `xmlrpc:"post_content"`
+	Parent        string        `xmlrpc:"post_parent"`
+	MimeType      string        `xmlrpc:"post_mime_type"`
+	Link          string        `xmlrpc:"link"`
+	GUID          string        `xmlrpc:"guid"`
+	MenuOrder     int           `xmlrpc:"menu_order"`
+	CommentStatus string        `xmlrpc:"comment_status"`
+	PingStatus    string        `xmlrpc:"ping_status"`
+	Sticky        bool          `xmlrpc:"sticky"`
+	Terms         []Term        `xmlrpc:"terms"`
+	CustomFields  []CustomField `xmlrpc:"custom_fields"`
+}
+
+// PostContent holds the fields of a post to create or change. Fields with
+// their zero value are left out.
+type PostContent struct {
+	Type          string        `xmlrpc:"post_type,omitempty"`
+	Status        string        `xmlrpc:"post_status,omitempty"`
+	Title         string        `xmlrpc:"post_title,omitempty"`
+	Author        int           `xmlrpc:"post_author,omitempty"`
+	Excerpt       string        `xmlrpc:"post_excerpt,omitempty"`
+	Content       string        `xmlrpc:"post_content,omitempty"`
+	Date          time.Time     `xmlrpc:"post_date,omitempty"`
+	Format        string        `xmlrpc:"post_format,omitempty"`
+	Name          string        `xmlrpc:"post_name,omitempty"`
+	Password      string        `xmlrpc:"post_password,omitempty"`
+	CommentStatus string        `xmlrpc:"comment_status,omitempty"`
+	PingStatus    string        `xmlrpc:"ping_status,omitempty"`
+	Sticky        bool          `xmlrpc:"sticky,omitempty"`
+	Thumbnail     int           `xmlrpc:"post_thumbnail,omitempty"`
+	Parent        int           `xmlrpc:"post_parent,omitempty"`
+	CustomFields  []CustomField `xmlrpc:"custom_fields,omitempty"`
+
+	// Terms are IDs of terms by taxonomy, TermNames names of terms by
+	// taxonomy, which are created if they don't exist.
+	Terms     map[string][]int    `xmlrpc:"terms,omitempty"`
+	TermNames map[string][]string `xmlrpc:"terms_names,omitempty"`
+}
+
+// CustomField is a custom field of a post.
+type CustomField struct {
+	ID    string `xmlrpc:"id,omitempty"`
+	Key   string `xmlrpc:"key"`
+	Value string `xmlrpc:"value"`
+}
+
+// PostFilter selects the posts returned by GetPosts.
+type PostFilter struct {
+	Type    string `xmlrpc:"post_type,omitempty"`
+	Status  string `xmlrpc:"post_status,omitempty"`
+	Number  int    `xmlrpc:"number,omitempty"`
+	Offset  int    `xmlrpc:"offset,omitempty"`
+	OrderBy string `xmlrpc:"orderby,omitempty"`
+	Order   string `xmlrpc:"order,omitempty"`
+}
+
+// GetPosts returns the posts matching filter, by default the ten most
+// recent ones.
+func (c *Client) GetPosts(ctx context.Context, filter PostFilter) ([]Post, error) {
+	var posts []Post
+	err := c.call(ctx, &posts, "wp.getPosts", filter)
+	return posts, err
+}
+
+// GetPost returns the post with the ID id.
+func (c *Client) GetPost(ctx context.Context, id string) (*Post, error) {
+	var post Post
+	if err := c.call(ctx, &post, "wp.getPost", id); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// NewPost creates a post, returning its ID.
+func (c *Client) NewPost(ctx context.Context, content PostContent) (string, error) {
+	var id string
+	err := c.call(ctx, &id, "wp.newPost", content)
+	return id, err
+}
+
+// EditPost changes the fields of the post with the ID id set in content.
+func (c *Client) EditPost(ctx context.Context, id string, content PostContent) error {
+	var ok bool
+	return c.call(ctx, &ok, "wp.editPost", id, content)
+}
+
+// DeletePost moves the post with the ID id to the trash.
+func (c *Client) DeletePost(ctx context.Context, id string) error {
+	var ok bool
+	return c.call(ctx, &ok, "wp.deletePost", id)
+}
+
+// File is a file to upload to the media library.
+type File struct {
+	Name      string `xmlrpc:"name"`
+	Type      string `xmlrpc:"type"` // MIME type
+	Bits      []byte `xmlrpc:"bits"`
+	Overwrite bool   `xmlrpc:"overwrite,omitempty"`
+	PostID    int    `xmlrpc:"post_id,omitempty"` // post to attach the file to
+}
+
+// Upload describes an uploaded file.
+type Upload struct {
+	ID   string `xmlrpc:"id"`
+	File string `xmlrpc:"file"`
+	URL  string `xmlrpc:"url"`
+	Type string `xmlrpc:"type"`
+}
+
+// UploadFile uploads f to the media library.
+func (c *Client) UploadFile(ctx context.Context, f File) (*Upload, error) {
+	var u Upload
+	if err := c.call(ctx, &u, "wp.uploadFile", f); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Taxonomy is a taxonomy such as category or post_tag.
+type Taxonomy struct {
+	Name         string   `xmlrpc:"name"`
+	Label        string   `xmlrpc:"label"`
+	Hierarchical bool     `xmlrpc:"hierarchical"`
+	Public       bool     `xmlrpc:"public"`
+	ShowUI       bool     `xmlrpc:"show_ui"`
+	Builtin      bool     `xmlrpc:"_builtin"`
+	ObjectType   []string `xmlrpc:"object_type"`
+}
+
+// GetTaxonomies returns the taxonomies of the blog.
+func (c *Client) GetTaxonomies(ctx context.Context) ([]Taxonomy, error) {
+	var taxonomies []Taxonomy
+	err := c.call(ctx, &taxonomies, "wp.getTaxonomies")
+	return taxonomies, err
+}
+
+// GetTaxonomy returns the taxonomy name.
+func (c *Client) GetTaxonomy(ctx context.Context, name string) (*Taxonomy, error) {
+	var t Taxonomy
+	if err := c.call(ctx, &t, "wp.getTaxonomy", name); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Term is a term of a taxonomy, such as a category or tag.
+type Term struct {
+	ID          string `xmlrpc:"term_id"`
+	Name        string `xmlrpc:"name"`
+	Slug        string `xmlrpc:"slug"`
+	Group       string `xmlrpc:"term_group"`
+	TaxonomyID  string `xmlrpc:"term_taxonomy_id"`
+	Taxonomy    string `xmlrpc:"taxonomy"`
+	Description string `xmlrpc:"description"`
+	Parent      string `xmlrpc:"parent"`
+	Count       int    `xmlrpc:"count"`
+}
+
+// TermFilter selects the terms returned by GetTerms.
+type TermFilter struct {
+	Number    int    `xmlrpc:"number,omitempty"`
+	Offset    int    `xmlrpc:"offset,omitempty"`
+	OrderBy   string `xmlrpc:"orderby,omitempty"`
+	Order     string `xmlrpc:"order,omitempty"`
+	HideEmpty bool   `xmlrpc:"hide_empty,omitempty"`
+	Search    string `xmlrpc:"search,omitempty"`
+}
+
+// GetTerms returns the terms of taxonomy matching filter.
+func (c *Client) GetTerms(ctx context.Context, taxonomy string, filter TermFilter) ([]Term, error) {
+	var terms []Term
+	err := c.call(ctx, &terms, "wp.getTerms", taxonomy, filter)
+	return terms, err
+}
+
+// TermContent holds the fields of a term to create.
+type TermContent struct {
+	Name        string `xmlrpc:"name"`
+	Taxonomy    string `xmlrpc:"taxonomy"`
+	Slug        string `xmlrpc:"slug,omitempty"`
+	Description string `xmlrpc:"description,omitempty"`
+	Parent      int    `xmlrpc:"parent,omitempty"`
+}
+
+// NewTerm creates a term, returning its ID.
+func (c *Client) NewTerm(ctx context.Context, t TermContent) (string, error) {
+	var id string
+	err := c.call(ctx, &id, "wp.newTerm", t)
+	return id, err
+}
@@ -0,0 +1,113 @@
+package wordpress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-xmlrpc"
+	"github.com/mattn/go-xmlrpc/xmlrpctest"
+)
+
+func TestGetPosts(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"wp.getPosts": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{
+				"post_id":    "42",
+				"post_title": "Hello",
+				"post_date":  date,
+				"menu_order": 0,
+				"sticky":     true,
+				"terms": xmlrpc.Array{
+					xmlrpc.Struct{"term_id": "1", "name": "News", "taxonomy": "category", "count": 3},
+				},
+			},
+		}),
+	})
+	defer s.Close()
+
+	c := New(s.URL, "admin", "secret")
+	posts, err := c.GetPosts(context.Background(), PostFilter{Number: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("want 1 post but got %d", len(posts))
+	}
+	p := posts[0]
+	if p.ID != "42" || p.Title != "Hello" || !p.Date.Equal(date) || !p.Sticky || len(p.Terms) != 1 || p.Terms[0].Name != "News" || p.Terms[0].Count != 3 {
+		t.Fatalf("unexpected post: %+v", p)
+	}
+	s.AssertCalled(t, "wp.getPosts", 1, "admin", "secret", xmlrpc.Struct{"number": 5})
+}
+
+func TestNewPost(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"wp.newPost": xmlrpctest.Value("43"),
+	})
+	defer s.Close()
+
+	id, err := New(s.URL, "admin", "secret").NewPost(context.Background(), PostContent{
+		Title:     "Hello",
+		Status:    "publish",
+		TermNames: map[string][]string{"post_tag": {"go"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "43" {
+		t.Fatalf("want 43 but got %q", id)
+	}
+	s.AssertCalled(t, "wp.newPost", 1, "admin", "secret", xmlrpc.Struct{
+		"post_title":  "Hello",
+		"post_status": "publish",
+		"terms_names": xmlrpc.Struct{"post_tag": xmlrpc.Array{"go"}},
+	})
+}
+
+func TestUploadFile(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"wp.uploadFile": xmlrpctest.Value(xmlrpc.Struct{"id": "7", "file": "a.png", "url": "http://example.com/a.png", "type": "image/png"}),
+	})
+	defer s.Close()
+
+	u, err := New(s.URL, "admin", "secret").UploadFile(context.Background(), File{Name: "a.png", Type: "image/png", Bits: []byte{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != "7" || u.URL != "http://example.com/a.png" {
+		t.Fatalf("unexpected upload: %+v", u)
+	}
+	s.AssertCalled(t, "wp.uploadFile", 1, "admin", "secret", xmlrpc.Struct{"name": "a.png", "type": "image/png", "bits": []byte{1, 2}})
+}
+
+func TestGetTerms(t *testing.T) {
+	s := xmlrpctest.NewServer(map[string]xmlrpc.HandlerFunc{
+		"wp.getTerms": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"term_id": "1", "name": "News", "slug": "news", "taxonomy": "category", "count": 3},
+		}),
+		"wp.getTaxonomies": xmlrpctest.Value(xmlrpc.Array{
+			xmlrpc.Struct{"name": "category", "label": "Categories", "hierarchical": true, "object_type": xmlrpc.Array{"post"}},
+		}),
+	})
+	defer s.Close()
+	c := New(s.URL, "admin", "secret")
+
+	terms, err := c.GetTerms(context.Background(), "category", TermFilter{HideEmpty: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terms) != 1 || terms[0].Slug != "news" {
+		t.Fatalf("unexpected terms: %+v", terms)
+	}
+	s.AssertCalled(t, "wp.getTerms", 1, "admin", "secret", "category", xmlrpc.Struct{"hide_empty": true})
+
+	taxonomies, err := c.GetTaxonomies(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taxonomies) != 1 || !taxonomies[0].Hierarchical || taxonomies[0].ObjectType[0] != "post" {
+		t.Fatalf("unexpected taxonomies: %+v", taxonomies)
+	}
+}
@@ -2,361 +2,682 @@ package xmlrpc
 
 import (
 	"bytes"
-	"encoding/base64"
-	"encoding/xml"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"reflect"
-	"strconv"
-	"strings"
+	"net/http/httptrace"
+	"sync"
 	"time"
 )
 
 type Array []interface{}
 type Struct map[string]interface{}
 
-var xmlSpecial = map[byte]string{
-	'<':  "&lt;",
-	'>':  "&gt;",
-	'"':  "&quot;",
-	'\'': "&apos;",
-	'&':  "&amp;",
-}
-
-func xmlEscape(s string) string {
-	var b bytes.Buffer
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if s, ok := xmlSpecial[c]; ok {
-			b.WriteString(s)
-		} else {
-			b.WriteByte(c)
-		}
+// Client is client of XMLRPC. A Client is safe for concurrent use by
+// multiple goroutines. Its settings are fixed by the options it is created
+// with; use With to derive a client with other settings. HttpClient must
+// not be changed once the client is in use, use SetHTTPClient instead.
+type Client struct {
+	HttpClient *http.Client
+	url        string
+	enc        encoder
+	dec        decodeOptions
+	callInfo   func(*CallInfo)
+	reqDump    io.Writer
+	resDump    io.Writer
+	retry      RetryPolicy
+	cache      *cachePolicy
+	hedge      *hedgePolicy
+	flights    *flightGroup
+	limit      *clientLimit
+	signer     func(body []byte, header http.Header) error
+	token      func(ctx context.Context) (string, error)
+	header     http.Header
+
+	// credentials identifies the token and signer in the keys of cached
+	// and coalesced calls.
+	credentials string
+
+	decodeTimeout time.Duration
+	maxResponse   int64
+
+	traceHeader string
+	traceID     func(ctx context.Context) string
+
+	checkMethods bool
+
+	mu      sync.Mutex
+	caps    *Capabilities
+	methods map[string]bool // of system.listMethods
+
+	// decoders holds the decoders of finished calls, whose buffers are
+	// reused; see decoder.reset.
+	decoders sync.Pool
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithUnsafeXML allows DOCTYPE declarations, other directives and processing
+// instructions in responses. They are rejected by default; only use this
+// with trusted legacy peers that insist on sending them.
+func WithUnsafeXML() Option {
+	return func(c *Client) {
+		c.dec.unsafeXML = true
 	}
-	return b.String()
 }
 
-type valueNode struct {
-	Type string `xml:"attr"`
-	Body string `xml:"chardata"`
+// WithCharsetReader sets the function used to convert responses declaring a
+// charset other than UTF-8. By default only US-ASCII, ISO-8859-1 and
+// Windows-1252 are supported, as the package has no dependencies; responses
+// in other charsets such as GBK, Big5 or Shift_JIS fail to decode unless fn
+// handles them, e.g. charset.NewReaderLabel of golang.org/x/net/html/charset.
+func WithCharsetReader(fn CharsetReader) Option {
+	return func(c *Client) {
+		c.dec.charsetReader = fn
+	}
 }
 
-func next(p *xml.Decoder) (xml.Name, interface{}, error) {
-	se, e := nextStart(p)
-	if e != nil {
-		return xml.Name{}, nil, e
+// WithEntityMap sets the entities, in addition to the five predefined by XML,
+// that are recognized in responses. Passing xml.HTMLEntity makes responses
+// of servers that emit e.g. &nbsp; decode instead of failing.
+func WithEntityMap(entity map[string]string) Option {
+	return func(c *Client) {
+		c.dec.entity = entity
 	}
+}
 
-	var nv interface{}
-	switch se.Name.Local {
-	case "string":
-		var s string
-		if e = p.DecodeElement(&s, &se); e != nil {
-			return xml.Name{}, nil, e
-		}
-		return xml.Name{}, s, nil
-	case "boolean":
-		var s string
-		if e = p.DecodeElement(&s, &se); e != nil {
-			return xml.Name{}, nil, e
-		}
-		s = strings.TrimSpace(s)
-		var b bool
-		switch s {
-		case "true", "1":
-			b = true
-		case "false", "0":
-			b = false
-		default:
-			e = errors.New("invalid boolean value")
-		}
-		return xml.Name{}, b, e
-	case "int", "i1", "i2", "i4", "i8":
-		var s string
-		var i int
-		if e = p.DecodeElement(&s, &se); e != nil {
-			return xml.Name{}, nil, e
-		}
-		i, e = strconv.Atoi(strings.TrimSpace(s))
-		return xml.Name{}, i, e
-	case "double":
-		var s string
-		var f float64
-		if e = p.DecodeElement(&s, &se); e != nil {
-			return xml.Name{}, nil, e
-		}
-		f, e = strconv.ParseFloat(strings.TrimSpace(s), 64)
-		return xml.Name{}, f, e
-	case "dateTime.iso8601":
-		var s string
-		if e = p.DecodeElement(&s, &se); e != nil {
-			return xml.Name{}, nil, e
-		}
-		t, e := time.Parse("20060102T15:04:05", s)
-		if e != nil {
-			t, e = time.Parse("2006-01-02T15:04:05-07:00", s)
-			if e != nil {
-				t, e = time.Parse("2006-01-02T15:04:05", s)
-			}
-		}
-		return xml.Name{}, t, e
-	case "base64":
-		var s string
-		if e = p.DecodeElement(&s, &se); e != nil {
-			return xml.Name{}, nil, e
-		}
-		if b, e := base64.StdEncoding.DecodeString(s); e != nil {
-			return xml.Name{}, nil, e
-		} else {
-			return xml.Name{}, b, nil
-		}
-	case "member":
-		nextStart(p)
-		return next(p)
-	case "value":
-		nextStart(p)
-		return next(p)
-	case "name":
-		nextStart(p)
-		return next(p)
-	case "struct":
-		st := Struct{}
-
-		se, e = nextStart(p)
-		for e == nil && se.Name.Local == "member" {
-			// name
-			se, e = nextStart(p)
-			if se.Name.Local != "name" {
-				return xml.Name{}, nil, errors.New("invalid response")
-			}
-			if e != nil {
-				break
-			}
-			var name string
-			if e = p.DecodeElement(&name, &se); e != nil {
-				return xml.Name{}, nil, e
-			}
-			se, e = nextStart(p)
-			if e != nil {
-				break
-			}
-
-			// value
-			_, value, e := next(p)
-			if se.Name.Local != "value" {
-				return xml.Name{}, nil, errors.New("invalid response")
-			}
-			if e != nil {
-				break
-			}
-			st[name] = value
-
-			se, e = nextStart(p)
-			if e != nil {
-				break
-			}
-		}
-		return xml.Name{}, st, nil
-	case "array":
-		var ar Array
-		nextStart(p) // data
-		nextStart(p) // top of value
-		for {
-			_, value, e := next(p)
-			if e != nil {
-				break
-			}
-			ar = append(ar, value)
-
-			if reflect.ValueOf(value).Kind() != reflect.Map {
-				nextStart(p)
-			}
-		}
-		return xml.Name{}, ar, nil
-	case "nil":
-		return xml.Name{}, nil, nil
+// WithSkipLeadingJunk makes the client skip up to limit bytes of garbage,
+// such as warnings printed by PHP, in front of the XML declaration or the
+// methodResponse element of responses.
+func WithSkipLeadingJunk(limit int) Option {
+	return func(c *Client) {
+		c.dec.junkLimit = limit
 	}
+}
 
-	if e = p.DecodeElement(nv, &se); e != nil {
-		return xml.Name{}, nil, e
+// WithCDATA makes the client wrap strings containing at least n markup
+// characters (<, >, &, quotes) in CDATA sections instead of escaping them,
+// which some consumers require.
+func WithCDATA(n int) Option {
+	return func(c *Client) {
+		c.enc.cdata = n
 	}
-	return se.Name, nv, e
 }
-func nextStart(p *xml.Decoder) (xml.StartElement, error) {
-	for {
-		t, e := p.Token()
-		if e != nil {
-			return xml.StartElement{}, e
-		}
-		switch t := t.(type) {
-		case xml.StartElement:
-			return t, nil
-		}
+
+// WithWhitespace sets how whitespace surrounding strings, untyped values and
+// member names in responses is handled.
+func WithWhitespace(ws Whitespace) Option {
+	return func(c *Client) {
+		c.dec.whitespace = ws
 	}
-	panic("unreachable")
 }
 
-func toXml(v interface{}, typ bool) (s string) {
-	if v == nil {
-		return "<nil/>"
+// WithDuplicateMembers sets how struct members that occur more than once in
+// responses are handled.
+func WithDuplicateMembers(policy DuplicatePolicy) Option {
+	return func(c *Client) {
+		c.dec.duplicates = policy
 	}
-	r := reflect.ValueOf(v)
-	t := r.Type()
-	k := t.Kind()
+}
 
-	if b, ok := v.([]byte); ok {
-		return "<base64>" + base64.StdEncoding.EncodeToString(b) + "</base64>"
+// WithOmitEmptyParams makes the client leave out the params element of calls
+// without arguments, which some strict servers reject when it is empty.
+func WithOmitEmptyParams() Option {
+	return func(c *Client) {
+		c.enc.omitEmptyParams = true
 	}
+}
 
-	switch k {
-	case reflect.Invalid:
-		panic("unsupported type")
-	case reflect.Bool:
-		return fmt.Sprintf("<boolean>%v</boolean>", v)
-	case reflect.Int,
-		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint,
-		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if typ {
-			return fmt.Sprintf("<int>%v</int>", v)
-		}
-		return fmt.Sprintf("%v", v)
-	case reflect.Uintptr:
-		panic("unsupported type")
-	case reflect.Float32, reflect.Float64:
-		if typ {
-			return fmt.Sprintf("<double>%v</double>", v)
-		}
-		return fmt.Sprintf("%v", v)
-	case reflect.Complex64, reflect.Complex128:
-		panic("unsupported type")
-	case reflect.Array:
-		s = "<array><data>"
-		for n := 0; n < r.Len(); n++ {
-			s += "<value>"
-			s += toXml(r.Index(n).Interface(), typ)
-			s += "</value>"
-		}
-		s += "</data></array>"
-		return s
-	case reflect.Chan:
-		panic("unsupported type")
-	case reflect.Func:
-		panic("unsupported type")
-	case reflect.Interface:
-		return toXml(r.Elem(), typ)
-	case reflect.Map:
-		s = "<struct>"
-		for _, key := range r.MapKeys() {
-			s += "<member>"
-			s += "<name>" + xmlEscape(key.Interface().(string)) + "</name>"
-			s += "<value>" + toXml(r.MapIndex(key).Interface(), typ) + "</value>"
-			s += "</member>"
-		}
-		s += "</struct>"
-		return s
-	case reflect.Ptr:
-		panic("unsupported type")
-	case reflect.Slice:
-		s = "<array><data>"
-		for n := 0; n < r.Len(); n++ {
-			s += "<value>"
-			s += toXml(r.Index(n).Interface(), typ)
-			s += "</value>"
-		}
-		s += "</data></array>"
-		return s
-	case reflect.String:
-		if typ {
-			return fmt.Sprintf("<string>%v</string>", xmlEscape(v.(string)))
-		}
-		return xmlEscape(v.(string))
-	case reflect.Struct:
-		s = "<struct>"
-		for n := 0; n < r.NumField(); n++ {
-			s += "<member>"
-			s += "<name>" + t.Field(n).Name + "</name>"
-			s += "<value>" + toXml(r.FieldByIndex([]int{n}).Interface(), true) + "</value>"
-			s += "</member>"
-		}
-		s += "</struct>"
-		return s
-	case reflect.UnsafePointer:
-		return toXml(r.Elem(), typ)
+// WithAnyMethodName disables the validation of method names, for servers
+// using names with whitespace or other unusual characters. The name is still
+// escaped.
+func WithAnyMethodName() Option {
+	return func(c *Client) {
+		c.enc.anyMethodName = true
 	}
-	return
 }
 
-// Client is client of XMLRPC
-type Client struct {
-	HttpClient *http.Client
-	url        string
+// WithIndent makes the client send requests with one element per line,
+// indented by indent per nesting level, which is easier to read in packet
+// captures and server logs. Use Indent to format captured responses.
+// Indented requests are built in memory as a whole, so Base64Reader and
+// ArrayStream arguments aren't streamed.
+func WithIndent(indent string) Option {
+	return func(c *Client) {
+		c.enc.indent = indent
+	}
+}
+
+// WithASCII makes the client escape non-ASCII characters in requests as
+// numeric character references, for servers that can't handle raw UTF-8.
+func WithASCII() Option {
+	return func(c *Client) {
+		c.enc.ascii = true
+	}
+}
+
+// WithXMLDeclaration makes the client declare encoding, e.g. "UTF-8", in
+// the XML declaration of requests, which some strict servers require. The
+// requests are still encoded in UTF-8; declare another encoding only if the
+// transport transcodes them.
+func WithXMLDeclaration(encoding string) Option {
+	return func(c *Client) {
+		c.enc.encoding = encoding
+		c.enc.noDeclaration = false
+	}
+}
+
+// WithoutXMLDeclaration makes the client leave out the XML declaration of
+// requests.
+func WithoutXMLDeclaration() Option {
+	return func(c *Client) {
+		c.enc.noDeclaration = true
+	}
+}
+
+// WithUint64Policy sets how unsigned integers greater than math.MaxInt64
+// are sent. By default calls with such arguments fail.
+func WithUint64Policy(p Uint64Policy) Option {
+	return func(c *Client) {
+		c.enc.uint64 = p
+	}
+}
+
+// WithInvalidChars sets how characters XML doesn't allow, such as most
+// control characters, are sent in strings and member names.
+func WithInvalidChars(p InvalidCharPolicy) Option {
+	return func(c *Client) {
+		c.enc.invalidChars = p
+	}
+}
+
+// WithIntType sets the Go type integers in responses are decoded to, int
+// by default. Unmarshal accepts all of them.
+func WithIntType(t IntType) Option {
+	return func(c *Client) {
+		c.dec.ints = t
+	}
+}
+
+// WithDecodeLimits bounds the values of responses by l, for servers which
+// can't be trusted.
+func WithDecodeLimits(l DecodeLimits) Option {
+	return func(c *Client) {
+		c.dec.limits = l
+	}
+}
+
+// WithMaxResponseSize limits the size of response bodies to n bytes.
+// Larger responses fail with an error wrapping ErrDecodeLimit.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxResponse = n
+	}
+}
+
+// WithStrictScalars makes responses with elements or comments inside
+// scalar values, such as <string>a<b/></string>, fail to decode instead of
+// having them ignored.
+func WithStrictScalars() Option {
+	return func(c *Client) {
+		c.dec.strictScalars = true
+	}
+}
+
+// WithTrailingContent sets how content following </methodResponse> in
+// responses is handled. With TrailingHook, hook is called with up to 64KB
+// of it.
+func WithTrailingContent(p TrailingPolicy, hook func(content []byte)) Option {
+	return func(c *Client) {
+		c.dec.trailing = p
+		c.dec.trailingHook = hook
+	}
+}
+
+// WithStrict makes the client check responses against the XML-RPC
+// specification. Responses which violate it fail with a *SpecError listing
+// every violation, even if they could be decoded. A malformed fault is
+// wrapped in the *SpecError.
+func WithStrict() Option {
+	return func(c *Client) {
+		c.dec.strict = true
+	}
+}
+
+// WithLenient makes the client decode as much of malformed responses as it
+// can: values of unknown types and values which can't be parsed are
+// returned as RawValue, and unexpected elements in structs and arrays are
+// skipped.
+func WithLenient() Option {
+	return func(c *Client) {
+		c.dec.lenient = true
+	}
+}
+
+// WithUnwrapNestedValues makes the client decode values wrapped in
+// redundant value elements, such as
+// <value><value><string>x</string></value></value>, which some servers
+// send, as the innermost value instead of failing.
+func WithUnwrapNestedValues() Option {
+	return func(c *Client) {
+		c.dec.unwrapValues = true
+	}
+}
+
+// WithBase64Writer makes the client stream base64 values of responses into
+// the writer fn returns for the path of the value, e.g. params[0].value,
+// instead of decoding them into a []byte held in memory. The value is then
+// returned as a Base64Writer. If fn returns nil, the value is decoded as
+// usual.
+func WithBase64Writer(fn func(path string) io.Writer) Option {
+	return func(c *Client) {
+		c.dec.base64Writer = fn
+	}
+}
+
+// WithCallInfo sets a function which is called with the transfer statistics
+// of every call once it has finished.
+func WithCallInfo(fn func(*CallInfo)) Option {
+	return func(c *Client) {
+		c.callInfo = fn
+	}
+}
+
+// WithBearerToken sets a function which returns the token the client
+// sends as bearer token in the Authorization header of each request. It
+// is called for each attempt of a call, so it can refresh the token as it
+// expires; an error aborts the call. An oauth2.TokenSource fits as
+//
+//	func(ctx context.Context) (string, error) {
+//		t, err := ts.Token()
+//		if err != nil {
+//			return "", err
+//		}
+//		return t.AccessToken, nil
+//	}
+func WithBearerToken(fn func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.token = fn
+		c.credentials = newCredentials()
+	}
+}
+
+// WithSigner sets a function which is called before each attempt of a
+// call with the exact bytes of the request and its headers, which it may
+// set, e.g. to an HMAC signature of the body. An error aborts the call.
+// The body is nil for calls with Base64Reader arguments, which are
+// streamed.
+func WithSigner(fn func(body []byte, header http.Header) error) Option {
+	return func(c *Client) {
+		c.signer = fn
+		c.credentials = newCredentials()
+	}
+}
+
+// WithRequestDump makes the client copy the body of every request, exactly
+// as it is sent, to w. Writes of concurrent calls are not synchronized.
+func WithRequestDump(w io.Writer) Option {
+	return func(c *Client) {
+		c.reqDump = w
+	}
+}
+
+// WithResponseDump makes the client copy the body of every response, exactly
+// as it is received, to w. Writes of concurrent calls are not synchronized.
+func WithResponseDump(w io.Writer) Option {
+	return func(c *Client) {
+		c.resDump = w
+	}
+}
+
+// WithHTTPClient makes the client send its requests with a copy of hc.
+// Options changing the transport or the timeout which come after it apply
+// to the copy.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		h := *hc
+		c.HttpClient = &h
+	}
+}
+
+// WithTransport makes the client send its requests through rt, with a copy
+// of its http.Client. rt may be any http.RoundTripper; the package itself
+// only speaks HTTP/1.1 and HTTP/2, but the codec doesn't depend on the
+// version of HTTP. For servers behind HTTP/3 edges, WithHTTP3 of the
+// separate module github.com/mattn/go-xmlrpc/contrib/http3 passes a
+// transport of quic-go.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := *c.HttpClient
+		hc.Transport = rt
+		c.HttpClient = &hc
+	}
+}
+
+// SetHTTPClient makes the client send its requests with hc from then on.
+// Unlike assigning HttpClient, it may be called while calls are running.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.HttpClient = hc
+}
+
+// httpClient returns the http.Client requests are sent with.
+func (c *Client) httpClient() *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.HttpClient
 }
 
 // NewClient create new Client
-func NewClient(url string) *Client {
-	return &Client{
+func NewClient(url string, opts ...Option) *Client {
+	c := &Client{
 		HttpClient: &http.Client{Transport: http.DefaultTransport, Timeout: 10 * time.Second},
 		url:        url,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func makeRequest(name string, args ...interface{}) *bytes.Buffer {
-	buf := new(bytes.Buffer)
-	buf.WriteString(`<?xml version="1.0"?><methodCall>`)
-	buf.WriteString("<methodName>" + xmlEscape(name) + "</methodName>")
-	buf.WriteString("<params>")
-	for _, arg := range args {
-		buf.WriteString("<param><value>")
-		buf.WriteString(toXml(arg, true))
-		buf.WriteString("</value></param>")
+func (c *Client) call(ctx context.Context, name string, args []interface{}, decode func(*decoder) (interface{}, error)) (v interface{}, e error) {
+	if e := c.checkMethod(ctx, name); e != nil {
+		return nil, e
+	}
+	body, stream, e := c.encodeRequest(name, args)
+	if e != nil {
+		return nil, e
 	}
-	buf.WriteString("</params></methodCall>")
-	return buf
+	retry := c.retryPolicy(ctx)
+	for attempt := 1; ; attempt++ {
+		v, e = c.attempt(ctx, name, body, stream, decode)
+		if e == nil || attempt >= retry.MaxAttempts || !retry.retryable(e) || stream != nil || !retry.idempotent(ctx, name) {
+			return v, e
+		}
+		t := time.NewTimer(retry.backoff(attempt))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return v, e
+		}
+	}
+}
+
+// encodeRequest returns the body of the call of name with args, which is
+// sent unchanged by every attempt, or a stream if it has a Base64Reader
+// and can only be sent once.
+func (c *Client) encodeRequest(name string, args []interface{}) (body []byte, stream io.Reader, err error) {
+	c.mu.Lock()
+	enc := c.enc
+	c.mu.Unlock()
+	r, err := enc.makeRequest(name, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if buf, ok := r.(*bytes.Buffer); ok {
+		return buf.Bytes(), nil, nil
+	}
+	return nil, r, nil
 }
 
-func call(client *http.Client, url, name string, args ...interface{}) (v interface{}, e error) {
-	r, e := client.Post(url, "text/xml", makeRequest(name, args...))
+// do makes a single attempt of a call.
+func (c *Client) do(ctx context.Context, url, name string, body []byte, stream io.Reader, decode func(*decoder) (interface{}, error)) (v interface{}, e error) {
+	info := &CallInfo{Method: name}
+	if c.callInfo != nil {
+		start := time.Now()
+		defer func() {
+			info.Total = time.Since(start)
+			info.Err = e
+			c.callInfo(info)
+		}()
+	}
+
+	release, e := c.limit.acquire(ctx)
 	if e != nil {
 		return nil, e
 	}
+	defer release()
 
-	// Since we do not always read the entire body, discard the rest, which
-	// allows the http transport to reuse the connection.
-	defer io.Copy(ioutil.Discard, r.Body)
+	reqBody := stream
+	if reqBody == nil {
+		reqBody = bytes.NewReader(body)
+	}
+	if c.reqDump != nil {
+		reqBody = io.TeeReader(reqBody, c.reqDump)
+	}
+	req, e := http.NewRequestWithContext(ctx, "POST", url, countReader{reqBody, &info.RequestBytes})
+	if e != nil {
+		return nil, e
+	}
+	if stream == nil {
+		// Let the transport replay the body, e.g. on redirects.
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	for k, vs := range c.header {
+		req.Header[k] = append(req.Header[k], vs...)
+	}
+	o := callOptionsFrom(ctx)
+	for k, vs := range o.header {
+		req.Header[k] = append(req.Header[k], vs...)
+	}
+	req.Header.Set("Content-Type", "text/xml")
+	if c.traceID != nil {
+		if id := c.traceID(ctx); id != "" {
+			req.Header.Set(c.traceHeader, id)
+		}
+	}
+	if c.token != nil {
+		token, e := c.token(ctx)
+		if e != nil {
+			return nil, e
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if o.onRequest != nil {
+		o.onRequest(req)
+	}
+	if c.signer != nil {
+		if e = c.signer(body, req.Header); e != nil {
+			return nil, e
+		}
+	}
+	if c.callInfo != nil {
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), info.trace()))
+	}
+	r, e := c.httpClient().Do(req)
+	if e != nil {
+		return nil, e
+	}
 	defer r.Body.Close()
+	var rbody io.Reader = countReader{r.Body, &info.ResponseBytes}
+	if c.maxResponse > 0 {
+		rbody = &limitReader{r: rbody, n: c.maxResponse, max: c.maxResponse}
+	}
+	if c.resDump != nil {
+		rbody = io.TeeReader(rbody, c.resDump)
+	}
+	if o.raw != nil {
+		raw := &bytes.Buffer{}
+		rbody = io.TeeReader(rbody, raw)
+		defer func() {
+			o.raw.write(raw.Bytes())
+		}()
+	}
+
+	if c.decodeTimeout > 0 {
+		dr := &deadlineReader{r: rbody, body: r.Body}
+		defer time.AfterFunc(c.decodeTimeout, dr.expire).Stop()
+		rbody = dr
+	}
 
+	// Since we do not always read the entire body, discard the rest of
+	// well-formed responses, which allows the http transport to reuse the
+	// connection. The body of a response that failed to decode, e.g. one
+	// exceeding the decode limits, is just closed.
+	defer func() {
+		var f *Fault
+		if e == nil || errors.As(e, &f) {
+			io.Copy(ioutil.Discard, io.LimitReader(rbody, maxDrain))
+		}
+	}()
+
+	if o.onResponse != nil {
+		o.onResponse(r)
+	}
 	if r.StatusCode/100 != 2 {
-		return nil, errors.New(http.StatusText(http.StatusBadRequest))
+		return nil, &StatusError{StatusCode: r.StatusCode}
 	}
 
-	p := xml.NewDecoder(r.Body)
-	se, e := nextStart(p) // methodResponse
-	if se.Name.Local != "methodResponse" {
-		return nil, errors.New("invalid response: missing methodResponse")
+	start := time.Now()
+	d := c.acquireDecoder(rbody)
+	v, e = decode(d)
+	var f *Fault
+	if e == nil || errors.As(e, &f) {
+		if err := d.checkTrailing(); err != nil {
+			v, e = nil, err
+		}
 	}
-	se, e = nextStart(p) // params
-	if se.Name.Local != "params" {
-		return nil, errors.New("invalid response: missing params")
+	c.releaseDecoder(d)
+	info.Decode = time.Since(start)
+	return v, e
+}
+
+// maxDrain is the number of bytes of a response which are discarded after
+// decoding it so that its connection can be reused.
+const maxDrain = 64 << 10
+
+// limitReader reads at most n bytes of r, like http.MaxBytesReader, and
+// fails with ErrDecodeLimit after them.
+type limitReader struct {
+	r   io.Reader
+	n   int64 // bytes left
+	max int64
+	err error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
 	}
-	se, e = nextStart(p) // param
-	if se.Name.Local != "param" {
-		return nil, errors.New("invalid response: missing param")
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
 	}
-	se, e = nextStart(p) // value
-	if se.Name.Local != "value" {
-		return nil, errors.New("invalid response: missing value")
+	n, err := l.r.Read(p)
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+		l.err = err
+		return n, err
 	}
-	_, v, e = next(p)
-	return v, e
+	n = int(l.n)
+	l.n = 0
+	l.err = fmt.Errorf("%w: response larger than %d bytes", ErrDecodeLimit, l.max)
+	return n, l.err
 }
 
-// Call call remote procedures function name with args
+// acquireDecoder returns a decoder of r with the options of the client,
+// reusing the buffers of one of an earlier call if there is one.
+func (c *Client) acquireDecoder(r io.Reader) *decoder {
+	if d, ok := c.decoders.Get().(*decoder); ok {
+		return d.reset(r, c.dec)
+	}
+	return newDecoder(r, c.dec)
+}
+
+// releaseDecoder makes d, whose call has finished, available for reuse.
+func (c *Client) releaseDecoder(d *decoder) {
+	d.br.Reset(nil)
+	d.r = nil
+	d.violations = nil
+	c.decoders.Put(d)
+}
+
+// Call call remote procedures function name with args. CallOptions at the
+// end of args change the behavior of the call instead of being sent.
 func (c *Client) Call(name string, args ...interface{}) (v interface{}, e error) {
-	return call(c.HttpClient, c.url, name, args...)
+	return c.CallContext(context.Background(), name, args...)
+}
+
+// CallContext is like Call but aborts the call when ctx is done.
+func (c *Client) CallContext(ctx context.Context, name string, args ...interface{}) (v interface{}, e error) {
+	args, o := splitCallOptions(args)
+	if o != nil {
+		return c.callWith(ctx, name, args, o)
+	}
+	return c.callCached(ctx, name, args)
+}
+
+// CallStruct calls the method name with params as its only argument, for
+// APIs taking named parameters. Go structs can be passed to Call in the
+// same way; their exported fields are sent as members named by their
+// xmlrpc tag, like for Unmarshal, and left out if the tag has the omitempty
+// option and the field its zero value.
+func (c *Client) CallStruct(name string, params map[string]interface{}) (interface{}, error) {
+	return c.Call(name, Struct(params))
+}
+
+// CallMulti is like Call but returns all params of the response. Use it with
+// non-conforming servers which return more than one param.
+func (c *Client) CallMulti(name string, args ...interface{}) (Array, error) {
+	v, err := c.call(context.Background(), name, args, func(d *decoder) (interface{}, error) {
+		return d.params(-1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(Array), nil
+}
+
+// Do makes the call and returns the response, which holds the fault the
+// call failed with instead of the error, and the headers of the HTTP
+// response. Unlike Call, it never uses the cache or coalescing of the
+// client. opts change the call as for Call; CallRequestHook and
+// CallResponseHook give access to the HTTP messages of each attempt.
+func (c *Client) Do(ctx context.Context, call *MethodCall, opts ...CallOption) (*MethodResponse, error) {
+	o := &callOptions{}
+	for _, opt := range opts {
+		opt.applyCall(o)
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	res := &MethodResponse{}
+	var mu sync.Mutex
+	onResponse := o.onResponse
+	o.onResponse = func(r *http.Response) {
+		mu.Lock()
+		res.Header = r.Header
+		mu.Unlock()
+		if onResponse != nil {
+			onResponse(r)
+		}
+	}
+	v, err := c.call(context.WithValue(ctx, callOptionsKey{}, o), call.Name, call.Params, (*decoder).response)
+	var f *Fault
+	if errors.As(err, &f) {
+		res.Fault = f
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Value = v
+	if o.result != nil {
+		if err := Unmarshal(v, o.result); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
 }
 
 // Global httpClient allows us to pool/reuse connections and not wastefully
@@ -365,5 +686,5 @@ var httpClient = &http.Client{Transport: http.DefaultTransport, Timeout: 10 * ti
 
 // Call call remote procedures function name with args
 func Call(url, name string, args ...interface{}) (v interface{}, e error) {
-	return call(httpClient, url, name, args...)
+	return (&Client{HttpClient: httpClient, url: url}).Call(name, args...)
 }
@@ -1,84 +1,27 @@
 package xmlrpc
 
 import (
-	"encoding/xml"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
-func createServer(path, name string, f func(args ...interface{}) (interface{}, error)) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != path {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-			return
-		}
-		p := xml.NewDecoder(r.Body)
-		se, _ := nextStart(p) // methodResponse
-		if se.Name.Local != "methodCall" {
-			http.Error(w, "missing methodCall", http.StatusBadRequest)
-			return
-		}
-		se, _ = nextStart(p) // params
-		if se.Name.Local != "methodName" {
-			http.Error(w, "missing methodName", http.StatusBadRequest)
-			return
-		}
-		var s string
-		if err := p.DecodeElement(&s, &se); err != nil {
-			http.Error(w, "wrong function name", http.StatusBadRequest)
-			return
-		}
-		if s != name {
-			http.Error(w, fmt.Sprintf("want function name %q but got %q", name, s), http.StatusBadRequest)
-			return
-		}
-		se, _ = nextStart(p) // params
-		if se.Name.Local != "params" {
-			http.Error(w, "missing params", http.StatusBadRequest)
-			return
-		}
-		var args []interface{}
-		for {
-			se, _ = nextStart(p) // param
-			if se.Name.Local == "" {
-				break
-			}
-			if se.Name.Local != "param" {
-				http.Error(w, "missing param", http.StatusBadRequest)
-				return
-			}
-			se, _ = nextStart(p) // value
-			if se.Name.Local != "value" {
-				http.Error(w, "missing value", http.StatusBadRequest)
-				return
-			}
-			_, v, err := next(p)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
-				return
-			}
-			args = append(args, v)
-		}
-
-		ret, err := f(args...)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		w.Write([]byte(`
-		<?xml version="1.0"?>
-		<methodResponse>
-		<params>
-			<param>
-				<value>` + toXml(ret, true) + `</value>
-			</param>
-		</params>
-		</methodResponse>
-		`))
-	}
+func createServer(path, name string, f HandlerFunc) http.Handler {
+	s := NewServer()
+	s.Register(name, f)
+	mux := http.NewServeMux()
+	mux.Handle(path, s)
+	return mux
 }
 
 func TestAddInt(t *testing.T) {
@@ -349,3 +292,344 @@ func TestParseMixedArray(t *testing.T) {
 		t.Fatal("expected array with 4 entries")
 	}
 }
+
+func TestCallMulti(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+<?xml version="1.0"?>
+<methodResponse>
+  <params>
+    <param><value><int>1</int></value></param>
+    <param><value><string>two</string></value></param>
+  </params>
+</methodResponse>
+		`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL + "/")
+	v, err := client.Call("Irrelevant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 {
+		t.Fatalf("want 1 but got %v", v)
+	}
+	res, err := client.CallMulti("Irrelevant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0] != 1 || res[1] != "two" {
+		t.Fatalf("want [1 two] but got %v", res)
+	}
+}
+
+func TestCallStruct(t *testing.T) {
+	ts := httptest.NewServer(createServer("/api", "search", func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("bad number of arguments")
+		}
+		return args[0], nil
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL + "/api")
+	v, err := client.CallStruct("search", map[string]interface{}{"product": "Go", "limit": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, Struct{"product": "Go", "limit": 10}) {
+		t.Fatalf("unexpected result: %v", v)
+	}
+
+	type query struct {
+		Product string `xmlrpc:"product"`
+		Limit   int    `xmlrpc:"limit,omitempty"`
+		Offset  *int   `xmlrpc:"offset,omitempty"`
+		Secret  string `xmlrpc:"-"`
+		ID      int
+		private int
+	}
+	offset := 5
+	v, err = client.Call("search", query{Product: "Go", Offset: &offset, Secret: "x", private: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, Struct{"product": "Go", "offset": 5, "ID": 0}) {
+		t.Fatalf("unexpected result: %v", v)
+	}
+}
+
+func TestCallContext(t *testing.T) {
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewClient(ts.URL).CallContext(ctx, "Irrelevant"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled but got %v", err)
+	}
+}
+
+func TestBase64Reader(t *testing.T) {
+	data := strings.Repeat("0123456789", 10000)
+	ts := httptest.NewServer(createServer("/api", "Upload", func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New("bad number of arguments")
+		}
+		b, ok := args[1].(Struct)["bits"].([]byte)
+		if !ok {
+			return nil, errors.New("bits should be base64")
+		}
+		if string(b) != data {
+			return nil, errors.New("bits mismatch")
+		}
+		return len(b), nil
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL + "/api")
+	v, err := client.Call("Upload", "blog", Struct{"name": "a.txt", "bits": Base64Reader{strings.NewReader(data)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != len(data) {
+		t.Fatalf("want %v but got %v", len(data), v)
+	}
+}
+
+func TestDump(t *testing.T) {
+	ts := httptest.NewServer(&ParseIntArrayHandler{})
+	defer ts.Close()
+
+	var req, res bytes.Buffer
+	client := NewClient(ts.URL+"/", WithRequestDump(&req), WithResponseDump(&res))
+	if _, err := client.Call("Irrelevant", 1); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0"?><methodCall><methodName>Irrelevant</methodName><params><param><value><int>1</int></value></param></params></methodCall>`
+	if req.String() != want {
+		t.Fatalf("want request %q but got %q", want, req.String())
+	}
+	rec := httptest.NewRecorder()
+	(&ParseIntArrayHandler{}).ServeHTTP(rec, nil)
+	if res.String() != rec.Body.String() {
+		t.Fatalf("want response %q but got %q", rec.Body.String(), res.String())
+	}
+}
+
+func TestSigner(t *testing.T) {
+	key := []byte("secret")
+	sign := func(body []byte) string {
+		m := hmac.New(sha256.New, key)
+		m.Write(body)
+		return hex.EncodeToString(m.Sum(nil))
+	}
+	s := NewServer()
+	s.Register("echo", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Signature") != sign(body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithSigner(func(body []byte, h http.Header) error {
+		h.Set("X-Signature", sign(body))
+		return nil
+	}))
+	if v, err := c.Call("echo", Struct{"a": 1, "b": 2}); err != nil || !reflect.DeepEqual(v, Struct{"a": 1, "b": 2}) {
+		t.Fatalf("want signed call echoed but got %v, %v", v, err)
+	}
+	if _, err := NewClient(ts.URL).Call("echo", 1); err == nil {
+		t.Fatal("want unsigned call rejected")
+	}
+	errSign := errors.New("no key")
+	c = NewClient(ts.URL, WithSigner(func(body []byte, h http.Header) error {
+		return errSign
+	}))
+	if _, err := c.Call("echo", 1); !errors.Is(err, errSign) {
+		t.Fatalf("want signer error but got %v", err)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("whoami", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		return p.Header.Get("Authorization"), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	n := 0
+	c := NewClient(ts.URL, WithBearerToken(func(ctx context.Context) (string, error) {
+		n++
+		if n > 2 {
+			return "", errors.New("refresh failed")
+		}
+		return "token" + strconv.Itoa(n), nil
+	}))
+	for _, want := range []string{"Bearer token1", "Bearer token2"} {
+		v, err := c.Call("whoami")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != want {
+			t.Fatalf("want %q but got %v", want, v)
+		}
+	}
+	if _, err := c.Call("whoami"); err == nil || err.Error() != "refresh failed" {
+		t.Fatalf("want refresh error but got %v", err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	s := NewServer()
+	s.RegisterContext("whoami", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		p, _ := PeerFromContext(ctx)
+		return p.Header.Get("X-User"), nil
+	})
+	s.Register("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, &Fault{Code: 4, String: "failed"}
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Server", "test")
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	var status int
+	var user string
+	res, err := c.Do(context.Background(), &MethodCall{Name: "whoami"},
+		CallRequestHook(func(r *http.Request) { r.Header.Set("X-User", "alice") }),
+		CallResponseHook(func(r *http.Response) { status = r.StatusCode }),
+		CallResult(&user))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "alice" || user != "alice" || res.Fault != nil || res.Header.Get("X-Server") != "test" || status != 200 {
+		t.Fatalf("unexpected response %+v with status %d", res, status)
+	}
+
+	res, err = c.Do(context.Background(), &MethodCall{Name: "fail"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Fault == nil || res.Fault.Code != 4 || res.Value != nil {
+		t.Fatalf("want fault but got %+v", res)
+	}
+}
+
+func TestTrailingContent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<methodResponse><params><param><value>ok</value></param></params></methodResponse>Warning: deprecated`))
+	}))
+	defer ts.Close()
+
+	if _, err := NewClient(ts.URL).Call("f"); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	if _, err := NewClient(ts.URL, WithTrailingContent(TrailingHook, func(b []byte) { got = string(b) })).Call("f"); err != nil || got != "Warning: deprecated" {
+		t.Fatalf("want trailing content reported but got %q, %v", got, err)
+	}
+	var de *DecodeError
+	if _, err := NewClient(ts.URL, WithTrailingContent(TrailingError, nil)).Call("f"); !errors.As(err, &de) {
+		t.Fatalf("want DecodeError but got %v", err)
+	}
+}
+
+func BenchmarkCall(b *testing.B) {
+	s := NewServer()
+	s.Register("add", func(args ...interface{}) (interface{}, error) {
+		return args[0].(int) + args[1].(int), nil
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Call("add", i, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecoderReuse(t *testing.T) {
+	responses := []string{
+		`<?xml version="1.0" encoding="ISO-8859-1"?><methodResponse><params><param><value>caf` + "\xe9" + `</value></param></params></methodResponse>`,
+		`<methodResponse><params><param><value><int>1</int></value></param><param><value>x</value></param></params></methodResponse>`,
+		`<methodResponse><params><param><value>ok</value></param></params></methodResponse>`,
+	}
+	n := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[n%len(responses)]))
+		n++
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithStrict())
+	for i := 0; i < 2; i++ {
+		if v, err := c.Call("f"); err != nil || v != "café" {
+			t.Fatalf("want café but got %v, %v", v, err)
+		}
+		var se *SpecError
+		if _, err := c.Call("f"); !errors.As(err, &se) || len(se.Violations) != 1 {
+			t.Fatalf("want one violation but got %v", err)
+		}
+		if v, err := c.Call("f"); err != nil || v != "ok" {
+			t.Fatalf("want ok but got %v, %v", v, err)
+		}
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	big := strings.Repeat("x", 4096)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := "ok"
+		if r.URL.Path == "/big" {
+			s = big
+		}
+		w.Write([]byte(`<methodResponse><params><param><value>` + s + `</value></param></params></methodResponse>`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithMaxResponseSize(1024))
+	if v, err := c.Call("f"); err != nil || v != "ok" {
+		t.Fatalf("want ok but got %v, %v", v, err)
+	}
+	if _, err := c.With(WithPath("/big")).Call("f"); !errors.Is(err, ErrDecodeLimit) {
+		t.Fatalf("want limit exceeded but got %v", err)
+	}
+}
+
+func TestDecodeLimitsEndlessResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<methodResponse><params><param><value><array><data>`))
+		for {
+			if _, err := w.Write([]byte(`<value>1</value>`)); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithDecodeLimits(DecodeLimits{MaxElements: 100}))
+	if _, err := c.Call("f"); !errors.Is(err, ErrDecodeLimit) {
+		t.Fatalf("want limit exceeded but got %v", err)
+	}
+}
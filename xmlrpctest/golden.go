@@ -0,0 +1,166 @@
+package xmlrpctest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// node is an element of a payload.
+type node struct {
+	start    xml.StartElement
+	children []*node
+	text     string
+}
+
+// Canonicalize returns payload, a methodCall or methodResponse, in a form
+// suitable for comparisons: indented by two spaces, without formatting
+// whitespace, and with struct members sorted by name.
+func Canonicalize(payload []byte) ([]byte, error) {
+	p := xml.NewDecoder(bytes.NewReader(payload))
+	root := &node{}
+	stack := []*node{root}
+	for {
+		t, err := p.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		cur := stack[len(stack)-1]
+		switch t := t.(type) {
+		case xml.StartElement:
+			n := &node{start: t.Copy()}
+			cur.children = append(cur.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			cur.text += string(t)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0"?>`)
+	for _, n := range root.children {
+		n.write(&buf)
+	}
+	var out bytes.Buffer
+	if err := xmlrpc.Indent(&out, &buf, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (n *node) write(buf *bytes.Buffer) {
+	name := n.start.Name.Local
+	if len(n.children) == 0 {
+		buf.WriteString("<" + name + ">")
+		xml.EscapeText(buf, []byte(n.text))
+		buf.WriteString("</" + name + ">")
+		return
+	}
+	if name == "struct" {
+		sort.SliceStable(n.children, func(i, j int) bool {
+			return n.children[i].memberName() < n.children[j].memberName()
+		})
+	}
+	buf.WriteString("<" + name + ">")
+	for _, c := range n.children {
+		c.write(buf)
+	}
+	buf.WriteString("</" + name + ">")
+}
+
+func (n *node) memberName() string {
+	for _, c := range n.children {
+		if c.start.Name.Local == "name" {
+			return c.text
+		}
+	}
+	return ""
+}
+
+// AssertGolden fails the test unless payload is equal to the payload in the
+// golden file at path after both are canonicalized. If the environment
+// variable XMLRPCTEST_UPDATE is set, the golden file is written instead.
+func AssertGolden(t testing.TB, path string, payload []byte) {
+	t.Helper()
+	got, err := Canonicalize(payload)
+	if err != nil {
+		t.Fatalf("xmlrpctest: %v", err)
+	}
+	if os.Getenv("XMLRPCTEST_UPDATE") != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("xmlrpctest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("xmlrpctest: %v", err)
+		}
+		return
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("xmlrpctest: %v (set XMLRPCTEST_UPDATE=1 to create it)", err)
+	}
+	want, err := Canonicalize(b)
+	if err != nil {
+		t.Fatalf("xmlrpctest: %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("xmlrpctest: payload differs from %s (-want +got):\n%s", path, Diff(string(want), string(got)))
+	}
+}
+
+// Diff returns a line by line diff of a and b, with lines only in a
+// prefixed by "-" and lines only in b prefixed by "+".
+func Diff(a, b string) string {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			fmt.Fprintf(&buf, " %s\n", x[i])
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&buf, "-%s\n", x[i])
+			i++
+		default:
+			fmt.Fprintf(&buf, "+%s\n", y[j])
+			j++
+		}
+	}
+	return buf.String()
+}
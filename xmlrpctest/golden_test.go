@@ -0,0 +1,59 @@
+package xmlrpctest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestAssertGolden(t *testing.T) {
+	s := NewServer(map[string]xmlrpc.HandlerFunc{
+		"metaWeblog.newPost": Value("1"),
+	})
+	defer s.Close()
+
+	var buf bytes.Buffer
+	_, err := s.XMLRPCClient(xmlrpc.WithRequestDump(&buf)).Call("metaWeblog.newPost", "1", xmlrpc.Struct{
+		"title":       "Hello",
+		"description": "Hello <b>world</b>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, "testdata/newPost.golden", buf.Bytes())
+}
+
+func TestCanonicalize(t *testing.T) {
+	a, err := Canonicalize([]byte(`<methodResponse><params><param><value><struct><member><name>b</name><value><int>2</int></value></member><member><name>a</name><value> x </value></member></struct></value></param></params></methodResponse>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Canonicalize([]byte(`<?xml version="1.0"?>
+<methodResponse>
+  <params>
+    <param>
+      <value>
+        <struct>
+          <member><name>a</name><value> x </value></member>
+          <member><name>b</name><value><int>2</int></value></member>
+        </struct>
+      </value>
+    </param>
+  </params>
+</methodResponse>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Fatalf("want equal canonical forms but got:\n%s", Diff(string(a), string(b)))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("a\nb\nc\n", "a\nc\nd\n")
+	want := " a\n-b\n c\n+d\n"
+	if got != want {
+		t.Fatalf("want %q but got %q", want, got)
+	}
+}
@@ -0,0 +1,182 @@
+// Package xmlrpctest provides XML-RPC servers for testing clients: they
+// dispatch calls to handler functions, record the calls for assertions and
+// can be told to send malformed responses.
+package xmlrpctest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+// Call is a call received by a Server.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Server is an XML-RPC server listening on a system-chosen port on the local
+// loopback interface.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	calls    []Call
+	injected map[string]Malformation
+}
+
+// NewServer starts and returns a new Server serving handlers, keyed by
+// method name. The caller should call Close when finished.
+func NewServer(handlers map[string]xmlrpc.HandlerFunc) *Server {
+	s := &Server{injected: map[string]Malformation{}}
+	rs := xmlrpc.NewServer()
+	for name, h := range handlers {
+		rs.Register(name, s.record(name, h))
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(r.Body, &body), r.Body}
+		rec := httptest.NewRecorder()
+		rs.ServeHTTP(rec, r)
+
+		s.mu.Lock()
+		m, ok := s.injected[methodName(body.Bytes())]
+		if !ok {
+			m, ok = s.injected[""]
+		}
+		s.mu.Unlock()
+		if !ok {
+			m = passThrough
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		m(w, rec.Code, rec.Body.Bytes())
+	}))
+	return s
+}
+
+// XMLRPCClient returns an xmlrpc.Client calling the server.
+func (s *Server) XMLRPCClient(opts ...xmlrpc.Option) *xmlrpc.Client {
+	return xmlrpc.NewClient(s.URL, opts...)
+}
+
+func (s *Server) record(name string, h xmlrpc.HandlerFunc) xmlrpc.HandlerFunc {
+	return func(args ...interface{}) (interface{}, error) {
+		s.mu.Lock()
+		s.calls = append(s.calls, Call{Method: name, Args: args})
+		s.mu.Unlock()
+		return h(args...)
+	}
+}
+
+// Calls returns the calls of registered methods received so far.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// AssertCalled fails the test unless the server received a call of method
+// with args.
+func (s *Server) AssertCalled(t testing.TB, method string, args ...interface{}) {
+	t.Helper()
+	for _, c := range s.Calls() {
+		if c.Method == method && ((len(c.Args) == 0 && len(args) == 0) || reflect.DeepEqual(c.Args, args)) {
+			return
+		}
+	}
+	t.Errorf("xmlrpctest: want call %s%v but got %v", method, args, s.Calls())
+}
+
+// AssertNotCalled fails the test if the server received a call of method.
+func (s *Server) AssertNotCalled(t testing.TB, method string) {
+	t.Helper()
+	for _, c := range s.Calls() {
+		if c.Method == method {
+			t.Errorf("xmlrpctest: want no call of %s but got %v", method, c)
+			return
+		}
+	}
+}
+
+// Inject makes the server pass its responses to calls of method through m.
+// An empty method applies m to all calls without an injection of their own.
+func (s *Server) Inject(method string, m Malformation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected[method] = m
+}
+
+// Fault returns a handler which always responds with the given fault.
+func Fault(code int, msg string) xmlrpc.HandlerFunc {
+	return func(args ...interface{}) (interface{}, error) {
+		return nil, &xmlrpc.Fault{Code: code, String: msg}
+	}
+}
+
+// Value returns a handler which always responds with v.
+func Value(v interface{}) xmlrpc.HandlerFunc {
+	return func(args ...interface{}) (interface{}, error) {
+		return v, nil
+	}
+}
+
+// Malformation writes a possibly damaged version of the response the server
+// would have sent, with the given status code and body, to w.
+type Malformation func(w http.ResponseWriter, code int, body []byte)
+
+func passThrough(w http.ResponseWriter, code int, body []byte) {
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// Truncate cuts responses off after n bytes.
+func Truncate(n int) Malformation {
+	return func(w http.ResponseWriter, code int, body []byte) {
+		if n < len(body) {
+			body = body[:n]
+		}
+		passThrough(w, code, body)
+	}
+}
+
+// Prepend puts junk, such as a PHP warning, in front of responses.
+func Prepend(junk string) Malformation {
+	return func(w http.ResponseWriter, code int, body []byte) {
+		passThrough(w, code, append([]byte(junk), body...))
+	}
+}
+
+// Replace sends body instead of the response.
+func Replace(body string) Malformation {
+	return func(w http.ResponseWriter, code int, _ []byte) {
+		passThrough(w, code, []byte(body))
+	}
+}
+
+// Status sends responses with the given HTTP status code.
+func Status(code int) Malformation {
+	return func(w http.ResponseWriter, _ int, body []byte) {
+		passThrough(w, code, body)
+	}
+}
+
+var methodNameRe = regexp.MustCompile(`<methodName>\s*([^<]*?)\s*</methodName>`)
+
+func methodName(body []byte) string {
+	if m := methodNameRe.FindSubmatch(body); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package xmlrpctest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mattn/go-xmlrpc"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer(map[string]xmlrpc.HandlerFunc{
+		"add": func(args ...interface{}) (interface{}, error) {
+			return args[0].(int) + args[1].(int), nil
+		},
+		"fail": Fault(4, "Too many parameters."),
+	})
+	defer s.Close()
+
+	client := s.XMLRPCClient()
+	v, err := client.Call("add", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 3 {
+		t.Fatalf("want 3 but got %v", v)
+	}
+	_, err = client.Call("fail")
+	var f *xmlrpc.Fault
+	if !errors.As(err, &f) || f.Code != 4 {
+		t.Fatalf("want fault 4 but got %v", err)
+	}
+	s.AssertCalled(t, "add", 1, 2)
+	s.AssertCalled(t, "fail")
+	s.AssertNotCalled(t, "other")
+	if len(s.Calls()) != 2 {
+		t.Fatalf("want 2 calls but got %v", s.Calls())
+	}
+}
+
+func TestInject(t *testing.T) {
+	s := NewServer(map[string]xmlrpc.HandlerFunc{
+		"a": Value("x"),
+		"b": Value("y"),
+	})
+	defer s.Close()
+	client := s.XMLRPCClient()
+
+	s.Inject("a", Truncate(40))
+	if _, err := client.Call("a"); err == nil {
+		t.Fatal("want error for truncated response")
+	}
+	if v, err := client.Call("b"); err != nil || v != "y" {
+		t.Fatalf("want y but got %v, %v", v, err)
+	}
+
+	s.Inject("", Prepend("<br />\n<b>Warning</b>: something\n"))
+	if _, err := client.Call("b"); err == nil {
+		t.Fatal("want error for leading junk")
+	}
+	if v, err := s.XMLRPCClient(xmlrpc.WithSkipLeadingJunk(100)).Call("b"); err != nil || v != "y" {
+		t.Fatalf("want y but got %v, %v", v, err)
+	}
+
+	s.Inject("b", Status(http.StatusInternalServerError))
+	if _, err := client.Call("b"); err == nil {
+		t.Fatal("want error for status 500")
+	}
+
+	s.Inject("b", Replace("<html>oops</html>"))
+	if _, err := client.Call("b"); err == nil {
+		t.Fatal("want error for HTML response")
+	}
+}